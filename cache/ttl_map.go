@@ -0,0 +1,298 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// EvictReason is the reason why the entry is removed from TTLMap.
+type EvictReason int
+
+// Predefine some evict reasons.
+const (
+	// EvictExpired means that the entry has expired.
+	EvictExpired EvictReason = iota
+
+	// EvictReplaced means that the entry has been replaced by a new value.
+	EvictReplaced
+
+	// EvictDeleted means that the entry has been deleted or cleared.
+	EvictDeleted
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictExpired:
+		return "expired"
+	case EvictReplaced:
+		return "replaced"
+	case EvictDeleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
+
+type ttlEntry struct {
+	value   interface{}
+	ttl     time.Duration
+	expires time.Time
+}
+
+func (e *ttlEntry) expired(now time.Time) bool {
+	return e.ttl > 0 && !now.Before(e.expires)
+}
+
+type evicted struct {
+	key    string
+	value  interface{}
+	reason EvictReason
+}
+
+// TTLMap is a thread-safe map that each entry expires after its TTL.
+//
+// The expired entries are removed by the background goroutine periodically,
+// and they are invisible even if not removed yet.
+type TTLMap struct {
+	// OnEvict is called when the entry is removed with the reason,
+	// which is called without holding the lock.
+	OnEvict func(key string, value interface{}, reason EvictReason)
+
+	// RefreshOnGet reports whether to reset the expiry of the entry
+	// to its TTL from now when calling Get.
+	RefreshOnGet bool
+
+	lock    sync.Mutex
+	entries map[string]*ttlEntry
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewTTLMap returns a new TTLMap, which removes the expired entries
+// every interval in a new goroutine. If interval is 0, it is one minute
+// by default.
+//
+// Call Close to stop the background goroutine when not used any more.
+func NewTTLMap(interval time.Duration) *TTLMap {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	m := &TTLMap{entries: make(map[string]*ttlEntry), stop: make(chan struct{})}
+	go m.cleanup(interval)
+	return m
+}
+
+func (m *TTLMap) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.Cleanup()
+		}
+	}
+}
+
+// Close stops the background goroutine, but keeps the entries.
+func (m *TTLMap) Close() {
+	m.once.Do(func() { close(m.stop) })
+}
+
+func (m *TTLMap) evict(es ...evicted) {
+	if m.OnEvict != nil {
+		for _, e := range es {
+			m.OnEvict(e.key, e.value, e.reason)
+		}
+	}
+}
+
+// Set sets the value of the key, which expires after ttl.
+// If ttl is equal to or less than 0, it never expires.
+//
+// If the key has existed, the old value is evicted as EvictReplaced,
+// or EvictExpired if it has expired.
+func (m *TTLMap) Set(key string, value interface{}, ttl time.Duration) {
+	now := time.Now()
+	m.lock.Lock()
+	old, ok := m.entries[key]
+	m.entries[key] = &ttlEntry{value: value, ttl: ttl, expires: now.Add(ttl)}
+	m.lock.Unlock()
+
+	if ok {
+		reason := EvictReplaced
+		if old.expired(now) {
+			reason = EvictExpired
+		}
+		m.evict(evicted{key, old.value, reason})
+	}
+}
+
+// SetIfAbsent is the same as Set, but does nothing and returns false
+// if the key has existed and not expired.
+func (m *TTLMap) SetIfAbsent(key string, value interface{}, ttl time.Duration) bool {
+	now := time.Now()
+	m.lock.Lock()
+	old, ok := m.entries[key]
+	if ok && !old.expired(now) {
+		m.lock.Unlock()
+		return false
+	}
+	m.entries[key] = &ttlEntry{value: value, ttl: ttl, expires: now.Add(ttl)}
+	m.lock.Unlock()
+
+	if ok {
+		m.evict(evicted{key, old.value, EvictExpired})
+	}
+	return true
+}
+
+func (m *TTLMap) get(key string, refresh bool) (value interface{}, ok bool) {
+	now := time.Now()
+	m.lock.Lock()
+	e, ok := m.entries[key]
+	if !ok {
+		m.lock.Unlock()
+		return nil, false
+	} else if e.expired(now) {
+		delete(m.entries, key)
+		m.lock.Unlock()
+		m.evict(evicted{key, e.value, EvictExpired})
+		return nil, false
+	}
+
+	if refresh && e.ttl > 0 {
+		e.expires = now.Add(e.ttl)
+	}
+	m.lock.Unlock()
+	return e.value, true
+}
+
+// Get returns the value of the key, which resets the expiry if RefreshOnGet
+// is true. If the key does not exist or has expired, return (nil, false).
+func (m *TTLMap) Get(key string) (value interface{}, ok bool) {
+	return m.get(key, m.RefreshOnGet)
+}
+
+// Peek is the same as Get, but never resets the expiry.
+func (m *TTLMap) Peek(key string) (value interface{}, ok bool) {
+	return m.get(key, false)
+}
+
+// TTL returns the remaining duration before the key expires.
+//
+// It returns (0, true) if the key never expires, and (0, false)
+// if it does not exist or has expired.
+func (m *TTLMap) TTL(key string) (time.Duration, bool) {
+	now := time.Now()
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok || e.expired(now) {
+		return 0, false
+	} else if e.ttl <= 0 {
+		return 0, true
+	}
+	return e.expires.Sub(now), true
+}
+
+// Touch resets the expiry of the key to its TTL from now,
+// and reports whether the key exists and has not expired.
+func (m *TTLMap) Touch(key string) bool {
+	_, ok := m.get(key, true)
+	return ok
+}
+
+// Delete deletes the key, and reports whether it exists and not expired.
+func (m *TTLMap) Delete(key string) bool {
+	now := time.Now()
+	m.lock.Lock()
+	e, ok := m.entries[key]
+	delete(m.entries, key)
+	m.lock.Unlock()
+
+	if !ok {
+		return false
+	} else if e.expired(now) {
+		m.evict(evicted{key, e.value, EvictExpired})
+		return false
+	}
+
+	m.evict(evicted{key, e.value, EvictDeleted})
+	return true
+}
+
+// Len returns the number of the entries, including the expired ones
+// that have not been removed.
+func (m *TTLMap) Len() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return len(m.entries)
+}
+
+// Keys returns the keys of all the entries that have not expired.
+func (m *TTLMap) Keys() []string {
+	now := time.Now()
+	m.lock.Lock()
+	keys := make([]string, 0, len(m.entries))
+	for key, e := range m.entries {
+		if !e.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	m.lock.Unlock()
+	return keys
+}
+
+// Clear removes all the entries, which are evicted as EvictDeleted.
+func (m *TTLMap) Clear() {
+	m.lock.Lock()
+	entries := m.entries
+	m.entries = make(map[string]*ttlEntry)
+	m.lock.Unlock()
+
+	if m.OnEvict != nil {
+		now := time.Now()
+		for key, e := range entries {
+			reason := EvictDeleted
+			if e.expired(now) {
+				reason = EvictExpired
+			}
+			m.OnEvict(key, e.value, reason)
+		}
+	}
+}
+
+// Cleanup removes the expired entries at once, and returns the number of them.
+func (m *TTLMap) Cleanup() int {
+	now := time.Now()
+	var es []evicted
+	m.lock.Lock()
+	for key, e := range m.entries {
+		if e.expired(now) {
+			delete(m.entries, key)
+			es = append(es, evicted{key, e.value, EvictExpired})
+		}
+	}
+	m.lock.Unlock()
+
+	m.evict(es...)
+	return len(es)
+}
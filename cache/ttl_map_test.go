@@ -0,0 +1,130 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+type evictRecorder struct {
+	lock    sync.Mutex
+	reasons map[string]EvictReason
+}
+
+func (r *evictRecorder) OnEvict(key string, value interface{}, reason EvictReason) {
+	r.lock.Lock()
+	r.reasons[key] = reason
+	r.lock.Unlock()
+}
+
+func (r *evictRecorder) Reason(key string) (EvictReason, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	reason, ok := r.reasons[key]
+	return reason, ok
+}
+
+func TestTTLMap(t *testing.T) {
+	r := &evictRecorder{reasons: make(map[string]EvictReason)}
+	m := NewTTLMap(time.Millisecond * 10)
+	m.OnEvict = r.OnEvict
+	defer m.Close()
+
+	m.Set("expired", 1, time.Millisecond*20)
+	m.Set("replaced", 2, 0)
+	m.Set("replaced", 3, 0)
+	m.Set("deleted", 4, time.Hour)
+	m.Set("forever", 5, 0)
+	if !m.Delete("deleted") || m.Delete("deleted") {
+		t.Error("failed to delete the key")
+	}
+	if m.SetIfAbsent("forever", 6, 0) || !m.SetIfAbsent("absent", 7, time.Hour) {
+		t.Error("unexpected result of SetIfAbsent")
+	}
+
+	if v, ok := m.Get("replaced"); !ok || v != 3 {
+		t.Errorf("unexpected value: %v", v)
+	}
+	if ttl, ok := m.TTL("absent"); !ok || ttl <= time.Minute*59 {
+		t.Errorf("unexpected ttl: %s", ttl)
+	}
+	if ttl, ok := m.TTL("forever"); !ok || ttl != 0 {
+		t.Errorf("unexpected ttl: %s", ttl)
+	}
+
+	time.Sleep(time.Millisecond * 50)
+	if _, ok := m.Get("expired"); ok {
+		t.Error("the key should have expired")
+	}
+	if n := m.Len(); n != 3 {
+		t.Errorf("expect 3 entries, but got %d", n)
+	}
+
+	keys := m.Keys()
+	sort.Strings(keys)
+	if len(keys) != 3 || keys[0] != "absent" || keys[1] != "forever" || keys[2] != "replaced" {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+
+	for key, reason := range map[string]EvictReason{
+		"expired":  EvictExpired,
+		"replaced": EvictReplaced,
+		"deleted":  EvictDeleted,
+	} {
+		if r, ok := r.Reason(key); !ok || r != reason {
+			t.Errorf("%s: expect the evict reason '%s', but got '%s'", key, reason, r)
+		}
+	}
+
+	m.Clear()
+	if n := m.Len(); n != 0 {
+		t.Errorf("expect no entries, but got %d", n)
+	}
+	if reason, _ := r.Reason("forever"); reason != EvictDeleted {
+		t.Errorf("unexpected evict reason '%s'", reason)
+	}
+}
+
+func TestTTLMapRefresh(t *testing.T) {
+	m := NewTTLMap(time.Hour)
+	m.RefreshOnGet = true
+	defer m.Close()
+
+	m.Set("key", "value", time.Millisecond*40)
+	for i := 0; i < 4; i++ {
+		time.Sleep(time.Millisecond * 20)
+		if _, ok := m.Get("key"); !ok {
+			t.Fatal("the key should be refreshed")
+		}
+	}
+
+	time.Sleep(time.Millisecond * 20)
+	if _, ok := m.Peek("key"); !ok {
+		t.Error("the key should not expire")
+	}
+	time.Sleep(time.Millisecond * 30)
+	if m.Touch("key") {
+		t.Error("the key should have expired")
+	}
+
+	m.Set("key", "value", time.Millisecond)
+	time.Sleep(time.Millisecond * 5)
+	if n := m.Cleanup(); n != 1 {
+		t.Errorf("expect to clean up 1 entry, but got %d", n)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package channels
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBatcherClosed is returned when adding the item into the closed batcher.
+var ErrBatcherClosed = errors.New("the batcher has been closed")
+
+// Batcher accumulates the items added from many goroutines, and flushes
+// them by batch when the number of the items reaches the batch size,
+// or the max delay has elapsed since the first item of the batch was added.
+//
+// The flush callback is called in a single goroutine in order.
+type Batcher struct {
+	size  int
+	delay time.Duration
+	flush func([]interface{})
+
+	lock    sync.RWMutex
+	closed  bool
+	items   chan interface{}
+	flushes chan chan struct{}
+	done    chan struct{}
+}
+
+// NewBatcher returns a new Batcher, and starts the background goroutine.
+//
+// If delay is equal to or less than 0, only flush by the batch size.
+func NewBatcher(size int, delay time.Duration, flush func(items []interface{})) *Batcher {
+	if size < 1 {
+		size = 1
+	}
+
+	b := &Batcher{
+		size:    size,
+		delay:   delay,
+		flush:   flush,
+		items:   make(chan interface{}, size),
+		flushes: make(chan chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go b.loop()
+	return b
+}
+
+// Add adds an item into the batch, which blocks if the batcher is busy
+// in flushing and the pending items are too many.
+func (b *Batcher) Add(item interface{}) error {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	if b.closed {
+		return ErrBatcherClosed
+	}
+
+	b.items <- item
+	return nil
+}
+
+// Flush flushes the items added before immediately, and waits until finishing.
+func (b *Batcher) Flush() {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	if !b.closed {
+		done := make(chan struct{})
+		b.flushes <- done
+		<-done
+	}
+}
+
+// Close closes the batcher, flushes the rest items and waits until finishing.
+func (b *Batcher) Close() {
+	b.lock.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.items)
+	}
+	b.lock.Unlock()
+	<-b.done
+}
+
+func (b *Batcher) loop() {
+	defer close(b.done)
+
+	batch := make([]interface{}, 0, b.size)
+	flush := func() {
+		if len(batch) > 0 {
+			b.flush(batch)
+			batch = make([]interface{}, 0, b.size)
+		}
+	}
+
+	var timer *time.Timer
+	var timeout <-chan time.Time
+	stopTimer := func() {
+		if timer != nil && !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timeout = nil
+	}
+
+	for {
+		select {
+		case item, ok := <-b.items:
+			if !ok {
+				stopTimer()
+				flush()
+				return
+			}
+
+			batch = append(batch, item)
+			if len(batch) >= b.size {
+				stopTimer()
+				flush()
+			} else if len(batch) == 1 && b.delay > 0 {
+				if timer == nil {
+					timer = time.NewTimer(b.delay)
+				} else {
+					timer.Reset(b.delay)
+				}
+				timeout = timer.C
+			}
+		case <-timeout:
+			timeout = nil
+			flush()
+		case done := <-b.flushes:
+			// Collect the items having been added before flushing.
+			for n := len(b.items); n > 0; n-- {
+				if batch = append(batch, <-b.items); len(batch) >= b.size {
+					flush()
+				}
+			}
+			stopTimer()
+			flush()
+			close(done)
+		}
+	}
+}
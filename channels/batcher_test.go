@@ -0,0 +1,107 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package channels
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type batchRecorder struct {
+	lock    sync.Mutex
+	batches [][]interface{}
+}
+
+func (r *batchRecorder) flush(items []interface{}) {
+	r.lock.Lock()
+	r.batches = append(r.batches, items)
+	r.lock.Unlock()
+}
+
+func (r *batchRecorder) sizes() (sizes []int) {
+	r.lock.Lock()
+	for _, b := range r.batches {
+		sizes = append(sizes, len(b))
+	}
+	r.lock.Unlock()
+	return
+}
+
+func TestBatcherSize(t *testing.T) {
+	var r batchRecorder
+	b := NewBatcher(3, 0, r.flush)
+	for i := 0; i < 7; i++ {
+		b.Add(i)
+	}
+	b.Close()
+
+	if sizes := r.sizes(); len(sizes) != 3 || sizes[0] != 3 || sizes[1] != 3 || sizes[2] != 1 {
+		t.Error(sizes)
+	}
+	if err := b.Add(1); err != ErrBatcherClosed {
+		t.Error(err)
+	}
+	b.Flush()
+	b.Close()
+}
+
+func TestBatcherDelay(t *testing.T) {
+	var r batchRecorder
+	b := NewBatcher(100, time.Millisecond*20, r.flush)
+	defer b.Close()
+
+	b.Add(1)
+	b.Add(2)
+	time.Sleep(time.Millisecond * 100)
+	if sizes := r.sizes(); len(sizes) != 1 || sizes[0] != 2 {
+		t.Error(sizes)
+	}
+
+	b.Add(3)
+	b.Flush()
+	if sizes := r.sizes(); len(sizes) != 2 || sizes[1] != 1 {
+		t.Error(sizes)
+	}
+}
+
+func TestBatcherConcurrency(t *testing.T) {
+	var r batchRecorder
+	b := NewBatcher(10, time.Millisecond, r.flush)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				b.Add(j)
+			}
+		}()
+	}
+	wg.Wait()
+	b.Close()
+
+	var total int
+	for _, size := range r.sizes() {
+		if size > 10 {
+			t.Errorf("the batch is too large: %d", size)
+		}
+		total += size
+	}
+	if total != 1000 {
+		t.Errorf("expect 1000 items, got %d", total)
+	}
+}
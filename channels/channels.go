@@ -0,0 +1,144 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package channels
+
+import (
+	"context"
+	"sync"
+)
+
+// Merge merges all the channels into one, which is closed after all
+// the channels are closed. The order of the elements from the same channel
+// is kept, but not among the different channels.
+func Merge(chs ...<-chan interface{}) <-chan interface{} {
+	out := make(chan interface{})
+
+	var wg sync.WaitGroup
+	wg.Add(len(chs))
+	for _, ch := range chs {
+		go func(ch <-chan interface{}) {
+			defer wg.Done()
+			for v := range ch {
+				out <- v
+			}
+		}(ch)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// FanOut distributes the elements of ch to n channels, which each element
+// is sent to only one of them that is ready at first. All the returned
+// channels are closed after ch is closed.
+func FanOut(ch <-chan interface{}, n int) []<-chan interface{} {
+	outs := make([]<-chan interface{}, n)
+	for i := 0; i < n; i++ {
+		out := make(chan interface{})
+		outs[i] = out
+		go func() {
+			defer close(out)
+			for v := range ch {
+				out <- v
+			}
+		}()
+	}
+	return outs
+}
+
+// Tee copies each element of ch to the n channels, which are closed after
+// ch is closed. The next element is not read until the current one has been
+// received by all the returned channels.
+func Tee(ch <-chan interface{}, n int) []<-chan interface{} {
+	outs := make([]chan interface{}, n)
+	results := make([]<-chan interface{}, n)
+	for i := range outs {
+		outs[i] = make(chan interface{})
+		results[i] = outs[i]
+	}
+
+	go func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+
+		var wg sync.WaitGroup
+		for v := range ch {
+			wg.Add(n)
+			for _, out := range outs {
+				go func(out chan<- interface{}, v interface{}) {
+					out <- v
+					wg.Done()
+				}(out, v)
+			}
+			wg.Wait()
+		}
+	}()
+
+	return results
+}
+
+// OrDone returns a channel forwarding the elements of ch, which is closed
+// when ch is closed or ctx is done.
+func OrDone(ctx context.Context, ch <-chan interface{}) <-chan interface{} {
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-ch:
+				if !ok {
+					return
+				}
+
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// FromSlice returns a channel sending the elements of the slice in order,
+// which is closed after sending all of them.
+func FromSlice(vs []interface{}) <-chan interface{} {
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		for _, v := range vs {
+			out <- v
+		}
+	}()
+	return out
+}
+
+// ToSlice reads all the elements from ch until it's closed,
+// and returns them in order.
+func ToSlice(ch <-chan interface{}) (vs []interface{}) {
+	for v := range ch {
+		vs = append(vs, v)
+	}
+	return
+}
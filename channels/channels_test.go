@@ -0,0 +1,115 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package channels
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func ints(vs []interface{}) []int {
+	results := make([]int, len(vs))
+	for i, v := range vs {
+		results[i] = v.(int)
+	}
+	return results
+}
+
+func TestMerge(t *testing.T) {
+	vs := ints(ToSlice(Merge(FromSlice([]interface{}{1, 3, 5}), FromSlice([]interface{}{2, 4}))))
+	if len(vs) != 5 {
+		t.Fatal(vs)
+	}
+
+	// The order of the same channel is kept.
+	var odds []int
+	for _, v := range vs {
+		if v%2 == 1 {
+			odds = append(odds, v)
+		}
+	}
+	if !sort.IntsAreSorted(odds) {
+		t.Error(vs)
+	}
+
+	if vs := ToSlice(Merge()); len(vs) != 0 {
+		t.Error(vs)
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	outs := FanOut(FromSlice([]interface{}{1, 2, 3, 4, 5, 6}), 3)
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	var results []int
+	for _, out := range outs {
+		wg.Add(1)
+		go func(out <-chan interface{}) {
+			defer wg.Done()
+			vs := ints(ToSlice(out))
+			lock.Lock()
+			results = append(results, vs...)
+			lock.Unlock()
+		}(out)
+	}
+	wg.Wait()
+
+	if sort.Ints(results); len(results) != 6 || results[0] != 1 || results[5] != 6 {
+		t.Error(results)
+	}
+}
+
+func TestTee(t *testing.T) {
+	outs := Tee(FromSlice([]interface{}{1, 2, 3}), 2)
+
+	var wg sync.WaitGroup
+	results := make([][]int, 2)
+	for i, out := range outs {
+		wg.Add(1)
+		go func(i int, out <-chan interface{}) {
+			defer wg.Done()
+			results[i] = ints(ToSlice(out))
+		}(i, out)
+	}
+	wg.Wait()
+
+	for _, vs := range results {
+		if len(vs) != 3 || vs[0] != 1 || vs[1] != 2 || vs[2] != 3 {
+			t.Error(vs)
+		}
+	}
+}
+
+func TestOrDone(t *testing.T) {
+	if vs := ints(ToSlice(OrDone(context.Background(), FromSlice([]interface{}{1, 2})))); len(vs) != 2 {
+		t.Error(vs)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan interface{})
+	out := OrDone(ctx, ch)
+	go func() { ch <- 1 }()
+	if v := <-out; v.(int) != 1 {
+		t.Error(v)
+	}
+
+	cancel()
+	if _, ok := <-out; ok {
+		t.Error("expect the closed channel")
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"flag"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/xgfone/go-tools/option"
+)
+
+// Layer is the configuration layer that supplies the value.
+type Layer int
+
+// Predefine some layers from the lowest precedence to the highest.
+const (
+	LayerNone Layer = iota
+	LayerDefault
+	LayerFile
+	LayerEnv
+	LayerFlag
+)
+
+func (l Layer) String() string {
+	switch l {
+	case LayerNone:
+		return "none"
+	case LayerDefault:
+		return "default"
+	case LayerFile:
+		return "file"
+	case LayerEnv:
+		return "env"
+	case LayerFlag:
+		return "flag"
+	default:
+		return "unknown"
+	}
+}
+
+// Config is the layered configuration.
+//
+// The values of the environment variables and the flags are looked up
+// when getting the option, and they are always the strings.
+type Config struct {
+	// EnvPrefix is the prefix of the environment variables. The option
+	// "server.addr" is looked up by the environment variable
+	// "<EnvPrefix>SERVER_ADDR", which replaces "." and "-" with "_".
+	EnvPrefix string
+
+	// Flags is the command-line flags. If nil, the flag layer is disabled.
+	//
+	// Only the flags set explicitly, whose name is the same as the option,
+	// are used.
+	Flags *flag.FlagSet
+
+	lock     sync.RWMutex
+	defaults map[string]interface{}
+	file     map[string]interface{}
+}
+
+// New returns a new Config.
+func New() *Config {
+	return &Config{
+		defaults: make(map[string]interface{}),
+		file:     make(map[string]interface{}),
+	}
+}
+
+// EnvName returns the name of the environment variable of the option name.
+func (c *Config) EnvName(name string) string {
+	name = strings.NewReplacer(".", "_", "-", "_").Replace(name)
+	return c.EnvPrefix + strings.ToUpper(name)
+}
+
+// SetDefault sets the default value of the option name.
+func (c *Config) SetDefault(name string, value interface{}) *Config {
+	c.lock.Lock()
+	c.defaults[name] = value
+	c.lock.Unlock()
+	return c
+}
+
+// SetDefaults sets the default values of the options.
+func (c *Config) SetDefaults(values map[string]interface{}) *Config {
+	c.lock.Lock()
+	for name, value := range values {
+		c.defaults[name] = value
+	}
+	c.lock.Unlock()
+	return c
+}
+
+// SetFileValues replaces all the values of the file layer,
+// and the nested maps are flattened by joining the keys with ".".
+func (c *Config) SetFileValues(values map[string]interface{}) {
+	file := make(map[string]interface{}, len(values))
+	flatten(file, "", values)
+
+	c.lock.Lock()
+	c.file = file
+	c.lock.Unlock()
+}
+
+// withFile returns a copy of the configuration with the new file layer.
+func (c *Config) withFile(values map[string]interface{}) *Config {
+	nc := New()
+	nc.EnvPrefix = c.EnvPrefix
+	nc.Flags = c.Flags
+	c.lock.RLock()
+	for name, value := range c.defaults {
+		nc.defaults[name] = value
+	}
+	c.lock.RUnlock()
+	nc.file = values
+	return nc
+}
+
+// Lookup returns the option name and the layer supplying it.
+//
+// If no layer supplies it, it returns the NamedNone option and LayerNone.
+func (c *Config) Lookup(name string) (option.NamedOption, Layer) {
+	if c.Flags != nil {
+		if opt := option.FromFlag(c.Flags, name); opt.IsSome() {
+			return opt, LayerFlag
+		}
+	}
+
+	if opt := option.FromEnv(c.EnvName(name)); opt.IsSome() {
+		return option.NamedSome(name, opt.Value()), LayerEnv
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if v, ok := c.file[name]; ok {
+		return option.NamedSome(name, v), LayerFile
+	} else if v, ok := c.defaults[name]; ok {
+		return option.NamedSome(name, v), LayerDefault
+	}
+	return option.NamedNone(name), LayerNone
+}
+
+// Get returns the option name with the value from the highest layer.
+func (c *Config) Get(name string) option.NamedOption {
+	opt, _ := c.Lookup(name)
+	return opt
+}
+
+// Source returns the layer supplying the option name.
+func (c *Config) Source(name string) Layer {
+	_, layer := c.Lookup(name)
+	return layer
+}
+
+// Names returns the sorted names of all the options, which are
+// the union of the defaults, the file and the flags set explicitly.
+func (c *Config) Names() []string {
+	names := make(map[string]struct{})
+	c.lock.RLock()
+	for name := range c.defaults {
+		names[name] = struct{}{}
+	}
+	for name := range c.file {
+		names[name] = struct{}{}
+	}
+	c.lock.RUnlock()
+
+	if c.Flags != nil {
+		c.Flags.Visit(func(f *flag.Flag) { names[f.Name] = struct{}{} })
+	}
+
+	results := make([]string, 0, len(names))
+	for name := range names {
+		results = append(results, name)
+	}
+	sort.Strings(results)
+	return results
+}
+
+// Sources returns the layers supplying all the options returned by Names.
+func (c *Config) Sources() map[string]Layer {
+	names := c.Names()
+	sources := make(map[string]Layer, len(names))
+	for _, name := range names {
+		sources[name] = c.Source(name)
+	}
+	return sources
+}
+
+// Options returns the snapshot of all the options returned by Names.
+func (c *Config) Options() *option.Options {
+	names := c.Names()
+	opts := make([]option.NamedOption, len(names))
+	for i, name := range names {
+		opts[i] = c.Get(name)
+	}
+	return option.NewOptions(opts...)
+}
+
+// ScanStruct is equal to c.Options().ScanStruct(dst).
+func (c *Config) ScanStruct(dst interface{}) error {
+	return c.Options().ScanStruct(dst)
+}
@@ -0,0 +1,127 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfig(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("server.addr", ":80", "")
+	fs.Int("server.port", 80, "")
+	fs.Parse([]string{"-server.addr", ":8080"})
+
+	conf := New()
+	conf.EnvPrefix = "CONFIG_TEST_"
+	conf.Flags = fs
+	conf.SetDefaults(map[string]interface{}{
+		"server.addr":    ":80",
+		"server.timeout": "1s",
+		"log.level":      "info",
+		"db.host":        "localhost",
+	})
+	conf.SetFileValues(map[string]interface{}{
+		"log": map[string]interface{}{"level": "debug"},
+		"db":  map[string]interface{}{"host": "127.0.0.1", "port": 3306},
+	})
+
+	os.Setenv("CONFIG_TEST_DB_HOST", "db.local")
+	defer os.Unsetenv("CONFIG_TEST_DB_HOST")
+
+	for name, expect := range map[string]struct {
+		value string
+		layer Layer
+	}{
+		"server.addr":    {":8080", LayerFlag},
+		"db.host":        {"db.local", LayerEnv},
+		"log.level":      {"debug", LayerFile},
+		"server.timeout": {"1s", LayerDefault},
+		"server.port":    {"", LayerNone}, // Not set explicitly.
+	} {
+		opt, layer := conf.Lookup(name)
+		if layer != expect.layer {
+			t.Errorf("%s: expect the layer '%s', but got '%s'", name, expect.layer, layer)
+		}
+		if layer != LayerNone && opt.Str() != expect.value {
+			t.Errorf("%s: expect '%s', but got '%s'", name, expect.value, opt.Str())
+		}
+	}
+
+	if port := conf.Get("db.port").Int(); port != 3306 {
+		t.Errorf("expect the port 3306, but got %d", port)
+	}
+
+	sources := conf.Sources()
+	if len(sources) != 5 || sources["db.port"] != LayerFile {
+		t.Errorf("unexpected sources: %v", sources)
+	}
+
+	var c struct {
+		Timeout time.Duration `option:"server.timeout"`
+		Port    int           `option:"db.port"`
+	}
+	if err := conf.ScanStruct(&c); err != nil {
+		t.Fatal(err)
+	} else if c.Timeout != time.Second || c.Port != 3306 {
+		t.Errorf("unexpected struct: %+v", c)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	jsonFile := filepath.Join(dir, "app.json")
+	ioutil.WriteFile(jsonFile, []byte(`{"server": {"addr": ":80", "port": 80, "rate": 0.5}}`), 0644)
+	propFile := filepath.Join(dir, "app.conf")
+	ioutil.WriteFile(propFile, []byte("# comment\n\nserver.addr = :81\nname: \"app\"\n"), 0644)
+
+	conf := New()
+	if err := conf.LoadFile(jsonFile); err != nil {
+		t.Fatal(err)
+	}
+	if v := conf.Get("server.port").Value(); v != int64(80) {
+		t.Errorf("expect the int64 port, but got %T", v)
+	}
+	if v := conf.Get("server.rate").Float64(); v != 0.5 {
+		t.Errorf("expect the rate 0.5, but got %v", v)
+	}
+
+	if err := conf.LoadFile(propFile); err != nil {
+		t.Fatal(err)
+	}
+	if addr := conf.Get("server.addr").Str(); addr != ":81" {
+		t.Errorf("unexpected addr '%s'", addr)
+	}
+	if name := conf.Get("name").Str(); name != "app" {
+		t.Errorf("unexpected name '%s'", name)
+	}
+	if conf.Source("server.port") != LayerNone {
+		t.Error("expect to replace the old values of the file layer")
+	}
+
+	if _, err := ParseProperties([]byte("invalid")); err == nil {
+		t.Error("expect an error")
+	}
+}
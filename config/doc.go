@@ -0,0 +1,34 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config supplies the layered configuration, which resolves
+// the value of the option by the precedence:
+//
+//	default < file < environment < command-line flag
+//
+// Example
+//
+//	conf := config.New()
+//	conf.EnvPrefix = "APP_"
+//	conf.Flags = flag.CommandLine
+//	conf.SetDefault("server.addr", ":80")
+//	if err := conf.LoadFile("app.json"); err != nil {
+//		// ...
+//	}
+//
+//	// Look up the flag "server.addr", the environment variable
+//	// "APP_SERVER_ADDR", the key "server.addr" in the file,
+//	// then the default value in turn.
+//	addr := conf.Get("server.addr").Str()
+package config
@@ -0,0 +1,134 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// LoadFile loads the file as the file layer, which replaces the old values.
+//
+// If the extension of the file is ".json", it's parsed by ParseJSON.
+// Or it's parsed by ParseProperties.
+func (c *Config) LoadFile(path string) error {
+	values, err := ReadFile(path)
+	if err != nil {
+		return err
+	}
+	c.SetFileValues(values)
+	return nil
+}
+
+// ReadFile reads and parses the configuration file by its extension
+// like LoadFile, and returns the flattened values.
+func ReadFile(path string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		values, err = ParseJSON(data)
+	} else {
+		values, err = ParseProperties(data)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file '%s': %s", path, err)
+	}
+	return values, nil
+}
+
+// ParseJSON parses the JSON object, and flattens the nested objects
+// by joining the keys with ".", for example,
+//
+//	{"server": {"addr": ":80"}} => {"server.addr": ":80"}
+func ParseJSON(data []byte) (map[string]interface{}, error) {
+	var ms map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&ms); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(ms))
+	flatten(values, "", ms)
+	return values, nil
+}
+
+// ParseProperties parses the lines of "key = value" or "key: value",
+// and ignores the empty lines and the comment lines starting with "#" or ";".
+func ParseProperties(data []byte) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		index := strings.IndexAny(line, "=:")
+		if index < 1 {
+			return nil, fmt.Errorf("line %d: invalid line '%s'", lineno, line)
+		}
+
+		key := strings.TrimSpace(line[:index])
+		value := strings.TrimSpace(line[index+1:])
+		if n := len(value); n > 1 && value[0] == '"' && value[n-1] == '"' {
+			value = value[1 : n-1]
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+func flatten(dst map[string]interface{}, prefix string, src map[string]interface{}) {
+	for key, value := range src {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if ms, ok := value.(map[string]interface{}); ok {
+			flatten(dst, key, ms)
+		} else {
+			dst[key] = normalize(value)
+		}
+	}
+}
+
+// normalize converts json.Number to int64, or float64 if not an integer.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		} else if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case []interface{}:
+		for i := range v {
+			v[i] = normalize(v[i])
+		}
+	}
+	return value
+}
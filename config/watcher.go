@@ -0,0 +1,199 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/xgfone/go-tools/eventbus"
+	"github.com/xgfone/go-tools/option"
+	"github.com/xgfone/go-tools/signal2"
+	"github.com/xgfone/go-tools/sync2"
+)
+
+// ChangedTopic is the topic of the event published by Watcher
+// when the configuration changes, and the event data is Change.
+const ChangedTopic = "config.changed"
+
+// Change is the change of the configuration after reloading.
+type Change struct {
+	// Keys is the sorted names of the options that are added,
+	// removed or changed.
+	Keys []string
+
+	Old *option.Options
+	New *option.Options
+}
+
+// Watcher reloads the configuration file when it changes or receiving
+// the signal, then swaps the active snapshot atomically and notifies
+// the subscribers of the changed keys.
+type Watcher struct {
+	// Interval is the interval to check whether the file changes
+	// by the modification time and the size. The default is one second.
+	Interval time.Duration
+
+	// Signals is the signals to reload the file. The default is SIGHUP.
+	Signals []os.Signal
+
+	// Validate is optional, which validates the new snapshot before
+	// swapping it. If it returns an error, the new one is discarded.
+	Validate func(snapshot *option.Options) error
+
+	// Bus is optional, which publishes the event with ChangedTopic
+	// if some options change after reloading.
+	Bus *eventbus.Bus
+
+	// OnError is optional, which is called when failing to reload.
+	OnError func(err error)
+
+	conf     *Config
+	path     string
+	lock     sync.Mutex
+	snapshot sync2.AtomicValue
+	modTime  time.Time
+	size     int64
+}
+
+// NewWatcher returns a new Watcher to reload the configuration file path
+// as the file layer of conf.
+func NewWatcher(conf *Config, path string) *Watcher {
+	w := &Watcher{conf: conf, path: path}
+	w.snapshot.Set(conf.Options())
+	return w
+}
+
+// Snapshot returns the active snapshot of the configuration.
+func (w *Watcher) Snapshot() *option.Options {
+	return w.snapshot.Get().(*option.Options)
+}
+
+// Get is equal to w.Snapshot().Get(name).
+func (w *Watcher) Get(name string) option.NamedOption {
+	return w.Snapshot().Get(name)
+}
+
+// Reload re-reads and validates the configuration file, then swaps
+// the active snapshot and publishes the change if validated.
+//
+// If failing, the active snapshot and conf are not changed. And the failed
+// file won't be reloaded by Watch again until it changes or a signal comes.
+func (w *Watcher) Reload() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	stat, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+	w.modTime, w.size = stat.ModTime(), stat.Size()
+
+	values, err := ReadFile(w.path)
+	if err != nil {
+		return err
+	}
+
+	snapshot := w.conf.withFile(values).Options()
+	if w.Validate != nil {
+		if err = w.Validate(snapshot); err != nil {
+			return err
+		}
+	}
+
+	w.conf.SetFileValues(values)
+	old := w.snapshot.Swap(snapshot).(*option.Options)
+	if keys := diff(old, snapshot); len(keys) > 0 && w.Bus != nil {
+		w.Bus.Publish(ChangedTopic, Change{Keys: keys, Old: old, New: snapshot})
+	}
+	return nil
+}
+
+func (w *Watcher) changed() bool {
+	stat, err := os.Stat(w.path)
+	if err != nil {
+		return false
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return !stat.ModTime().Equal(w.modTime) || stat.Size() != w.size
+}
+
+func (w *Watcher) reload() {
+	if err := w.Reload(); err != nil && w.OnError != nil {
+		w.OnError(err)
+	}
+}
+
+// Watch loads the configuration file at first, then reloads it
+// when it changes or receiving the signals, which blocks until ctx is done.
+func (w *Watcher) Watch(ctx context.Context) {
+	signals := w.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	reload := make(chan struct{}, 1)
+	stop := signal2.OnSignal(func(os.Signal) {
+		select {
+		case reload <- struct{}{}:
+		default:
+		}
+	}, signals[0], signals[1:]...)
+	defer stop()
+
+	interval := w.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	w.reload()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-reload:
+			w.reload()
+		case <-ticker.C:
+			if w.changed() {
+				w.reload()
+			}
+		}
+	}
+}
+
+func diff(prev, next *option.Options) (keys []string) {
+	for _, opt := range next.Options() {
+		if o, ok := prev.Lookup(opt.Name()); !ok || o.IsSome() != opt.IsSome() ||
+			!reflect.DeepEqual(o.Value(), opt.Value()) {
+			keys = append(keys, opt.Name())
+		}
+	}
+	for _, name := range prev.Names() {
+		if !next.Has(name) {
+			keys = append(keys, name)
+		}
+	}
+	sort.Strings(keys)
+	return
+}
@@ -0,0 +1,112 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-tools/eventbus"
+	"github.com/xgfone/go-tools/option"
+)
+
+func TestWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.conf")
+	if err = ioutil.WriteFile(path, []byte("addr = :80\nlevel = info\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bus := eventbus.New()
+	defer bus.Close()
+	changes := make(chan Change, 4)
+	bus.Subscribe(ChangedTopic, 4, eventbus.Block, func(e eventbus.Event) {
+		changes <- e.Data.(Change)
+	})
+
+	conf := New().SetDefault("timeout", "1s")
+	errs := make(chan error, 4)
+	w := NewWatcher(conf, path)
+	w.Interval = time.Millisecond * 10
+	w.Bus = bus
+	w.OnError = func(err error) { errs <- err }
+	w.Validate = func(snapshot *option.Options) error {
+		if snapshot.Get("addr").IsNone() {
+			return errors.New("missing addr")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Watch(ctx)
+
+	expectChange := func(keys string) {
+		select {
+		case c := <-changes:
+			if s := strings.Join(c.Keys, ","); s != keys {
+				t.Errorf("expect the changed keys '%s', but got '%s'", keys, s)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expect the change of '%s'", keys)
+		}
+	}
+
+	expectChange("addr,level")
+	if addr := w.Get("addr").Str(); addr != ":80" {
+		t.Errorf("unexpected addr '%s'", addr)
+	}
+
+	ioutil.WriteFile(path, []byte("addr = :8080\nlevel = info\nname = app\n"), 0644)
+	expectChange("addr,name")
+	if addr := w.Get("addr").Str(); addr != ":8080" || conf.Get("addr").Str() != ":8080" {
+		t.Errorf("unexpected addr '%s'", addr)
+	}
+
+	// The invalid config is discarded, and not reloaded again until it changes.
+	tmp := path + ".tmp"
+	ioutil.WriteFile(tmp, []byte("level = debug\n"), 0644)
+	os.Rename(tmp, path)
+	select {
+	case err := <-errs:
+		if err.Error() != "missing addr" {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expect a validation error")
+	}
+	select {
+	case err := <-errs:
+		t.Errorf("unexpected reloading the unchanged invalid file: %v", err)
+	case <-time.After(time.Millisecond * 100):
+	}
+	if level := w.Get("level").Str(); level != "info" || conf.Get("level").Str() != "info" {
+		t.Errorf("unexpected level '%s'", level)
+	}
+	if timeout, err := w.Get("timeout").ToDuration(); err != nil || timeout != time.Second {
+		t.Errorf("unexpected timeout '%s'", timeout)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package context2
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type mergedContext struct {
+	context.Context
+	other context.Context
+}
+
+func (c mergedContext) Value(key interface{}) interface{} {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.other.Value(key)
+}
+
+func (c mergedContext) String() string {
+	return fmt.Sprintf("context2.MergeCancel(%v, %v)", c.Context, c.other)
+}
+
+// MergeCancel returns a new context, which is cancelled when either of
+// ctx1 and ctx2 is cancelled or the returned cancel function is called.
+//
+// The deadline is the earlier one of ctx1 and ctx2, and the value is
+// looked up in ctx1 first, then ctx2.
+func MergeCancel(ctx1, ctx2 context.Context) (context.Context, context.CancelFunc) {
+	var deadline2 bool
+	ctx, cancel := context.WithCancel(ctx1)
+	if d2, ok := ctx2.Deadline(); ok {
+		if d1, ok := ctx1.Deadline(); !ok || d2.Before(d1) {
+			var cancel2 context.CancelFunc
+			cancel1 := cancel
+			ctx, cancel2 = context.WithDeadline(ctx, d2)
+			cancel = func() { cancel2(); cancel1() }
+			deadline2 = true
+		}
+	}
+
+	if done := ctx2.Done(); done != nil {
+		go func() {
+			select {
+			case <-done:
+				// Let the inherited deadline expire by itself
+				// to report context.DeadlineExceeded.
+				if !deadline2 || ctx2.Err() != context.DeadlineExceeded {
+					cancel()
+				}
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	return mergedContext{Context: ctx, other: ctx2}, cancel
+}
+
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (deadline time.Time, ok bool) { return }
+func (c detachedContext) Done() <-chan struct{}                   { return nil }
+func (c detachedContext) Err() error                              { return nil }
+func (c detachedContext) Value(key interface{}) interface{}       { return c.parent.Value(key) }
+func (c detachedContext) String() string {
+	return fmt.Sprintf("context2.Detach(%v)", c.parent)
+}
+
+// Detach returns a new context, which keeps the values of ctx
+// but is never cancelled and has no deadline, which is used to run
+// the fire-and-forget work outliving the request, for example,
+//
+//	go sendNotification(context2.Detach(ctx), msg)
+func Detach(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+type valuesContext struct {
+	context.Context
+	values map[interface{}]interface{}
+}
+
+func (c valuesContext) Value(key interface{}) interface{} {
+	if v, ok := c.values[key]; ok {
+		return v
+	}
+	return c.Context.Value(key)
+}
+
+func (c valuesContext) String() string {
+	return fmt.Sprintf("%v.WithValues(%v)", c.Context, c.values)
+}
+
+// WithValues returns a copy of ctx with all the key-value pairs in values,
+// which is equal to calling context.WithValue for each of them.
+//
+// The map is copied, so the later changes to it does not affect the context.
+func WithValues(ctx context.Context, values map[interface{}]interface{}) context.Context {
+	if len(values) == 0 {
+		return ctx
+	}
+
+	vs := make(map[interface{}]interface{}, len(values))
+	for k, v := range values {
+		vs[k] = v
+	}
+	return valuesContext{Context: ctx, values: vs}
+}
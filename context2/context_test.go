@@ -0,0 +1,96 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package context2
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type ctxKey string
+
+func TestMergeCancel(t *testing.T) {
+	ctx1 := context.WithValue(context.Background(), ctxKey("k1"), "v1")
+	ctx2, cancel2 := context.WithCancel(context.WithValue(context.Background(), ctxKey("k2"), "v2"))
+
+	ctx, cancel := MergeCancel(ctx1, ctx2)
+	defer cancel()
+	if ctx.Value(ctxKey("k1")) != "v1" || ctx.Value(ctxKey("k2")) != "v2" {
+		t.Error("failed to look up the values")
+	}
+
+	cancel2()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Error("expect the merged context to be cancelled")
+	}
+
+	ctx3, cancel3 := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel3()
+	ctx, cancel = MergeCancel(context.Background(), ctx3)
+	defer cancel()
+	if d, ok := ctx.Deadline(); !ok || !d.Equal(mustDeadline(ctx3)) {
+		t.Errorf("unexpected deadline: %v, %v", d, ok)
+	}
+	<-ctx.Done()
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("unexpected error: %v", ctx.Err())
+	}
+
+	ctx, cancel = MergeCancel(context.Background(), context.Background())
+	cancel()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("unexpected error: %v", ctx.Err())
+	}
+}
+
+func mustDeadline(ctx context.Context) time.Time {
+	d, _ := ctx.Deadline()
+	return d
+}
+
+func TestDetach(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(),
+		ctxKey("k"), "v"), time.Millisecond)
+	cancel()
+
+	ctx := Detach(parent)
+	if ctx.Err() != nil || ctx.Done() != nil {
+		t.Error("the detached context should not be cancelled")
+	}
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("the detached context should not have the deadline")
+	}
+	if ctx.Value(ctxKey("k")) != "v" {
+		t.Error("failed to look up the value")
+	}
+}
+
+func TestWithValues(t *testing.T) {
+	values := map[interface{}]interface{}{ctxKey("k1"): "v1", ctxKey("k2"): "v2"}
+	ctx := WithValues(context.WithValue(context.Background(), ctxKey("k3"), "v3"), values)
+	values[ctxKey("k1")] = "changed"
+
+	if ctx.Value(ctxKey("k1")) != "v1" || ctx.Value(ctxKey("k2")) != "v2" ||
+		ctx.Value(ctxKey("k3")) != "v3" || ctx.Value(ctxKey("k4")) != nil {
+		t.Error("failed to look up the values")
+	}
+
+	if ctx := context.Background(); WithValues(ctx, nil) != ctx {
+		t.Error("expect the original context")
+	}
+}
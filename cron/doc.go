@@ -0,0 +1,41 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cron supplies a lightweight scheduler to run the periodic jobs
+// by the cron expression or the fixed interval.
+//
+// The cron expression has five fields, or six fields with the leading
+// second field:
+//
+//	Field        | Values          | Special Characters
+//	------------ | --------------- | ------------------
+//	Second       | 0-59            | * / , -
+//	Minute       | 0-59            | * / , -
+//	Hour         | 0-23            | * / , -
+//	Day of month | 1-31            | * / , - ?
+//	Month        | 1-12 or JAN-DEC | * / , -
+//	Day of week  | 0-7 or SUN-SAT  | * / , - ?
+//
+// It also supports the predefined schedules, such as "@yearly", "@monthly",
+// "@weekly", "@daily", "@hourly" and "@every <duration>".
+//
+// Example
+//
+//	s := cron.NewScheduler()
+//	s.Add("*/5 * * * *", func(ctx context.Context) { /* clean up */ })
+//	s.Every(time.Minute, func(ctx context.Context) { /* report */ },
+//		cron.WithOverlap(cron.OverlapQueue), cron.WithJitter(time.Second))
+//	s.Start()
+//	defer s.Stop()
+package cron
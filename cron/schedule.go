@@ -0,0 +1,285 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is used to calculate the next time to run the job.
+type Schedule interface {
+	// Next returns the next time after t, or the zero time if no next one.
+	Next(t time.Time) time.Time
+}
+
+// Every returns a schedule running at the fixed interval,
+// which panics if interval is not positive.
+func Every(interval time.Duration) Schedule {
+	if interval <= 0 {
+		panic("cron: the interval must be positive")
+	}
+	return everySchedule(interval)
+}
+
+type everySchedule time.Duration
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+type bounds struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	secondBounds = bounds{min: 0, max: 59}
+	minuteBounds = bounds{min: 0, max: 59}
+	hourBounds   = bounds{min: 0, max: 23}
+	domBounds    = bounds{min: 1, max: 31}
+	monthBounds  = bounds{min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowBounds = bounds{min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// starBit is set when the field is "*" or "?".
+const starBit = 1 << 63
+
+type specSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+}
+
+// Parse parses the cron expression and returns the schedule.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(spec[7:]))
+		if err != nil {
+			return nil, fmt.Errorf("cron: invalid spec '%s': %s", spec, err)
+		} else if d <= 0 {
+			return nil, fmt.Errorf("cron: invalid spec '%s': non-positive duration", spec)
+		}
+		return Every(d), nil
+	} else if s, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = s
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron: invalid spec '%s': expect 5 or 6 fields", spec)
+	}
+
+	var s specSchedule
+	var err error
+	for i, f := range []struct {
+		bits   *uint64
+		bounds bounds
+	}{
+		{&s.second, secondBounds},
+		{&s.minute, minuteBounds},
+		{&s.hour, hourBounds},
+		{&s.dom, domBounds},
+		{&s.month, monthBounds},
+		{&s.dow, dowBounds},
+	} {
+		if *f.bits, err = parseField(fields[i], f.bounds); err != nil {
+			return nil, fmt.Errorf("cron: invalid spec '%s': %s", spec, err)
+		}
+	}
+
+	// Sunday is both 0 and 7.
+	if s.dow&(1<<7) != 0 {
+		s.dow = (s.dow | 1) &^ (1 << 7)
+	}
+	return s, nil
+}
+
+// MustParse is the same as Parse, but panics if there is an error.
+func MustParse(spec string) Schedule {
+	s, err := Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func parseField(field string, b bounds) (bits uint64, err error) {
+	for _, expr := range strings.Split(field, ",") {
+		var v uint64
+		if v, err = parseRange(expr, b); err != nil {
+			return
+		}
+		bits |= v
+	}
+	return
+}
+
+func parseRange(expr string, b bounds) (bits uint64, err error) {
+	var start, end, step uint = 0, 0, 1
+	rangeAndStep := strings.Split(expr, "/")
+	lowAndHigh := strings.Split(rangeAndStep[0], "-")
+
+	var star bool
+	switch {
+	case len(rangeAndStep) > 2, len(lowAndHigh) > 2:
+		return 0, fmt.Errorf("invalid field '%s'", expr)
+	case lowAndHigh[0] == "*" || lowAndHigh[0] == "?":
+		if len(lowAndHigh) != 1 {
+			return 0, fmt.Errorf("invalid field '%s'", expr)
+		}
+		start, end, star = b.min, b.max, true
+	default:
+		if start, err = parseValue(lowAndHigh[0], b); err != nil {
+			return
+		}
+		end = start
+		if len(lowAndHigh) == 2 {
+			if end, err = parseValue(lowAndHigh[1], b); err != nil {
+				return
+			}
+		}
+	}
+
+	if len(rangeAndStep) == 2 {
+		n, err := strconv.ParseUint(rangeAndStep[1], 10, 8)
+		if err != nil || n == 0 {
+			return 0, fmt.Errorf("invalid step in '%s'", expr)
+		}
+		step = uint(n)
+		if !star && len(lowAndHigh) == 1 {
+			end = b.max // "N/step" means "N-max/step".
+		}
+		star = false
+	}
+
+	if start > end {
+		return 0, fmt.Errorf("invalid range '%s'", expr)
+	}
+	for i := start; i <= end; i += step {
+		bits |= 1 << i
+	}
+	if star {
+		bits |= starBit
+	}
+	return
+}
+
+func parseValue(s string, b bounds) (uint, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s'", s)
+	} else if uint(v) < b.min || uint(v) > b.max {
+		return 0, fmt.Errorf("value '%s' out of range [%d, %d]", s, b.min, b.max)
+	}
+	return uint(v), nil
+}
+
+func (s specSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.dom&starBit != 0 || s.dow&starBit != 0 {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func (s specSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Add(time.Second - time.Duration(t.Nanosecond())*time.Nanosecond)
+	yearLimit := t.Year() + 5
+
+	added := false
+WRAP:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for s.month&(1<<uint(t.Month())) == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		}
+		if t = t.AddDate(0, 1, 0); t.Month() == time.January {
+			goto WRAP
+		}
+	}
+
+	for !s.dayMatches(t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		}
+		if t = t.AddDate(0, 0, 1); t.Day() == 1 {
+			goto WRAP
+		}
+	}
+
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+		}
+		if t = t.Add(time.Hour); t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Minute)
+		}
+		if t = t.Add(time.Minute); t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+
+	for s.second&(1<<uint(t.Second())) == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Second)
+		}
+		if t = t.Add(time.Second); t.Second() == 0 {
+			goto WRAP
+		}
+	}
+
+	return t
+}
@@ -0,0 +1,65 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	base := time.Date(2019, 3, 15, 10, 20, 30, 500, time.UTC)
+	for _, c := range []struct {
+		spec string
+		next string
+	}{
+		{"* * * * *", "2019-03-15 10:21:00"},
+		{"* * * * * *", "2019-03-15 10:20:31"},
+		{"*/15 * * * *", "2019-03-15 10:30:00"},
+		{"5 * * * *", "2019-03-15 11:05:00"},
+		{"0 9-17/4 * * *", "2019-03-15 13:00:00"},
+		{"0 0 1 * *", "2019-04-01 00:00:00"},
+		{"0 0 * * sun", "2019-03-17 00:00:00"},
+		{"0 0 * * 7", "2019-03-17 00:00:00"},
+		{"0 0 1,20 * mon", "2019-03-18 00:00:00"},
+		{"0 0 29 feb *", "2020-02-29 00:00:00"},
+		{"30 12 * JAN-MAR ?", "2019-03-15 12:30:00"},
+		{"@daily", "2019-03-16 00:00:00"},
+		{"@hourly", "2019-03-15 11:00:00"},
+		{"@yearly", "2020-01-01 00:00:00"},
+		{"@every 1h30m", "2019-03-15 11:50:30"},
+	} {
+		s, err := Parse(c.spec)
+		if err != nil {
+			t.Errorf("%s: %s", c.spec, err)
+			continue
+		}
+
+		if next := s.Next(base).Format("2006-01-02 15:04:05"); next != c.next {
+			t.Errorf("%s: expect '%s', but got '%s'", c.spec, c.next, next)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *",
+		"*/0 * * * *", "5-1 * * * *", "* * * foo *", "@every -1s", "@every x"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("%s: expect an error", spec)
+		}
+	}
+
+	if s := MustParse("0 0 30 2 *"); !s.Next(base).IsZero() {
+		t.Error("expect no next time")
+	}
+}
@@ -0,0 +1,372 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-tools/sync2"
+)
+
+// Job is the periodic job, and ctx is cancelled when the scheduler is stopped.
+type Job func(ctx context.Context)
+
+// JobID is the id of the job in the scheduler.
+type JobID int64
+
+// OverlapPolicy is the policy when the job is scheduled again
+// while the last run has not finished.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip skips the new run, which is the default.
+	OverlapSkip OverlapPolicy = iota
+
+	// OverlapQueue runs the job again after the last run finishes.
+	OverlapQueue
+
+	// OverlapConcurrent runs the job concurrently.
+	OverlapConcurrent
+)
+
+// JobOption is used to configure the job.
+type JobOption func(*entry)
+
+// WithName returns a job option to set the name of the job.
+func WithName(name string) JobOption {
+	return func(e *entry) { e.name = name }
+}
+
+// WithOverlap returns a job option to set the overlap policy of the job.
+func WithOverlap(policy OverlapPolicy) JobOption {
+	return func(e *entry) { e.overlap = policy }
+}
+
+// WithJitter returns a job option to delay each run of the job
+// by a random duration in [0, jitter), which avoids that many jobs
+// run at the same time.
+func WithJitter(jitter time.Duration) JobOption {
+	return func(e *entry) { e.jitter = jitter }
+}
+
+// Entry is the information of the job in the scheduler.
+type Entry struct {
+	ID   JobID
+	Name string
+
+	// Next is the next time to run the job, which is zero
+	// if the scheduler has not been started.
+	Next time.Time
+
+	// Prev is the last time the job has been scheduled.
+	Prev time.Time
+
+	// Runs is the number of the runs. Skips is the number of the runs
+	// skipped by OverlapSkip.
+	Runs  uint64
+	Skips uint64
+}
+
+type entry struct {
+	id       JobID
+	name     string
+	job      Job
+	schedule Schedule
+	overlap  OverlapPolicy
+	jitter   time.Duration
+
+	next    time.Time
+	prev    time.Time
+	runs    uint64
+	skips   uint64
+	running int
+	pending int
+}
+
+// Scheduler is used to run the jobs periodically.
+type Scheduler struct {
+	// Location is the time zone to calculate the schedule.
+	// The default is time.Local.
+	Location *time.Location
+
+	// PanicHandler is called when a job panics. If nil, ignore it.
+	PanicHandler func(id JobID, panic interface{}, stack []byte)
+
+	lock    sync.Mutex
+	entries map[JobID]*entry
+	lastID  JobID
+	wake    chan struct{}
+	started bool
+	stopped bool
+	ctx     context.Context
+	cancel  func()
+	loop    chan struct{}
+	jobs    sync2.WaitGroupTimeout
+}
+
+// NewScheduler returns a new Scheduler.
+func NewScheduler() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		entries: make(map[JobID]*entry),
+		wake:    make(chan struct{}, 1),
+		loop:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+func (s *Scheduler) now() time.Time {
+	if s.Location != nil {
+		return time.Now().In(s.Location)
+	}
+	return time.Now()
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Add parses the cron expression spec, and adds the job with it.
+func (s *Scheduler) Add(spec string, job Job, opts ...JobOption) (JobID, error) {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return 0, err
+	}
+	return s.Schedule(schedule, job, opts...), nil
+}
+
+// Every adds the job running at the fixed interval.
+func (s *Scheduler) Every(interval time.Duration, job Job, opts ...JobOption) JobID {
+	return s.Schedule(Every(interval), job, opts...)
+}
+
+// Schedule adds the job with the schedule, and returns its id.
+func (s *Scheduler) Schedule(schedule Schedule, job Job, opts ...JobOption) JobID {
+	e := &entry{job: job, schedule: schedule}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	s.lock.Lock()
+	s.lastID++
+	e.id = s.lastID
+	if s.started {
+		e.next = schedule.Next(s.now())
+	}
+	s.entries[e.id] = e
+	s.lock.Unlock()
+
+	s.notify()
+	return e.id
+}
+
+// Remove removes the job by the id, and reports whether it exists.
+//
+// The running job is not interrupted.
+func (s *Scheduler) Remove(id JobID) bool {
+	s.lock.Lock()
+	_, ok := s.entries[id]
+	delete(s.entries, id)
+	s.lock.Unlock()
+
+	if ok {
+		s.notify()
+	}
+	return ok
+}
+
+// Entries returns the information of all the jobs sorted by the next time.
+func (s *Scheduler) Entries() []Entry {
+	s.lock.Lock()
+	entries := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, Entry{
+			ID:    e.id,
+			Name:  e.name,
+			Next:  e.next,
+			Prev:  e.prev,
+			Runs:  e.runs,
+			Skips: e.skips,
+		})
+	}
+	s.lock.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Next.Equal(entries[j].Next) {
+			return entries[i].ID < entries[j].ID
+		}
+		return entries[i].Next.Before(entries[j].Next)
+	})
+	return entries
+}
+
+// Start starts the scheduler in a new goroutine, which does nothing
+// if the scheduler has been started or stopped.
+func (s *Scheduler) Start() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.started || s.stopped {
+		return
+	}
+
+	s.started = true
+	now := s.now()
+	for _, e := range s.entries {
+		e.next = e.schedule.Next(now)
+	}
+	go s.run()
+}
+
+// Stop stops the scheduler, cancels the context of the running jobs,
+// and waits until they finish.
+func (s *Scheduler) Stop() {
+	s.StopTimeout(0)
+}
+
+// StopTimeout is the same as Stop, but waits for the running jobs
+// with the timeout, and reports whether they have finished.
+//
+// If timeout is equal to or less than 0, wait until they finish.
+func (s *Scheduler) StopTimeout(timeout time.Duration) bool {
+	s.lock.Lock()
+	started, stopped := s.started, s.stopped
+	s.stopped = true
+	s.lock.Unlock()
+
+	if !stopped {
+		s.cancel()
+		if started {
+			<-s.loop
+		}
+	}
+	return s.jobs.Wait(timeout)
+}
+
+func (s *Scheduler) run() {
+	defer close(s.loop)
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		var next time.Time
+		s.lock.Lock()
+		for _, e := range s.entries {
+			if !e.next.IsZero() && (next.IsZero() || e.next.Before(next)) {
+				next = e.next
+			}
+		}
+		s.lock.Unlock()
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+
+		var timeout <-chan time.Time
+		if !next.IsZero() {
+			timer.Reset(next.Sub(s.now()))
+			timeout = timer.C
+		}
+
+		select {
+		case <-timeout:
+			s.trigger(s.now())
+		case <-s.wake:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Scheduler) trigger(now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, e := range s.entries {
+		if e.next.IsZero() || e.next.After(now) {
+			continue
+		}
+
+		e.prev = e.next
+		e.next = e.schedule.Next(now)
+		if e.running > 0 {
+			switch e.overlap {
+			case OverlapSkip:
+				e.skips++
+				continue
+			case OverlapQueue:
+				e.pending++
+				continue
+			}
+		}
+
+		e.running++
+		s.jobs.Add(1)
+		go s.runJob(e)
+	}
+}
+
+func (s *Scheduler) runJob(e *entry) {
+	defer s.jobs.Done()
+	for {
+		if e.jitter > 0 {
+			timer := time.NewTimer(time.Duration(rand.Int63n(int64(e.jitter))))
+			select {
+			case <-timer.C:
+			case <-s.ctx.Done():
+				timer.Stop()
+			}
+		}
+
+		if s.ctx.Err() == nil {
+			s.lock.Lock()
+			e.runs++
+			s.lock.Unlock()
+			s.callJob(e)
+		}
+
+		s.lock.Lock()
+		if e.pending > 0 && s.ctx.Err() == nil {
+			e.pending--
+			s.lock.Unlock()
+			continue
+		}
+		e.pending = 0
+		e.running--
+		s.lock.Unlock()
+		return
+	}
+}
+
+func (s *Scheduler) callJob(e *entry) {
+	defer func() {
+		if v := recover(); v != nil && s.PanicHandler != nil {
+			buf := make([]byte, 4096)
+			s.PanicHandler(e.id, v, buf[:runtime.Stack(buf, false)])
+		}
+	}()
+	e.job(s.ctx)
+}
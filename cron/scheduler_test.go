@@ -0,0 +1,135 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSchedulerEvery(t *testing.T) {
+	var runs int32
+	s := NewScheduler()
+	id := s.Every(time.Millisecond*20, func(context.Context) { atomic.AddInt32(&runs, 1) },
+		WithName("job"))
+	s.Start()
+
+	time.Sleep(time.Millisecond * 110)
+	if entries := s.Entries(); len(entries) != 1 || entries[0].ID != id ||
+		entries[0].Name != "job" || entries[0].Next.IsZero() || entries[0].Runs == 0 {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+
+	if !s.Remove(id) || s.Remove(id) {
+		t.Error("failed to remove the job")
+	}
+	n := atomic.LoadInt32(&runs)
+	if n < 3 || n > 6 {
+		t.Errorf("unexpected the number of the runs: %d", n)
+	}
+
+	time.Sleep(time.Millisecond * 50)
+	if m := atomic.LoadInt32(&runs); m != n {
+		t.Errorf("the removed job is still running: %d", m-n)
+	}
+	s.Stop()
+
+	if _, err := s.Add("* * *", func(context.Context) {}); err == nil {
+		t.Error("expect an error")
+	}
+}
+
+func TestSchedulerOverlap(t *testing.T) {
+	var skip, queue, concurrent, maxConcurrent int32
+	slow := func(counter *int32) Job {
+		return func(ctx context.Context) {
+			atomic.AddInt32(counter, 1)
+			time.Sleep(time.Millisecond * 55)
+		}
+	}
+
+	s := NewScheduler()
+	s.Every(time.Millisecond*20, slow(&skip), WithOverlap(OverlapSkip))
+	s.Every(time.Millisecond*20, slow(&queue), WithOverlap(OverlapQueue))
+	s.Every(time.Millisecond*20, func(ctx context.Context) {
+		n := atomic.AddInt32(&concurrent, 1)
+		defer atomic.AddInt32(&concurrent, -1)
+		for {
+			max := atomic.LoadInt32(&maxConcurrent)
+			if n <= max || atomic.CompareAndSwapInt32(&maxConcurrent, max, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond * 55)
+	}, WithOverlap(OverlapConcurrent))
+	s.Start()
+
+	time.Sleep(time.Millisecond * 130)
+	s.Stop()
+
+	if n := atomic.LoadInt32(&skip); n < 1 || n > 3 {
+		t.Errorf("unexpected the number of the runs for OverlapSkip: %d", n)
+	}
+	if n := atomic.LoadInt32(&queue); n < 2 || n > 4 {
+		t.Errorf("unexpected the number of the runs for OverlapQueue: %d", n)
+	}
+	if n := atomic.LoadInt32(&maxConcurrent); n < 2 {
+		t.Errorf("expect the concurrent runs, but got %d", n)
+	}
+
+	var skips uint64
+	for _, e := range s.Entries() {
+		skips += e.Skips
+	}
+	if skips == 0 {
+		t.Error("expect some skipped runs")
+	}
+}
+
+func TestSchedulerPanicAndStop(t *testing.T) {
+	panics := make(chan interface{}, 10)
+	s := NewScheduler()
+	s.PanicHandler = func(id JobID, v interface{}, stack []byte) { panics <- v }
+	s.Every(time.Millisecond*10, func(context.Context) { panic("test") })
+
+	var cancelled int32
+	started := make(chan struct{}, 10)
+	s.Every(time.Millisecond*10, func(ctx context.Context) {
+		started <- struct{}{}
+		<-ctx.Done()
+		time.Sleep(time.Millisecond * 20)
+		atomic.StoreInt32(&cancelled, 1)
+	}, WithJitter(time.Millisecond*5))
+	s.Start()
+
+	select {
+	case v := <-panics:
+		if v != "test" {
+			t.Errorf("unexpected panic: %v", v)
+		}
+	case <-time.After(time.Second):
+		t.Error("expect a panic")
+	}
+
+	<-started
+	if !s.StopTimeout(time.Second) {
+		t.Error("the running jobs did not finish")
+	} else if atomic.LoadInt32(&cancelled) != 1 {
+		t.Error("Stop did not wait for the running jobs")
+	}
+	s.Stop()
+}
@@ -0,0 +1,227 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Setter is used to set the default values by itself, which is called
+// after filling the fields by the tags.
+type Setter interface {
+	SetDefaults()
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// Set fills the zero-valued fields of the struct that ptr points to
+// by the tag "default", and the nested structs recursively.
+//
+// The supported field types are bool, string, the integers, the floats,
+// time.Duration, time.Time, and the pointers, slices, maps and structs
+// of them. The slice is split by the comma, such as "a,b,c", and the map
+// is split by the comma and the colon, such as "a:1,b:2". The slice,
+// the map and the struct may be also in JSON, such as `[1,2]`.
+//
+// The nil pointer to struct without the tag is left to nil.
+func Set(ptr interface{}) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("defaults: the argument must be a pointer to struct")
+	}
+	return setStruct(v.Elem(), "")
+}
+
+// MustSet is the same as Set, but panics if there is an error.
+func MustSet(ptr interface{}) {
+	if err := Set(ptr); err != nil {
+		panic(err)
+	}
+}
+
+func setStruct(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i, n := 0, t.NumField(); i < n; i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" { // Unexported
+			continue
+		}
+
+		tag, hasTag := field.Tag.Lookup("default")
+		if tag == "-" {
+			continue
+		}
+
+		path := prefix + field.Name
+		if err := setField(v.Field(i), tag, hasTag, path); err != nil {
+			return err
+		}
+	}
+
+	if v.CanAddr() {
+		if s, ok := v.Addr().Interface().(Setter); ok {
+			s.SetDefaults()
+		}
+	}
+	return nil
+}
+
+func setField(v reflect.Value, tag string, hasTag bool, path string) error {
+	if hasTag && isZero(v) {
+		if err := parse(v, tag); err != nil {
+			return fmt.Errorf("defaults: field '%s': %s", path, err)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() != timeType {
+			return setStruct(v, path+".")
+		}
+	case reflect.Ptr:
+		if !v.IsNil() && v.Elem().Kind() == reflect.Struct && v.Elem().Type() != timeType {
+			return setStruct(v.Elem(), path+".")
+		}
+	}
+	return nil
+}
+
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	case reflect.Struct:
+		return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+	default:
+		return v.Interface() == reflect.Zero(v.Type()).Interface()
+	}
+}
+
+func isJSON(s string, open, close byte) bool {
+	s = strings.TrimSpace(s)
+	return len(s) > 1 && s[0] == open && s[len(s)-1] == close
+}
+
+func parse(v reflect.Value, s string) (err error) {
+	switch t := v.Type(); {
+	case t == durationType:
+		var d time.Duration
+		if d, err = time.ParseDuration(s); err == nil {
+			v.SetInt(int64(d))
+		}
+		return
+	case t == timeType:
+		var tm time.Time
+		if tm, err = time.Parse(time.RFC3339, s); err == nil {
+			v.Set(reflect.ValueOf(tm))
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 0, v.Type().Bits()); err == nil {
+			v.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 0, v.Type().Bits()); err == nil {
+			v.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err = parse(elem.Elem(), s); err == nil {
+			v.Set(elem)
+		}
+	case reflect.Slice:
+		if isJSON(s, '[', ']') {
+			return unmarshal(v, s)
+		}
+
+		var ss []string
+		if s = strings.TrimSpace(s); s != "" {
+			ss = strings.Split(s, ",")
+		}
+		slice := reflect.MakeSlice(v.Type(), len(ss), len(ss))
+		for i, e := range ss {
+			if err = parse(slice.Index(i), strings.TrimSpace(e)); err != nil {
+				return
+			}
+		}
+		v.Set(slice)
+	case reflect.Map:
+		if isJSON(s, '{', '}') {
+			return unmarshal(v, s)
+		}
+
+		t := v.Type()
+		m := reflect.MakeMap(t)
+		for _, pair := range strings.Split(s, ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+
+			kv := strings.SplitN(pair, ":", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("invalid map pair '%s'", pair)
+			}
+
+			key, value := reflect.New(t.Key()).Elem(), reflect.New(t.Elem()).Elem()
+			if err = parse(key, strings.TrimSpace(kv[0])); err != nil {
+				return
+			} else if err = parse(value, strings.TrimSpace(kv[1])); err != nil {
+				return
+			}
+			m.SetMapIndex(key, value)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		return unmarshal(v, s)
+	default:
+		err = fmt.Errorf("unsupported type '%s'", v.Type())
+	}
+	return
+}
+
+func unmarshal(v reflect.Value, s string) error {
+	ptr := reflect.New(v.Type())
+	if err := json.Unmarshal([]byte(s), ptr.Interface()); err != nil {
+		return err
+	}
+	v.Set(ptr.Elem())
+	return nil
+}
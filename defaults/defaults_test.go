@@ -0,0 +1,101 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"testing"
+	"time"
+)
+
+type logConfig struct {
+	Level string `default:"info"`
+	Files []string
+}
+
+type serverConfig struct {
+	Addr    string         `default:":80"`
+	Port    uint16         `default:"8080"`
+	Debug   bool           `default:"true"`
+	Ratio   float64        `default:"0.5"`
+	Timeout time.Duration  `default:"30s"`
+	Hosts   []string       `default:"a, b"`
+	Ports   []int          `default:"[1, 2]"`
+	Labels  map[string]int `default:"a:1,b:2"`
+	MaxConn *int           `default:"100"`
+	Ignored string         `default:"-"`
+	Log     logConfig
+	Sub     *logConfig
+	Nil     *logConfig
+
+	called bool
+}
+
+func (c *serverConfig) SetDefaults() { c.called = true }
+
+func TestSet(t *testing.T) {
+	c := serverConfig{Addr: ":81", Sub: &logConfig{}}
+	if err := Set(&c); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Addr != ":81" {
+		t.Errorf("expected the non-zero ':81', but got '%s'", c.Addr)
+	}
+	if c.Port != 8080 || !c.Debug || c.Ratio != 0.5 || c.Timeout != 30*time.Second {
+		t.Errorf("unexpected scalars: %d, %v, %v, %s", c.Port, c.Debug, c.Ratio, c.Timeout)
+	}
+	if len(c.Hosts) != 2 || c.Hosts[0] != "a" || c.Hosts[1] != "b" {
+		t.Errorf("unexpected hosts: %v", c.Hosts)
+	}
+	if len(c.Ports) != 2 || c.Ports[0] != 1 || c.Ports[1] != 2 {
+		t.Errorf("unexpected ports: %v", c.Ports)
+	}
+	if len(c.Labels) != 2 || c.Labels["a"] != 1 || c.Labels["b"] != 2 {
+		t.Errorf("unexpected labels: %v", c.Labels)
+	}
+	if c.MaxConn == nil || *c.MaxConn != 100 {
+		t.Errorf("unexpected max conn: %v", c.MaxConn)
+	}
+	if c.Ignored != "" {
+		t.Errorf("unexpected ignored: '%s'", c.Ignored)
+	}
+	if c.Log.Level != "info" || c.Sub.Level != "info" {
+		t.Errorf("unexpected log levels: '%s', '%s'", c.Log.Level, c.Sub.Level)
+	}
+	if c.Nil != nil {
+		t.Errorf("expected the nil pointer to be left")
+	}
+	if !c.called {
+		t.Errorf("SetDefaults is not called")
+	}
+}
+
+func TestSetError(t *testing.T) {
+	var c struct {
+		Log struct {
+			Port int `default:"abc"`
+		}
+	}
+
+	if err := Set(&c); err == nil {
+		t.Error("expected an error")
+	} else if s := err.Error(); s[:31] != "defaults: field 'Log.Port': str" {
+		t.Error(s)
+	}
+
+	if err := Set(c); err == nil {
+		t.Error("expected an error for the non-pointer")
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package defaults fills the zero-valued fields of the struct
+// by the default values in the tag "default".
+//
+// Example
+//
+//	type Config struct {
+//		Addr    string        `default:":80"`
+//		Timeout time.Duration `default:"30s"`
+//		Hosts   []string      `default:"a.example.com,b.example.com"`
+//		Labels  map[string]int `default:"a:1,b:2"`
+//		Log     struct {
+//			Level string `default:"info"`
+//		}
+//	}
+//
+//	var c Config
+//	if err := defaults.Set(&c); err != nil {
+//		// ...
+//	}
+package defaults
@@ -0,0 +1,288 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Code is the machine-readable code of the error, which is used to classify
+// the error, such as to decide whether to retry.
+type Code int
+
+// Predefine some error codes.
+const (
+	CodeOK Code = iota
+	CodeUnknown
+	CodeCanceled
+	CodeInvalidArgument
+	CodeNotFound
+	CodeAlreadyExists
+	CodePermissionDenied
+	CodeUnauthenticated
+	CodeResourceExhausted
+	CodeUnavailable
+	CodeTimeout
+	CodeUnimplemented
+	CodeInternal
+)
+
+var codeNames = map[Code]string{
+	CodeOK:                "OK",
+	CodeUnknown:           "Unknown",
+	CodeCanceled:          "Canceled",
+	CodeInvalidArgument:   "InvalidArgument",
+	CodeNotFound:          "NotFound",
+	CodeAlreadyExists:     "AlreadyExists",
+	CodePermissionDenied:  "PermissionDenied",
+	CodeUnauthenticated:   "Unauthenticated",
+	CodeResourceExhausted: "ResourceExhausted",
+	CodeUnavailable:       "Unavailable",
+	CodeTimeout:           "Timeout",
+	CodeUnimplemented:     "Unimplemented",
+	CodeInternal:          "Internal",
+}
+
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Code(%d)", int(c))
+}
+
+// Temporary reports whether the error with the code is temporary,
+// that's, the operation may succeed by retrying later, which includes
+// CodeResourceExhausted, CodeUnavailable and CodeTimeout.
+func (c Code) Temporary() bool {
+	switch c {
+	case CodeResourceExhausted, CodeUnavailable, CodeTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+var codeStatuses = map[Code]int{
+	CodeOK:                http.StatusOK,
+	CodeUnknown:           http.StatusInternalServerError,
+	CodeCanceled:          499, // Client Closed Request
+	CodeInvalidArgument:   http.StatusBadRequest,
+	CodeNotFound:          http.StatusNotFound,
+	CodeAlreadyExists:     http.StatusConflict,
+	CodePermissionDenied:  http.StatusForbidden,
+	CodeUnauthenticated:   http.StatusUnauthorized,
+	CodeResourceExhausted: http.StatusTooManyRequests,
+	CodeUnavailable:       http.StatusServiceUnavailable,
+	CodeTimeout:           http.StatusGatewayTimeout,
+	CodeUnimplemented:     http.StatusNotImplemented,
+	CodeInternal:          http.StatusInternalServerError,
+}
+
+// HTTPStatus returns the HTTP status code corresponding to the code,
+// which is 500 for the unknown code.
+func (c Code) HTTPStatus() int {
+	if status, ok := codeStatuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// CodeFromHTTPStatus returns the error code corresponding to the HTTP status.
+func CodeFromHTTPStatus(status int) Code {
+	switch {
+	case status < 400:
+		return CodeOK
+	case status == http.StatusBadRequest:
+		return CodeInvalidArgument
+	case status == http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case status == http.StatusForbidden:
+		return CodePermissionDenied
+	case status == http.StatusNotFound:
+		return CodeNotFound
+	case status == http.StatusConflict:
+		return CodeAlreadyExists
+	case status == http.StatusRequestTimeout, status == http.StatusGatewayTimeout:
+		return CodeTimeout
+	case status == http.StatusTooManyRequests:
+		return CodeResourceExhausted
+	case status == 499:
+		return CodeCanceled
+	case status == http.StatusNotImplemented:
+		return CodeUnimplemented
+	case status == http.StatusBadGateway, status == http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case status < 500:
+		return CodeInvalidArgument
+	default:
+		return CodeInternal
+	}
+}
+
+// CodedError is an error with the code.
+type CodedError struct {
+	Code Code
+	Msg  string
+	Err  error
+}
+
+// NewCode returns a new CodedError with the code and the message.
+func NewCode(code Code, msg string) *CodedError {
+	return &CodedError{Code: code, Msg: msg}
+}
+
+// WithCode returns a new CodedError wrapping err with the code.
+//
+// Return nil if err is nil.
+func WithCode(err error, code Code) error {
+	if err == nil {
+		return nil
+	}
+	return &CodedError{Code: code, Err: err}
+}
+
+func (e *CodedError) Error() string {
+	switch {
+	case e.Err == nil && e.Msg == "":
+		return e.Code.String()
+	case e.Err == nil:
+		return e.Msg
+	case e.Msg == "":
+		return e.Err.Error()
+	default:
+		return e.Msg + ": " + e.Err.Error()
+	}
+}
+
+// Unwrap returns the wrapped error.
+func (e *CodedError) Unwrap() error { return e.Err }
+
+// Timeout reports whether the code is CodeTimeout, which is compatible
+// with net.Error.
+func (e *CodedError) Timeout() bool { return e.Code == CodeTimeout }
+
+// Temporary reports whether the code is temporary, which is compatible
+// with net.Error.
+func (e *CodedError) Temporary() bool { return e.Code.Temporary() }
+
+// walk traverses the error tree of err in the depth-first order
+// until f returns true.
+func walk(err error, f func(error) bool) bool {
+	for err != nil {
+		if f(err) {
+			return true
+		}
+
+		if m, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range m.Unwrap() {
+				if walk(e, f) {
+					return true
+				}
+			}
+			return false
+		}
+		err = Unwrap(err)
+	}
+	return false
+}
+
+// CodeOf returns the code of the first error in the error tree of err
+// that has the code, which understands some standard errors, such as
+//
+//	nil                      => CodeOK
+//	*CodedError              => its code
+//	context.Canceled         => CodeCanceled
+//	context.DeadlineExceeded => CodeTimeout
+//	net.Error with Timeout   => CodeTimeout
+//	net.Error with Temporary => CodeUnavailable
+//	os.IsNotExist            => CodeNotFound
+//	os.IsExist               => CodeAlreadyExists
+//	os.IsPermission          => CodePermissionDenied
+//
+// Or return CodeUnknown.
+func CodeOf(err error) Code {
+	if err == nil {
+		return CodeOK
+	}
+
+	code := CodeUnknown
+	walk(err, func(err error) bool {
+		if e, ok := err.(*CodedError); ok {
+			code = e.Code
+			return true
+		}
+
+		switch {
+		case err == context.Canceled:
+			code = CodeCanceled
+		case err == context.DeadlineExceeded:
+			code = CodeTimeout
+		case os.IsNotExist(err):
+			code = CodeNotFound
+		case os.IsExist(err):
+			code = CodeAlreadyExists
+		case os.IsPermission(err):
+			code = CodePermissionDenied
+		default:
+			if t, ok := err.(interface{ Timeout() bool }); ok && t.Timeout() {
+				code = CodeTimeout
+			} else if t, ok := err.(interface{ Temporary() bool }); ok && t.Temporary() {
+				code = CodeUnavailable
+			} else {
+				return false
+			}
+		}
+		return true
+	})
+	return code
+}
+
+// IsCode reports whether the code of err is code.
+func IsCode(err error, code Code) bool { return CodeOf(err) == code }
+
+// IsNotFound reports whether the code of err is CodeNotFound.
+func IsNotFound(err error) bool { return CodeOf(err) == CodeNotFound }
+
+// IsTimeout reports whether err is a timeout, that's, any error
+// in the error tree of err has the method Timeout() returning true,
+// such as net.Error and CodedError, or is context.DeadlineExceeded.
+func IsTimeout(err error) bool {
+	return walk(err, func(err error) bool {
+		if t, ok := err.(interface{ Timeout() bool }); ok && t.Timeout() {
+			return true
+		}
+		return err == context.DeadlineExceeded
+	})
+}
+
+// IsTemporary reports whether err is temporary, that's, any error
+// in the error tree of err has the method Temporary() or Timeout()
+// returning true, such as net.Error and CodedError,
+// or is context.DeadlineExceeded.
+//
+// It may be used to decide whether to retry.
+func IsTemporary(err error) bool {
+	return walk(err, func(err error) bool {
+		if t, ok := err.(interface{ Temporary() bool }); ok && t.Temporary() {
+			return true
+		} else if t, ok := err.(interface{ Timeout() bool }); ok && t.Timeout() {
+			return true
+		}
+		return err == context.DeadlineExceeded
+	})
+}
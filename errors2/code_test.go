@@ -0,0 +1,93 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors2
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+)
+
+type netError struct{ timeout, temporary bool }
+
+func (e netError) Error() string   { return "net error" }
+func (e netError) Timeout() bool   { return e.timeout }
+func (e netError) Temporary() bool { return e.temporary }
+
+var _ net.Error = netError{}
+
+func TestCodeOf(t *testing.T) {
+	_, notExist := os.Open("/path/to/not/exist/file")
+
+	cases := []struct {
+		err  error
+		code Code
+	}{
+		{nil, CodeOK},
+		{errors.New("error"), CodeUnknown},
+		{NewCode(CodeNotFound, "no user"), CodeNotFound},
+		{Wrap(WithCode(errors.New("error"), CodeInvalidArgument), "wrap"), CodeInvalidArgument},
+		{Wrap(context.Canceled, "wrap"), CodeCanceled},
+		{context.DeadlineExceeded, CodeTimeout},
+		{netError{timeout: true}, CodeTimeout},
+		{netError{temporary: true}, CodeUnavailable},
+		{notExist, CodeNotFound},
+		{Append(errors.New("error"), NewCode(CodeInternal, "")), CodeInternal},
+	}
+
+	for i, c := range cases {
+		if code := CodeOf(c.err); code != c.code {
+			t.Errorf("%d: expected code '%s', but got '%s'", i, c.code, code)
+		}
+	}
+
+	if !IsNotFound(notExist) || !IsCode(nil, CodeOK) {
+		t.Error("unexpected IsNotFound or IsCode")
+	}
+}
+
+func TestIsTemporary(t *testing.T) {
+	if !IsTimeout(Wrap(netError{timeout: true}, "wrap")) || !IsTimeout(context.DeadlineExceeded) {
+		t.Error("expected timeout")
+	}
+	if IsTimeout(netError{temporary: true}) || IsTimeout(errors.New("error")) {
+		t.Error("unexpected timeout")
+	}
+
+	if !IsTemporary(netError{temporary: true}) || !IsTemporary(NewCode(CodeUnavailable, "")) ||
+		!IsTemporary(WithCode(errors.New("error"), CodeTimeout)) {
+		t.Error("expected temporary")
+	}
+	if IsTemporary(NewCode(CodeNotFound, "")) || IsTemporary(nil) {
+		t.Error("unexpected temporary")
+	}
+}
+
+func TestCodeHTTPStatus(t *testing.T) {
+	for code := CodeOK; code <= CodeInternal; code++ {
+		if code == CodeUnknown {
+			continue
+		}
+		if c := CodeFromHTTPStatus(code.HTTPStatus()); c != code {
+			t.Errorf("expected code '%s', but got '%s'", code, c)
+		}
+	}
+
+	if s := NewCode(CodeTimeout, "").Error(); s != "Timeout" {
+		t.Errorf("unexpected error '%s'", s)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package errors2 is the supplement of the standard library of `errors`,
+// which supplies the errors with the stack traces and the wrapping.
+//
+// The wrapped errors implement the method Unwrap() error, so they work with
+// errors.Is and errors.As of Go 1.13+. For the older Go, you can use Is, As
+// and Unwrap of this package instead.
+//
+// The errors created by New, Errorf, Wrap and Wrapf support the %+v verb
+// to print the error chain with the stack frames, such as
+//
+//	err := errors2.Wrap(io.EOF, "failed to read the header")
+//	fmt.Printf("%+v\n", err)
+//
+//	(Output)
+//	failed to read the header: EOF
+//	    main.readHeader
+//	        /path/to/main.go:20
+//	    main.main
+//	        /path/to/main.go:10
+package errors2
@@ -0,0 +1,237 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors2
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// stackError is an error with the message, the stack trace and the cause.
+type stackError struct {
+	msg   string
+	cause error
+	stack stack
+}
+
+func (e *stackError) Error() string {
+	switch {
+	case e.cause == nil:
+		return e.msg
+	case e.msg == "":
+		return e.cause.Error()
+	default:
+		return e.msg + ": " + e.cause.Error()
+	}
+}
+
+func (e *stackError) Unwrap() error          { return e.cause }
+func (e *stackError) StackTrace() StackTrace { return e.stack.StackTrace() }
+
+// Format implements the interface fmt.Formatter.
+//
+// %s and %v print the error message, %q prints the quoted error message,
+// and %+v prints the error chain with the stack frames.
+func (e *stackError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			e.formatVerbose(s)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+func (e *stackError) formatVerbose(s fmt.State) {
+	io.WriteString(s, e.Error())
+	fmt.Fprintf(s, "%+v", e.StackTrace())
+
+	// Print the causes with their own stacks.
+	for err := e.cause; err != nil; err = Unwrap(err) {
+		if st, ok := err.(StackTracer); ok {
+			io.WriteString(s, "\nCaused by: "+err.Error())
+			fmt.Fprintf(s, "%+v", st.StackTrace())
+		}
+	}
+}
+
+// New returns a new error with the message and the stack trace.
+func New(msg string) error {
+	return &stackError{msg: msg, stack: callers(1)}
+}
+
+// Errorf is the same as New, but formats the message.
+func Errorf(format string, args ...interface{}) error {
+	return &stackError{msg: fmt.Sprintf(format, args...), stack: callers(1)}
+}
+
+// Wrap returns a new error wrapping err with the message and the stack trace.
+//
+// Return nil if err is nil.
+func Wrap(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return &stackError{msg: msg, cause: err, stack: callers(1)}
+}
+
+// Wrapf is the same as Wrap, but formats the message.
+func Wrapf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &stackError{msg: fmt.Sprintf(format, args...), cause: err, stack: callers(1)}
+}
+
+// WithStack returns a new error wrapping err with the stack trace
+// but without the extra message.
+//
+// Return nil if err is nil.
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &stackError{cause: err, stack: callers(1)}
+}
+
+// FromPanic converts the recovered panic value to an error with the stack
+// trace, which should be called in the deferred function, such as
+//
+//	defer func() {
+//		if v := recover(); v != nil {
+//			err = errors2.FromPanic(v)
+//		}
+//	}()
+//
+// Return nil if v is nil.
+func FromPanic(v interface{}) error {
+	switch e := v.(type) {
+	case nil:
+		return nil
+	case error:
+		// Skip the runtime frames of the panic.
+		return &stackError{msg: "panic", cause: e, stack: callers(3)}
+	default:
+		return &stackError{msg: fmt.Sprintf("panic: %v", v), stack: callers(3)}
+	}
+}
+
+// Unwrap returns the result of calling the method Unwrap() error on err,
+// or nil if err does not have the method.
+func Unwrap(err error) error {
+	if u, ok := err.(interface{ Unwrap() error }); ok {
+		return u.Unwrap()
+	}
+	return nil
+}
+
+// Cause returns the innermost error in the error chain of err.
+func Cause(err error) error {
+	for {
+		cause := Unwrap(err)
+		if cause == nil {
+			return err
+		}
+		err = cause
+	}
+}
+
+// Is reports whether any error in the error tree of err matches target,
+// which is the same as errors.Is of Go 1.13+.
+//
+// The error tree is traversed by the method Unwrap() error
+// or Unwrap() []error in the depth-first order.
+func Is(err, target error) bool {
+	if target == nil {
+		return err == target
+	}
+
+	comparable := reflect.TypeOf(target).Comparable()
+	for err != nil {
+		if comparable && reflect.TypeOf(err).Comparable() && err == target {
+			return true
+		}
+		if x, ok := err.(interface{ Is(error) bool }); ok && x.Is(target) {
+			return true
+		}
+
+		if m, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range m.Unwrap() {
+				if Is(e, target) {
+					return true
+				}
+			}
+			return false
+		}
+		err = Unwrap(err)
+	}
+	return false
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// As finds the first error in the error tree of err that matches target,
+// and sets target to that error value and returns true,
+// which is the same as errors.As of Go 1.13+.
+//
+// It panics if target is not a non-nil pointer to either a type that
+// implements error, or to any interface type.
+func As(err error, target interface{}) bool {
+	if target == nil {
+		panic("errors2: target cannot be nil")
+	}
+
+	val := reflect.ValueOf(target)
+	typ := val.Type()
+	if typ.Kind() != reflect.Ptr || val.IsNil() {
+		panic("errors2: target must be a non-nil pointer")
+	}
+
+	targetType := typ.Elem()
+	if targetType.Kind() != reflect.Interface && !targetType.Implements(errorType) {
+		panic("errors2: *target must be interface or implement error")
+	}
+
+	return as(err, target, val, targetType)
+}
+
+func as(err error, target interface{}, val reflect.Value, targetType reflect.Type) bool {
+	for err != nil {
+		if reflect.TypeOf(err).AssignableTo(targetType) {
+			val.Elem().Set(reflect.ValueOf(err))
+			return true
+		}
+		if x, ok := err.(interface{ As(interface{}) bool }); ok && x.As(target) {
+			return true
+		}
+
+		if m, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range m.Unwrap() {
+				if as(e, target, val, targetType) {
+					return true
+				}
+			}
+			return false
+		}
+		err = Unwrap(err)
+	}
+	return false
+}
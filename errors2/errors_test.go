@@ -0,0 +1,88 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors2
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	err := Wrap(io.EOF, "read")
+	if s := err.Error(); s != "read: EOF" {
+		t.Errorf("unexpected error '%s'", s)
+	}
+
+	err = Wrapf(err, "file '%s'", "a.txt")
+	if s := fmt.Sprintf("%v", err); s != "file 'a.txt': read: EOF" {
+		t.Errorf("unexpected error '%s'", s)
+	}
+
+	if !Is(err, io.EOF) || Is(err, io.ErrUnexpectedEOF) {
+		t.Error("unexpected Is")
+	}
+	if Cause(err) != io.EOF {
+		t.Errorf("unexpected cause '%v'", Cause(err))
+	}
+	if Wrap(nil, "msg") != nil || Wrapf(nil, "msg") != nil || WithStack(nil) != nil {
+		t.Error("expected nil")
+	}
+
+	var perr *os.PathError
+	err = WithStack(&os.PathError{Op: "open", Path: "a.txt", Err: io.EOF})
+	if !As(err, &perr) || perr.Path != "a.txt" {
+		t.Error("unexpected As")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	err := Wrap(New("inner"), "outer")
+	s := fmt.Sprintf("%+v", err)
+	if !strings.HasPrefix(s, "outer: inner\n    github.com/xgfone/go-tools/errors2.TestFormat\n") {
+		t.Errorf("unexpected format '%s'", s)
+	}
+	if !strings.Contains(s, "\nCaused by: inner\n") {
+		t.Errorf("no cause in '%s'", s)
+	}
+
+	st := StackOf(err)
+	if len(st) == 0 || st[0].Function != "github.com/xgfone/go-tools/errors2.TestFormat" {
+		t.Errorf("unexpected stack %v", st)
+	}
+
+	if s := fmt.Sprintf("%q", err); s != `"outer: inner"` {
+		t.Errorf("unexpected quoted error '%s'", s)
+	}
+}
+
+func panicFunc() { panic("oops") }
+
+func TestFromPanic(t *testing.T) {
+	err := func() (err error) {
+		defer func() { err = FromPanic(recover()) }()
+		panicFunc()
+		return
+	}()
+
+	if err == nil || err.Error() != "panic: oops" {
+		t.Fatalf("unexpected error '%v'", err)
+	}
+	if st := StackOf(err); st[0].Function != "github.com/xgfone/go-tools/errors2.panicFunc" {
+		t.Errorf("unexpected stack %+v", st)
+	}
+}
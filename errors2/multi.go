@@ -0,0 +1,128 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors2
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MultiFormatter is used to format the errors of Multi as the error message.
+type MultiFormatter func(errs []error) string
+
+// ListFormatter is the default formatter of Multi, such as
+//
+//	2 errors occurred:
+//		* error1
+//		* error2
+//
+// It returns the message of the error directly if there is only one error.
+func ListFormatter(errs []error) string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d errors occurred:", len(errs))
+	for _, err := range errs {
+		b.WriteString("\n\t* ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// JoinFormatter returns a formatter to join the error messages by sep.
+func JoinFormatter(sep string) MultiFormatter {
+	return func(errs []error) string {
+		ss := make([]string, len(errs))
+		for i, err := range errs {
+			ss[i] = err.Error()
+		}
+		return strings.Join(ss, sep)
+	}
+}
+
+// Multi is an error containing a set of errors, which is used to report
+// all the failures instead of only the first.
+type Multi struct {
+	Errors []error
+
+	// Formatter is used to format the error message.
+	// If nil, use ListFormatter by default.
+	Formatter MultiFormatter
+}
+
+// Append appends the errors into err and returns it as Multi.
+//
+// If err is nil or a nil *Multi, a new Multi is created. If err is not
+// a *Multi, it is regarded as the first error. The nil errors are ignored,
+// and the *Multi errors in errs are flattened.
+func Append(err error, errs ...error) *Multi {
+	m, ok := err.(*Multi)
+	if !ok || m == nil {
+		m = &Multi{}
+		if !ok && err != nil {
+			m.Errors = append(m.Errors, err)
+		}
+	}
+
+	for _, e := range errs {
+		switch e := e.(type) {
+		case nil:
+		case *Multi:
+			if e != nil {
+				m.Errors = append(m.Errors, e.Errors...)
+			}
+		default:
+			m.Errors = append(m.Errors, e)
+		}
+	}
+	return m
+}
+
+// Error implements the interface error.
+func (m *Multi) Error() string {
+	if len(m.Errors) == 0 {
+		return "no errors"
+	} else if m.Formatter != nil {
+		return m.Formatter(m.Errors)
+	}
+	return ListFormatter(m.Errors)
+}
+
+// Len returns the number of the errors.
+func (m *Multi) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.Errors)
+}
+
+// ErrorOrNil returns nil if there are no errors, or m itself.
+func (m *Multi) ErrorOrNil() error {
+	if m == nil || len(m.Errors) == 0 {
+		return nil
+	}
+	return m
+}
+
+// Unwrap returns the errors, which is compatible with errors.Is and
+// errors.As of Go 1.20+, and Is and As of this package.
+func (m *Multi) Unwrap() []error {
+	if m == nil {
+		return nil
+	}
+	return append([]error(nil), m.Errors...)
+}
@@ -0,0 +1,70 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors2
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestMulti(t *testing.T) {
+	var m *Multi
+	if m.ErrorOrNil() != nil || m.Len() != 0 {
+		t.Error("expected the empty multi")
+	}
+
+	m = Append(nil, nil)
+	if m.ErrorOrNil() != nil {
+		t.Error("expected nil")
+	}
+
+	var nilMulti *Multi
+	if m = Append(nilMulti, io.EOF); m.Len() != 1 || m.Errors[0] != io.EOF {
+		t.Errorf("unexpected errors: %v", m.Errors)
+	}
+
+	err1 := errors.New("error1")
+	m = Append(err1, nil, io.EOF)
+	m = Append(m, Append(nil, &os.PathError{Op: "open", Path: "a", Err: io.EOF}))
+	if m.Len() != 3 {
+		t.Fatalf("expected 3 errors, but got %d", m.Len())
+	}
+
+	expect := "3 errors occurred:\n\t* error1\n\t* EOF\n\t* open a: EOF"
+	if s := m.Error(); s != expect {
+		t.Errorf("expected '%s', but got '%s'", expect, s)
+	}
+
+	m.Formatter = JoinFormatter("; ")
+	if s := m.Error(); s != "error1; EOF; open a: EOF" {
+		t.Errorf("unexpected error '%s'", s)
+	}
+
+	err := Wrap(m.ErrorOrNil(), "close")
+	if !Is(err, err1) || !Is(err, io.EOF) || Is(err, io.ErrClosedPipe) {
+		t.Error("unexpected Is")
+	}
+
+	var perr *os.PathError
+	if !As(err, &perr) || perr.Path != "a" {
+		t.Error("unexpected As")
+	}
+
+	if s := Append(nil, err1).Error(); s != "error1" {
+		t.Errorf("unexpected error '%s'", s)
+	}
+}
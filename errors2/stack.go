@@ -0,0 +1,101 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors2
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+)
+
+// Frame is a stack frame.
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+func (f Frame) String() string {
+	return fmt.Sprintf("%s:%d", f.File, f.Line)
+}
+
+// StackTrace is the stack frames from the innermost to the outermost.
+type StackTrace []Frame
+
+// Format implements the interface fmt.Formatter.
+//
+// %s and %v print the frames as "file:line" separated by the space,
+// and %+v prints one function and its location per two lines.
+func (st StackTrace) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		for _, f := range st {
+			fmt.Fprintf(s, "\n    %s\n        %s:%d", f.Function, f.File, f.Line)
+		}
+		return
+	}
+
+	ss := make([]string, len(st))
+	for i, f := range st {
+		ss[i] = f.String()
+	}
+	io.WriteString(s, "["+strings.Join(ss, " ")+"]")
+}
+
+// stack is the program counters of the stack.
+type stack []uintptr
+
+func callers(skip int) stack {
+	var pcs [32]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	return stack(pcs[:n:n])
+}
+
+func (s stack) StackTrace() StackTrace {
+	if len(s) == 0 {
+		return nil
+	}
+
+	frames := runtime.CallersFrames(s)
+	trace := make(StackTrace, 0, len(s))
+	for {
+		frame, more := frames.Next()
+		trace = append(trace, Frame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+		if !more {
+			break
+		}
+	}
+	return trace
+}
+
+// StackTracer is an error with the stack trace.
+type StackTracer interface {
+	StackTrace() StackTrace
+}
+
+// StackOf returns the innermost stack trace in the error chain of err,
+// which is nil if no error has the stack trace.
+func StackOf(err error) (st StackTrace) {
+	for ; err != nil; err = Unwrap(err) {
+		if s, ok := err.(StackTracer); ok {
+			st = s.StackTrace()
+		}
+	}
+	return
+}
@@ -0,0 +1,300 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/xgfone/go-tools/sync2"
+	"github.com/xgfone/go-tools/types"
+)
+
+// AllTopics is the topic to subscribe the events of all the topics.
+const AllTopics = "*"
+
+// ErrBusClosed is returned when subscribing to the closed bus.
+var ErrBusClosed = errors.New("the event bus has been closed")
+
+// Event is an event published to the topic.
+type Event struct {
+	Topic string
+	Data  interface{}
+}
+
+// OverflowPolicy is the policy when the queue of the subscriber is full.
+type OverflowPolicy int
+
+// Predefine some overflow policies.
+const (
+	// Block blocks the publisher until the queue is not full.
+	Block OverflowPolicy = iota
+
+	// DropOldest drops the oldest event in the queue.
+	DropOldest
+
+	// DropNewest drops the event being published.
+	DropNewest
+)
+
+// Bus is an event bus.
+type Bus struct {
+	lock   sync.RWMutex
+	subs   map[string][]*Subscription
+	stops  []*Subscription
+	closed bool
+}
+
+// New returns a new event bus.
+func New() *Bus {
+	return &Bus{subs: make(map[string][]*Subscription)}
+}
+
+// Subscribe subscribes the events of the topic, which are handled by handler
+// in a goroutine of the subscription in order.
+//
+// If topic is AllTopics, subscribe the events of all the topics. size is
+// the size of the queue, which is 1 at least. policy is used when the queue
+// is full. The panic of handler is recovered and ignored.
+func (b *Bus) Subscribe(topic string, size int, policy OverflowPolicy,
+	handler func(Event)) (*Subscription, error) {
+	if size < 1 {
+		size = 1
+	}
+
+	s := &Subscription{
+		bus:     b,
+		topic:   topic,
+		size:    size,
+		policy:  policy,
+		handler: handler,
+		queue:   types.NewDeque(),
+		done:    make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.lock)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.closed {
+		return nil, ErrBusClosed
+	}
+
+	b.subs[topic] = append(b.subs[topic], s)
+	go s.loop()
+	return s, nil
+}
+
+// Publish publishes the event with data to the topic.
+//
+// It may block if the queue of a subscriber with the policy Block is full.
+func (b *Bus) Publish(topic string, data interface{}) {
+	b.lock.RLock()
+	subs := make([]*Subscription, 0, len(b.subs[topic])+len(b.subs[AllTopics]))
+	subs = append(subs, b.subs[topic]...)
+	if topic != AllTopics {
+		subs = append(subs, b.subs[AllTopics]...)
+	}
+	b.lock.RUnlock()
+
+	event := Event{Topic: topic, Data: data}
+	for _, s := range subs {
+		s.push(event)
+	}
+}
+
+// Topics returns the topics having the subscribers.
+func (b *Bus) Topics() []string {
+	b.lock.RLock()
+	topics := make([]string, 0, len(b.subs))
+	for topic := range b.subs {
+		topics = append(topics, topic)
+	}
+	b.lock.RUnlock()
+	return topics
+}
+
+// Close closes the bus and all the subscriptions without waiting,
+// so it may be called in the event handler. The queued events are still
+// handled, and Wait may be used to wait for them.
+func (b *Bus) Close() {
+	b.lock.Lock()
+	b.closed = true
+	subs := b.subs
+	b.subs = make(map[string][]*Subscription)
+	for _, ss := range subs {
+		for _, s := range ss {
+			s.close()
+			b.stops = append(b.stops, s)
+		}
+	}
+	b.lock.Unlock()
+}
+
+// Wait waits until all the subscriptions closed by Close have handled
+// their queued events. It must not be called in the event handler.
+func (b *Bus) Wait() {
+	b.lock.RLock()
+	stops := b.stops
+	b.lock.RUnlock()
+
+	for _, s := range stops {
+		s.Wait()
+	}
+}
+
+func (b *Bus) unsubscribe(s *Subscription) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	subs := b.subs[s.topic]
+	for i, sub := range subs {
+		if sub == s {
+			subs = append(subs[:i:i], subs[i+1:]...)
+			break
+		}
+	}
+
+	if len(subs) == 0 {
+		delete(b.subs, s.topic)
+	} else {
+		b.subs[s.topic] = subs
+	}
+}
+
+// Subscription is a subscription of the topic.
+type Subscription struct {
+	bus     *Bus
+	topic   string
+	size    int
+	policy  OverflowPolicy
+	handler func(Event)
+	dropped sync2.AtomicInt64
+
+	lock   sync.Mutex
+	cond   *sync.Cond
+	queue  *types.Deque
+	closed bool
+	done   chan struct{}
+}
+
+// Topic returns the subscribed topic.
+func (s *Subscription) Topic() string {
+	return s.topic
+}
+
+// Dropped returns the number of the dropped events due to the overflow.
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Get()
+}
+
+// Pending returns the number of the events in the queue.
+func (s *Subscription) Pending() int {
+	s.lock.Lock()
+	n := s.queue.Len()
+	s.lock.Unlock()
+	return n
+}
+
+// Unsubscribe cancels the subscription without waiting, so it may be called
+// in the event handler. The queued events are still handled, and Wait may be
+// used to wait for them.
+func (s *Subscription) Unsubscribe() {
+	s.bus.unsubscribe(s)
+	s.close()
+}
+
+// Wait waits until the subscription is cancelled and all the queued events
+// have been handled. It must not be called in the event handler.
+func (s *Subscription) Wait() {
+	<-s.done
+}
+
+func (s *Subscription) close() {
+	s.lock.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	s.lock.Unlock()
+}
+
+func (s *Subscription) push(e Event) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for !s.closed && s.queue.Len() >= s.size {
+		switch s.policy {
+		case DropOldest:
+			s.queue.PopFront()
+			s.dropped.Add(1)
+		case DropNewest:
+			s.dropped.Add(1)
+			return
+		default:
+			s.cond.Wait()
+		}
+	}
+
+	if !s.closed {
+		s.queue.PushBack(e)
+		s.cond.Broadcast()
+	}
+}
+
+func (s *Subscription) loop() {
+	defer close(s.done)
+	for {
+		s.lock.Lock()
+		for !s.closed && s.queue.Len() == 0 {
+			s.cond.Wait()
+		}
+
+		v, ok := s.queue.PopFront()
+		s.cond.Broadcast()
+		s.lock.Unlock()
+
+		if !ok {
+			return
+		}
+		s.handle(v.(Event))
+	}
+}
+
+func (s *Subscription) handle(e Event) {
+	defer func() { recover() }()
+	s.handler(e)
+}
+
+// Typed returns an event handler which calls f with the event data,
+// and ignores the event whose data is not assignable to the argument of f.
+//
+// f must be a function with only one argument, such as func(ConfigChanged),
+// or panic.
+func Typed(f interface{}) func(Event) {
+	fv := reflect.ValueOf(f)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func || ft.NumIn() != 1 {
+		panic(fmt.Errorf("the typed handler must be a function with an argument, but got '%T'", f))
+	}
+
+	in := ft.In(0)
+	return func(e Event) {
+		if e.Data == nil {
+			return
+		} else if v := reflect.ValueOf(e.Data); v.Type().AssignableTo(in) {
+			fv.Call([]reflect.Value{v})
+		}
+	}
+}
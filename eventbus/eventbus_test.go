@@ -0,0 +1,177 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventbus
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBus(t *testing.T) {
+	bus := New()
+
+	var lock sync.Mutex
+	var events, all []Event
+	s1, _ := bus.Subscribe("a", 10, Block, func(e Event) {
+		lock.Lock()
+		events = append(events, e)
+		lock.Unlock()
+	})
+	bus.Subscribe(AllTopics, 10, Block, func(e Event) {
+		lock.Lock()
+		all = append(all, e)
+		lock.Unlock()
+	})
+
+	for i := 0; i < 5; i++ {
+		bus.Publish("a", i)
+	}
+	bus.Publish("b", "b")
+
+	s1.Unsubscribe()
+	s1.Wait()
+	bus.Publish("a", 5)
+	bus.Close()
+	bus.Wait()
+
+	if len(events) != 5 || events[4].Data.(int) != 4 {
+		t.Error(events)
+	}
+	if len(all) != 7 || all[5].Topic != "b" {
+		t.Error(all)
+	}
+
+	if _, err := bus.Subscribe("a", 1, Block, func(Event) {}); err != ErrBusClosed {
+		t.Error(err)
+	}
+}
+
+func testBusOverflow(t *testing.T, policy OverflowPolicy, expect []int) {
+	bus := New()
+	defer bus.Close()
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	var results []int
+	s, _ := bus.Subscribe("a", 2, policy, func(e Event) {
+		if e.Data.(int) == 0 {
+			close(started)
+			<-block
+		}
+		results = append(results, e.Data.(int))
+	})
+
+	bus.Publish("a", 0)
+	<-started
+	for i := 1; i <= 4; i++ {
+		bus.Publish("a", i)
+	}
+	if s.Dropped() != 2 || s.Pending() != 2 {
+		t.Errorf("dropped=%d, pending=%d", s.Dropped(), s.Pending())
+	}
+
+	close(block)
+	s.Unsubscribe()
+	s.Wait()
+	if len(results) != len(expect) {
+		t.Fatalf("expect %v, got %v", expect, results)
+	}
+	for i := range expect {
+		if results[i] != expect[i] {
+			t.Fatalf("expect %v, got %v", expect, results)
+		}
+	}
+}
+
+func TestBusOverflow(t *testing.T) {
+	testBusOverflow(t, DropOldest, []int{0, 3, 4})
+	testBusOverflow(t, DropNewest, []int{0, 1, 2})
+}
+
+func TestBusBlock(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	var count int
+	bus.Subscribe("a", 1, Block, func(e Event) {
+		time.Sleep(time.Millisecond)
+		count++
+	})
+	for i := 0; i < 10; i++ {
+		bus.Publish("a", i)
+	}
+	bus.Close()
+	bus.Wait()
+
+	if count != 10 {
+		t.Errorf("expect 10 events, got %d", count)
+	}
+}
+
+func TestTyped(t *testing.T) {
+	bus := New()
+
+	var sum int
+	bus.Subscribe("a", 10, Block, Typed(func(n int) { sum += n }))
+	bus.Publish("a", 1)
+	bus.Publish("a", "ignored")
+	bus.Publish("a", nil)
+	bus.Publish("a", 2)
+	bus.Close()
+	bus.Wait()
+
+	if sum != 3 {
+		t.Error(sum)
+	}
+}
+
+func TestUnsubscribeInHandler(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	var s *Subscription
+	var count int
+	ready := make(chan struct{})
+	s, _ = bus.Subscribe("a", 10, Block, func(e Event) {
+		<-ready
+		count++
+		s.Unsubscribe()
+	})
+
+	bus.Publish("a", 1)
+	bus.Publish("a", 2)
+	close(ready)
+	waitOrFail(t, s.Wait)
+	if count != 2 {
+		t.Errorf("expect 2 queued events, got %d", count)
+	}
+
+	closed := make(chan struct{})
+	bus.Subscribe("b", 1, Block, func(e Event) { bus.Close(); close(closed) })
+	bus.Publish("b", 1)
+	waitOrFail(t, func() { <-closed })
+	waitOrFail(t, bus.Wait)
+}
+
+func waitOrFail(t *testing.T, wait func()) {
+	done := make(chan struct{})
+	go func() { wait(); close(done) }()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+}
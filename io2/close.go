@@ -0,0 +1,87 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package io2
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/xgfone/go-tools/errors2"
+)
+
+// CloseAll closes all the closers in turn, even though some of them fail,
+// and returns a *errors2.Multi containing all the errors if failing.
+//
+// The nil closers are ignored.
+func CloseAll(closers ...io.Closer) error {
+	var errs *errors2.Multi
+	for _, c := range closers {
+		if c != nil {
+			if err := c.Close(); err != nil {
+				errs = errors2.Append(errs, err)
+			}
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// FuncCloser is an adapter to allow the use of the ordinary function
+// as io.Closer.
+type FuncCloser func() error
+
+// Close implements the interface io.Closer.
+func (f FuncCloser) Close() error {
+	return f()
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// NopWriteCloser returns a WriteCloser with a no-op Close method wrapping w.
+func NopWriteCloser(w io.Writer) io.WriteCloser {
+	return nopWriteCloser{w}
+}
+
+type teeReadCloser struct {
+	io.Reader
+	rc io.ReadCloser
+	w  io.Writer
+}
+
+func (t teeReadCloser) Close() error {
+	if c, ok := t.w.(io.Closer); ok {
+		return CloseAll(t.rc, c)
+	}
+	return t.rc.Close()
+}
+
+// TeeReadCloser is the same as io.TeeReader, but returns a ReadCloser,
+// the Close method of which closes both rc and w if w is an io.Closer.
+func TeeReadCloser(rc io.ReadCloser, w io.Writer) io.ReadCloser {
+	return teeReadCloser{Reader: io.TeeReader(rc, w), rc: rc, w: w}
+}
+
+// DrainAndClose discards the remaining data of rc before closing it,
+// which is important for reusing the connection, such as the HTTP keep-alive.
+func DrainAndClose(rc io.ReadCloser) error {
+	_, err := io.Copy(ioutil.Discard, rc)
+	if e := rc.Close(); err == nil {
+		err = e
+	}
+	return err
+}
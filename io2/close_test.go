@@ -0,0 +1,98 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package io2
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/xgfone/go-tools/errors2"
+)
+
+func TestCloseAll(t *testing.T) {
+	var closed int
+	ok := FuncCloser(func() error { closed++; return nil })
+	fail := FuncCloser(func() error { closed++; return errors.New("fail") })
+
+	if err := CloseAll(ok, nil, ok); err != nil || closed != 2 {
+		t.Errorf("closed=%d, err=%v", closed, err)
+	}
+
+	closed = 0
+	err := CloseAll(fail, ok, fail)
+	if closed != 3 {
+		t.Errorf("expect to close 3 closers, but got %d", closed)
+	}
+	if m, ok := err.(*errors2.Multi); !ok || m.Len() != 2 || m.Errors[0].Error() != "fail" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNopWriteCloser(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	var w io.WriteCloser = NopWriteCloser(buf)
+	w.Write([]byte("abc"))
+	if err := w.Close(); err != nil || buf.String() != "abc" {
+		t.Errorf("buf=%s, err=%v", buf.String(), err)
+	}
+}
+
+type testReadCloser struct {
+	io.Reader
+	closed bool
+}
+
+func (r *testReadCloser) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestTeeReadCloser(t *testing.T) {
+	rc := &testReadCloser{Reader: bytes.NewBufferString("abc")}
+	buf := bytes.NewBuffer(nil)
+	wc := &testWriteCloser{Writer: buf}
+
+	tee := TeeReadCloser(rc, wc)
+	if data, err := ioutil.ReadAll(tee); err != nil || string(data) != "abc" {
+		t.Errorf("data=%s, err=%v", string(data), err)
+	} else if buf.String() != "abc" {
+		t.Errorf("expect 'abc', but got '%s'", buf.String())
+	}
+
+	if err := tee.Close(); err != nil || !rc.closed || !wc.closed {
+		t.Errorf("err=%v, rc.closed=%v, wc.closed=%v", err, rc.closed, wc.closed)
+	}
+}
+
+type testWriteCloser struct {
+	io.Writer
+	closed bool
+}
+
+func (w *testWriteCloser) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestDrainAndClose(t *testing.T) {
+	body := bytes.NewBufferString("abc")
+	rc := &testReadCloser{Reader: body}
+	if err := DrainAndClose(rc); err != nil || !rc.closed || body.Len() != 0 {
+		t.Errorf("err=%v, closed=%v, left=%d", err, rc.closed, body.Len())
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package io2
+
+import (
+	"io"
+	"net"
+	"os"
+
+	"github.com/xgfone/go-tools/pools"
+)
+
+// CopyFast is the same as io.Copy, but prefers to the zero-copy path.
+//
+// If dst is a *net.TCPConn or *os.File, it will use
+// the ReadFrom method of dst, which will use sendfile or splice
+// when src is a *os.File or *net.TCPConn on the platform supporting them.
+//
+// Or it falls back to the buffered copy with the buffer from a pool,
+// rather than allocating a new buffer for every copy like io.Copy.
+func CopyFast(dst io.Writer, src io.Reader) (written int64, err error) {
+	switch w := dst.(type) {
+	case *net.TCPConn:
+		return w.ReadFrom(src)
+	case *os.File:
+		return w.ReadFrom(src)
+	}
+
+	if wt, ok := src.(io.WriterTo); ok {
+		return wt.WriteTo(dst)
+	} else if rf, ok := dst.(io.ReaderFrom); ok {
+		return rf.ReadFrom(src)
+	}
+
+	buf := pools.GetBytes(32768) // 32KB
+	written, err = io.CopyBuffer(dst, src, buf)
+	pools.PutBytes(buf)
+	return
+}
@@ -0,0 +1,94 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package io2
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+)
+
+func TestCopyFast(t *testing.T) {
+	src := bytes.NewBufferString("1234567890")
+	dst := bytes.NewBuffer(nil)
+	if n, err := CopyFast(dst, src); err != nil || n != 10 || dst.String() != "1234567890" {
+		t.Errorf("n=%d, err=%v, dst=%s", n, err, dst.String())
+	}
+
+	// Hide the WriterTo and ReaderFrom interfaces to use the buffered copy.
+	src = bytes.NewBufferString("1234567890")
+	dst = bytes.NewBuffer(nil)
+	if n, err := CopyFast(struct{ io.Writer }{dst}, struct{ io.Reader }{src}); err != nil ||
+		n != 10 || dst.String() != "1234567890" {
+		t.Errorf("n=%d, err=%v, dst=%s", n, err, dst.String())
+	}
+}
+
+func benchmarkCopyFileToTCP(b *testing.B, copy func(io.Writer, io.Reader) (int64, error)) {
+	const size = 4 * 1024 * 1024
+
+	f, err := ioutil.TempFile("", "io2_copy_bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err = f.Write(make([]byte, size)); err != nil {
+		b.Fatal(err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			io.Copy(ioutil.Discard, conn)
+			conn.Close()
+		}
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer conn.Close()
+
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err = f.Seek(0, io.SeekStart); err != nil {
+			b.Fatal(err)
+		}
+		if _, err = copy(conn, f); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCopyFast(b *testing.B) {
+	benchmarkCopyFileToTCP(b, CopyFast)
+}
+
+func BenchmarkCopyUserspace(b *testing.B) {
+	benchmarkCopyFileToTCP(b, func(w io.Writer, r io.Reader) (int64, error) {
+		return io.Copy(struct{ io.Writer }{w}, struct{ io.Reader }{r})
+	})
+}
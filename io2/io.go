@@ -18,9 +18,15 @@ package io2
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"io"
+
+	"github.com/xgfone/go-tools/pools"
 )
 
+// ErrLineTooLong is returned when the line is longer than the limit.
+var ErrLineTooLong = errors.New("the line is too long")
+
 // ReadLine reads the content in the buffer by line.
 func ReadLine(r *bufio.Reader) (lines [][]byte, err error) {
 	var line []byte
@@ -34,6 +40,36 @@ func ReadLine(r *bufio.Reader) (lines [][]byte, err error) {
 	return lines, err
 }
 
+// ReadLineLimit is the same as ReadLine, but returns the whole line, which
+// does not include the end-of-line bytes, and ErrLineTooLong if the length
+// of the line exceeds max, which protects the servers that parse
+// the line-based protocols from the memory exhaustion.
+//
+// The returned line is always a copy, which is not overwritten by the next
+// read. When returning ErrLineTooLong, the rest of the too long line has been
+// discarded from r, so the next read starts from the next line.
+//
+// If max is equal to or less than 0, the length of the line is not limited.
+func ReadLineLimit(r *bufio.Reader, max int) (line []byte, err error) {
+	var buf []byte
+	isPrefix := true
+	for isPrefix && err == nil {
+		buf, isPrefix, err = r.ReadLine()
+		if max > 0 && len(line)+len(buf) > max {
+			for isPrefix && err == nil { // Discard the rest of the line.
+				_, isPrefix, err = r.ReadLine()
+			}
+			return nil, ErrLineTooLong
+		}
+		line = append(line, buf...)
+	}
+
+	if line == nil && err == nil {
+		line = []byte{}
+	}
+	return line, err
+}
+
 // ReadN reads the data from io.Reader until n bytes or no incoming data
 // if n is equal to or less than 0.
 func ReadN(r io.Reader, n int64) (v []byte, err error) {
@@ -67,3 +103,36 @@ func ReadNWriter(w io.Writer, r io.Reader, n int64) (err error) {
 	}
 	return nil
 }
+
+// ReadChunks reads the data from r by the chunk whose size is size,
+// and calls fn with each chunk until r returns io.EOF.
+//
+// The buffer passed to fn is reused for each chunk, so fn must not retain it.
+// The last chunk may be shorter than size. If fn returns an error, stop
+// reading and return it.
+//
+// If size is equal to or less than 0, it will be 32KB by default.
+func ReadChunks(r io.Reader, size int, fn func(chunk []byte) error) error {
+	if size < 1 {
+		size = 32768 // 32KB
+	}
+
+	buf := pools.GetBytes(size)
+	defer pools.PutBytes(buf)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if e := fn(buf[:n]); e != nil {
+				return e
+			}
+		}
+
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			return nil
+		default:
+			return err
+		}
+	}
+}
@@ -15,7 +15,9 @@
 package io2
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"testing"
@@ -73,3 +75,63 @@ func TestReadNWriter(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestReadChunks(t *testing.T) {
+	var chunks []string
+	err := ReadChunks(bytes.NewBufferString("1234567890"), 4, func(b []byte) error {
+		chunks = append(chunks, string(b))
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	} else if len(chunks) != 3 || chunks[0] != "1234" || chunks[1] != "5678" || chunks[2] != "90" {
+		t.Errorf("chunks: %v", chunks)
+	}
+
+	errStop := errors.New("stop")
+	err = ReadChunks(bytes.NewBufferString("1234567890"), 4, func(b []byte) error {
+		return errStop
+	})
+	if err != errStop {
+		t.Errorf("expect the error '%v', but got '%v'", errStop, err)
+	}
+
+	if err = ReadChunks(bytes.NewBuffer(nil), 4, func(b []byte) error {
+		t.Error("unexpected chunk")
+		return nil
+	}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReadLineLimit(t *testing.T) {
+	r := bufio.NewReaderSize(bytes.NewBufferString("abc\r\n0123456789012345678901\nend"), 16)
+	if line, err := ReadLineLimit(r, 32); err != nil || string(line) != "abc" {
+		t.Errorf("line=%s, err=%v", string(line), err)
+	}
+	if line, err := ReadLineLimit(r, 32); err != nil || string(line) != "0123456789012345678901" {
+		t.Errorf("line=%s, err=%v", string(line), err)
+	}
+	if line, err := ReadLineLimit(r, 32); err != nil || string(line) != "end" {
+		t.Errorf("line=%s, err=%v", string(line), err)
+	}
+	if _, err := ReadLineLimit(r, 32); err != io.EOF {
+		t.Errorf("expect io.EOF, but got %v", err)
+	}
+
+	r = bufio.NewReaderSize(bytes.NewBufferString("0123456789012345678901234567890123\nnext\n"), 16)
+	if _, err := ReadLineLimit(r, 20); err != ErrLineTooLong {
+		t.Errorf("expect ErrLineTooLong, but got %v", err)
+	}
+	if line, err := ReadLineLimit(r, 20); err != nil || string(line) != "next" {
+		t.Errorf("line=%s, err=%v", string(line), err)
+	}
+
+	// The short line must not be overwritten by the next read.
+	r = bufio.NewReaderSize(bytes.NewBufferString("abc\ndef\n"), 16)
+	line1, _ := ReadLineLimit(r, 0)
+	line2, _ := ReadLineLimit(r, 0)
+	if string(line1) != "abc" || string(line2) != "def" {
+		t.Errorf("line1=%s, line2=%s", string(line1), string(line2))
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package io2
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrLimitExceeded is returned when the written data exceeds the limit.
+var ErrLimitExceeded = errors.New("the limit is exceeded")
+
+// LimitWriter returns a Writer that writes to w but stops with
+// ErrLimitExceeded after n bytes.
+//
+// If the data to be written exceeds the limit, it only writes the data
+// within the limit, then returns ErrLimitExceeded.
+func LimitWriter(w io.Writer, n int64) io.Writer {
+	return &limitedWriter{w: w, n: n}
+}
+
+// DiscardAfter is the same as LimitWriter, but discards the data silently
+// after n bytes instead of returning ErrLimitExceeded, which is used
+// to capture the output of the handlers, such as the logs.
+func DiscardAfter(w io.Writer, n int64) io.Writer {
+	return &limitedWriter{w: w, n: n, discard: true}
+}
+
+type limitedWriter struct {
+	w       io.Writer
+	n       int64
+	discard bool
+}
+
+func (l *limitedWriter) Write(p []byte) (n int, err error) {
+	if l.n <= 0 {
+		if l.discard {
+			return len(p), nil
+		}
+		return 0, ErrLimitExceeded
+	}
+
+	total := len(p)
+	if int64(total) > l.n {
+		p = p[:l.n]
+	}
+
+	n, err = l.w.Write(p)
+	l.n -= int64(n)
+	if err == nil && n < total {
+		if l.discard {
+			return total, nil
+		}
+		err = ErrLimitExceeded
+	}
+	return
+}
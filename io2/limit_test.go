@@ -0,0 +1,50 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package io2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLimitWriter(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	w := LimitWriter(buf, 5)
+	if n, err := w.Write([]byte("123")); n != 3 || err != nil {
+		t.Errorf("n=%d, err=%v", n, err)
+	}
+	if n, err := w.Write([]byte("456")); n != 2 || err != ErrLimitExceeded {
+		t.Errorf("n=%d, err=%v", n, err)
+	}
+	if n, err := w.Write([]byte("7")); n != 0 || err != ErrLimitExceeded {
+		t.Errorf("n=%d, err=%v", n, err)
+	}
+	if buf.String() != "12345" {
+		t.Errorf("expect '12345', but got '%s'", buf.String())
+	}
+}
+
+func TestDiscardAfter(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	w := DiscardAfter(buf, 5)
+	for _, s := range []string{"123", "456", "7"} {
+		if n, err := w.Write([]byte(s)); n != len(s) || err != nil {
+			t.Errorf("n=%d, err=%v", n, err)
+		}
+	}
+	if buf.String() != "12345" {
+		t.Errorf("expect '12345', but got '%s'", buf.String())
+	}
+}
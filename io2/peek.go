@@ -0,0 +1,71 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package io2
+
+import "io"
+
+// PeekReader is a reader that supports to peek the next bytes
+// without consuming them, even if the underlying reader is not a bufio.Reader,
+// which is used to sniff the protocol, such as TLS vs plaintext.
+type PeekReader struct {
+	r   io.Reader
+	buf []byte
+}
+
+// NewPeekReader returns a new PeekReader.
+func NewPeekReader(r io.Reader) *PeekReader {
+	return &PeekReader{r: r}
+}
+
+// Buffered returns the number of the bytes that have been peeked
+// but not been read.
+func (p *PeekReader) Buffered() int {
+	return len(p.buf)
+}
+
+// Peek returns the next n bytes without advancing the reader.
+//
+// If Peek returns fewer than n bytes, it also returns an error explaining
+// why the read is short, such as io.EOF.
+//
+// The returned bytes are only valid until the next read.
+func (p *PeekReader) Peek(n int) ([]byte, error) {
+	for len(p.buf) < n {
+		if cap(p.buf) < n {
+			buf := make([]byte, len(p.buf), n)
+			copy(buf, p.buf)
+			p.buf = buf
+		}
+
+		m, err := p.r.Read(p.buf[len(p.buf):n])
+		p.buf = p.buf[:len(p.buf)+m]
+		if err != nil {
+			return p.buf, err
+		}
+	}
+	return p.buf[:n], nil
+}
+
+// Read implements the interface io.Reader, which reads the peeked bytes
+// firstly.
+func (p *PeekReader) Read(b []byte) (n int, err error) {
+	if len(p.buf) == 0 {
+		return p.r.Read(b)
+	}
+
+	n = copy(b, p.buf)
+	p.buf = p.buf[n:]
+	return
+}
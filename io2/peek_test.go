@@ -0,0 +1,44 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package io2
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestPeekReader(t *testing.T) {
+	r := NewPeekReader(iotest.OneByteReader(strings.NewReader("GET / HTTP/1.1")))
+	if b, err := r.Peek(3); err != nil || string(b) != "GET" {
+		t.Errorf("peek: %s, %v", string(b), err)
+	}
+	if b, err := r.Peek(5); err != nil || string(b) != "GET /" {
+		t.Errorf("peek: %s, %v", string(b), err)
+	}
+	if r.Buffered() != 5 {
+		t.Errorf("expect 5 buffered bytes, but got %d", r.Buffered())
+	}
+
+	if b, err := ioutil.ReadAll(r); err != nil || string(b) != "GET / HTTP/1.1" {
+		t.Errorf("read: %s, %v", string(b), err)
+	}
+
+	if b, err := r.Peek(1); err != io.EOF || len(b) != 0 {
+		t.Errorf("peek: %s, %v", string(b), err)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package io2
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// RateLimiter is used to limit the rate of the bytes.
+type RateLimiter interface {
+	// WaitN blocks until n bytes are allowed.
+	WaitN(n int)
+}
+
+// NewBytesLimiter returns a new token-bucket RateLimiter, which allows
+// rate bytes per second with the burst size.
+//
+// WaitN may be called with n larger than burst, which will wait until
+// the debt is paid off.
+func NewBytesLimiter(rate, burst int) RateLimiter {
+	if burst < 1 {
+		burst = rate
+	}
+	return &bytesLimiter{
+		rate:   float64(rate),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+type bytesLimiter struct {
+	lock   sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func (l *bytesLimiter) WaitN(n int) {
+	l.lock.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens -= float64(n)
+
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.lock.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// RateLimitReader returns a new reader, which waits for the limiter
+// after reading the data from r.
+func RateLimitReader(r io.Reader, limiter RateLimiter) io.Reader {
+	return rateLimitReader{r: r, l: limiter}
+}
+
+type rateLimitReader struct {
+	r io.Reader
+	l RateLimiter
+}
+
+func (r rateLimitReader) Read(p []byte) (n int, err error) {
+	if n, err = r.r.Read(p); n > 0 {
+		r.l.WaitN(n)
+	}
+	return
+}
+
+// RateLimitWriter returns a new writer, which waits for the limiter
+// before writing the data into w.
+func RateLimitWriter(w io.Writer, limiter RateLimiter) io.Writer {
+	return rateLimitWriter{w: w, l: limiter}
+}
+
+type rateLimitWriter struct {
+	w io.Writer
+	l RateLimiter
+}
+
+func (w rateLimitWriter) Write(p []byte) (n int, err error) {
+	if len(p) > 0 {
+		w.l.WaitN(len(p))
+	}
+	return w.w.Write(p)
+}
@@ -0,0 +1,51 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package io2
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestRateLimitReader(t *testing.T) {
+	limiter := NewBytesLimiter(1000, 100)
+	r := RateLimitReader(bytes.NewReader(make([]byte, 300)), limiter)
+
+	start := time.Now()
+	if data, err := ioutil.ReadAll(r); err != nil || len(data) != 300 {
+		t.Errorf("len=%d, err=%v", len(data), err)
+	}
+
+	// The burst is 100, so the left 200 bytes need about 200ms.
+	if cost := time.Since(start); cost < time.Millisecond*150 {
+		t.Errorf("read too fast: %s", cost)
+	}
+}
+
+func TestRateLimitWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := RateLimitWriter(&buf, NewBytesLimiter(1000, 100))
+
+	start := time.Now()
+	w.Write(make([]byte, 100))
+	w.Write(make([]byte, 100))
+	if cost := time.Since(start); cost < time.Millisecond*80 {
+		t.Errorf("write too fast: %s", cost)
+	} else if buf.Len() != 200 {
+		t.Errorf("expect 200 bytes, but got %d", buf.Len())
+	}
+}
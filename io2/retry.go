@@ -0,0 +1,111 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package io2
+
+import (
+	"io"
+	"time"
+)
+
+// RetryReader is a reader that reopens the source at the last good offset
+// and continues to read when it fails to read the data from the source,
+// such as downloading a file over the flaky links.
+type RetryReader struct {
+	// Open opens the source and reads the data from offset.
+	Open func(offset int64) (io.ReadCloser, error)
+
+	// MaxRetries is the maximum number of the continuous retries.
+	// The counter is reset once reading some data successfully.
+	MaxRetries int
+
+	// Delay is the interval duration between two retries. The default is 0.
+	Delay time.Duration
+
+	// IsTransient reports whether the error is transient so that it should
+	// reopen the source and retry. If nil, all the errors except io.EOF
+	// are considered transient.
+	IsTransient func(error) bool
+
+	offset  int64
+	retries int
+	reader  io.ReadCloser
+}
+
+// NewRetryReader returns a new RetryReader.
+func NewRetryReader(open func(offset int64) (io.ReadCloser, error), maxRetries int) *RetryReader {
+	return &RetryReader{Open: open, MaxRetries: maxRetries}
+}
+
+// Offset returns the offset of the data having been read successfully.
+func (r *RetryReader) Offset() int64 {
+	return r.offset
+}
+
+func (r *RetryReader) retry(err error) bool {
+	if r.retries >= r.MaxRetries {
+		return false
+	} else if r.IsTransient != nil && !r.IsTransient(err) {
+		return false
+	}
+
+	if r.reader != nil {
+		r.reader.Close()
+		r.reader = nil
+	}
+
+	r.retries++
+	if r.Delay > 0 {
+		time.Sleep(r.Delay)
+	}
+	return true
+}
+
+// Read implements the interface io.Reader.
+func (r *RetryReader) Read(p []byte) (n int, err error) {
+	for {
+		if r.reader == nil {
+			if r.reader, err = r.Open(r.offset); err != nil {
+				r.reader = nil
+				if r.retry(err) {
+					continue
+				}
+				return 0, err
+			}
+		}
+
+		n, err = r.reader.Read(p)
+		if n > 0 {
+			r.offset += int64(n)
+			r.retries = 0
+		}
+
+		if err == nil || err == io.EOF {
+			return
+		} else if !r.retry(err) {
+			return
+		} else if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// Close implements the interface io.Closer.
+func (r *RetryReader) Close() (err error) {
+	if r.reader != nil {
+		err = r.reader.Close()
+		r.reader = nil
+	}
+	return
+}
@@ -0,0 +1,70 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package io2
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+type flakyReader struct {
+	data string
+	left int
+}
+
+func (r *flakyReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	} else if r.left == 0 {
+		return 0, errors.New("broken link")
+	}
+
+	if len(p) > r.left {
+		p = p[:r.left]
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	r.left -= n
+	return n, nil
+}
+
+func TestRetryReader(t *testing.T) {
+	const data = "1234567890"
+	var opens int
+	r := NewRetryReader(func(offset int64) (io.ReadCloser, error) {
+		opens++
+		return ioutil.NopCloser(&flakyReader{data: data[offset:], left: 3}), nil
+	}, 1)
+
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Error(err)
+	} else if string(buf) != data {
+		t.Errorf("expect '%s', but got '%s'", data, string(buf))
+	} else if opens != 4 {
+		t.Errorf("expect to open 4 times, but got %d", opens)
+	}
+	r.Close()
+
+	r = NewRetryReader(func(offset int64) (io.ReadCloser, error) {
+		return nil, errors.New("unreachable")
+	}, 2)
+	if _, err = ioutil.ReadAll(r); err == nil || !strings.Contains(err.Error(), "unreachable") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
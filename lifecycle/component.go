@@ -0,0 +1,219 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/xgfone/go-tools/errors2"
+)
+
+// ErrStarted is returned when starting the components twice.
+var ErrStarted = errors.New("The components have been started")
+
+// Component is a part of the program with the start and stop functions,
+// such as the server, the database connection pool, etc.
+//
+// Either of Start and Stop may be nil.
+type Component struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+// Components manages the startup and the shutdown of the components.
+//
+// The components are started in the order that they are registered,
+// and stopped in the reverse order.
+type Components struct {
+	lock       sync.Mutex
+	components []Component
+	started    int
+	running    bool
+
+	done    chan struct{}
+	errOnce sync.Once
+	err     error
+}
+
+// NewComponents returns a new Components.
+func NewComponents() *Components {
+	return &Components{done: make(chan struct{})}
+}
+
+// Register registers the component with the start and stop functions.
+func (c *Components) Register(name string, start, stop func(ctx context.Context) error) *Components {
+	return c.RegisterComponent(Component{Name: name, Start: start, Stop: stop})
+}
+
+// RegisterComponent registers the components.
+func (c *Components) RegisterComponent(components ...Component) *Components {
+	c.lock.Lock()
+	c.components = append(c.components, components...)
+	c.lock.Unlock()
+	return c
+}
+
+// RegisterServe registers a component which serves by the function serve
+// that blocks until it fails or is stopped by the function stop,
+// such as a server.
+//
+// When starting the components, serve is called in a new goroutine.
+// If it returns an error before stopping, the components fail, which can be
+// checked by Done and Err. When stopping, stop is called and waits for serve
+// to return. If stop is nil, it does not wait, such as
+//
+//	c.RegisterServe("tcp", func() error {
+//		return net2.TCPServerForever(addr, handler)
+//	}, nil)
+func (c *Components) RegisterServe(name string, serve func() error, stop func(ctx context.Context) error) *Components {
+	var exit chan struct{}
+	start := func(context.Context) error {
+		exit = make(chan struct{})
+		go func() {
+			defer close(exit)
+			if err := serve(); err != nil {
+				c.fail(fmt.Errorf("component '%s' failed: %s", name, err))
+			}
+		}()
+		return nil
+	}
+
+	var _stop func(context.Context) error
+	if stop != nil {
+		_stop = func(ctx context.Context) error {
+			if err := stop(ctx); err != nil {
+				return err
+			}
+
+			select {
+			case <-exit:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	return c.Register(name, start, _stop)
+}
+
+func (c *Components) fail(err error) {
+	c.errOnce.Do(func() {
+		c.lock.Lock()
+		c.err = err
+		c.lock.Unlock()
+		close(c.done)
+	})
+}
+
+// Done returns a channel that's closed when a serving component fails.
+func (c *Components) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the error of the failed serving component, or nil.
+func (c *Components) Err() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.err
+}
+
+// Start starts all the components in turn.
+//
+// If one fails, it rolls back by stopping the components having been
+// started in the reverse order with ctx, and returns the error.
+func (c *Components) Start(ctx context.Context) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.running {
+		return ErrStarted
+	}
+
+	c.running = true
+	for ; c.started < len(c.components); c.started++ {
+		comp := c.components[c.started]
+		if comp.Start == nil {
+			continue
+		}
+
+		if err := comp.Start(ctx); err != nil {
+			err = fmt.Errorf("failed to start component '%s': %s", comp.Name, err)
+			if errs := c.stop(ctx); len(errs) > 0 {
+				return &errors2.Multi{Errors: append([]error{err}, errs...)}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop stops the started components in the reverse order, which is cancelled
+// when ctx is done, such as the global deadline by context.WithTimeout.
+//
+// If one fails to stop, it continues to stop the next, and returns all
+// the errors as *errors2.Multi. If ctx is done, the components that have not
+// stopped are reported.
+func (c *Components) Stop(ctx context.Context) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if errs := c.stop(ctx); len(errs) > 0 {
+		return &errors2.Multi{Errors: errs}
+	}
+	return nil
+}
+
+func (c *Components) stop(ctx context.Context) (errs []error) {
+	for c.started > 0 {
+		c.started--
+		comp := c.components[c.started]
+		if comp.Stop == nil {
+			continue
+		}
+
+		if err := stopComponent(ctx, comp); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop component '%s': %s", comp.Name, err))
+		}
+	}
+
+	c.running = false
+	return
+}
+
+func stopComponent(ctx context.Context, comp Component) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				result <- fmt.Errorf("panic: %v", v)
+			}
+		}()
+		result <- comp.Stop(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
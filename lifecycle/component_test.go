@@ -0,0 +1,162 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-tools/errors2"
+)
+
+func TestComponents(t *testing.T) {
+	var steps []string
+	newFunc := func(step string, err error) func(context.Context) error {
+		return func(context.Context) error {
+			steps = append(steps, step)
+			return err
+		}
+	}
+
+	c := NewComponents().
+		Register("c1", newFunc("start1", nil), newFunc("stop1", nil)).
+		Register("c2", newFunc("start2", nil), newFunc("stop2", errors.New("error"))).
+		Register("c3", newFunc("start3", nil), nil)
+
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	} else if c.Start(ctx) != ErrStarted {
+		t.Error("expect ErrStarted")
+	}
+
+	err := c.Stop(ctx)
+	if m, ok := err.(*errors2.Multi); !ok || m.Len() != 1 ||
+		m.Errors[0].Error() != "failed to stop component 'c2': error" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if s := strings.Join(steps, ","); s != "start1,start2,start3,stop2,stop1" {
+		t.Errorf("unexpected steps: %s", s)
+	}
+
+	if err := c.Stop(ctx); err != nil {
+		t.Errorf("unexpected error when stopping again: %v", err)
+	}
+}
+
+func TestComponentsRollback(t *testing.T) {
+	var steps []string
+	newFunc := func(step string, err error) func(context.Context) error {
+		return func(context.Context) error {
+			steps = append(steps, step)
+			return err
+		}
+	}
+
+	c := NewComponents().
+		Register("c1", newFunc("start1", nil), newFunc("stop1", nil)).
+		Register("c2", newFunc("start2", nil), newFunc("stop2", nil)).
+		Register("c3", newFunc("start3", errors.New("error")), newFunc("stop3", nil)).
+		Register("c4", newFunc("start4", nil), newFunc("stop4", nil))
+
+	err := c.Start(context.Background())
+	if err == nil || err.Error() != "failed to start component 'c3': error" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if s := strings.Join(steps, ","); s != "start1,start2,start3,stop2,stop1" {
+		t.Errorf("unexpected steps: %s", s)
+	}
+}
+
+func TestComponentsStopDeadline(t *testing.T) {
+	var stopped bool
+	c := NewComponents().
+		Register("c1", nil, func(context.Context) error { stopped = true; return nil }).
+		Register("c2", nil, func(context.Context) error { time.Sleep(time.Second); return nil })
+	c.Start(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+
+	start := time.Now()
+	err := c.Stop(ctx)
+	if cost := time.Since(start); cost > time.Millisecond*500 {
+		t.Errorf("the deadline is not respected: %s", cost)
+	}
+
+	if m, ok := err.(*errors2.Multi); !ok || m.Len() != 2 {
+		t.Errorf("unexpected error: %v", err)
+	} else if stopped {
+		t.Error("unexpected to stop c1 after the deadline")
+	}
+}
+
+func TestComponentsServe(t *testing.T) {
+	exit := make(chan struct{})
+	c := NewComponents().
+		RegisterServe("server", func() error { <-exit; return nil },
+			func(context.Context) error { close(exit); return nil }).
+		RegisterServe("forever", func() error {
+			time.Sleep(time.Millisecond * 10)
+			return errors.New("listen error")
+		}, nil)
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-c.Done():
+		if err := c.Err(); err == nil || err.Error() != "component 'forever' failed: listen error" {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("expect the component to fail")
+	}
+
+	if err := c.Stop(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func ExampleComponents() {
+	c := NewComponents()
+	for _, name := range []string{"db", "cache", "server"} {
+		name := name
+		c.Register(name,
+			func(context.Context) error { fmt.Println("start", name); return nil },
+			func(context.Context) error { fmt.Println("stop", name); return nil })
+	}
+
+	c.Start(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	c.Stop(ctx)
+
+	// Output:
+	// start db
+	// start cache
+	// start server
+	// stop server
+	// stop cache
+	// stop db
+}
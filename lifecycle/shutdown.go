@@ -0,0 +1,272 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-tools/errors2"
+)
+
+// Phase is the name of the shutdown phase.
+type Phase string
+
+// Predefine some shutdown phases, which are run in the order.
+const (
+	PhaseStopAccepting Phase = "stop-accepting"
+	PhaseDrain         Phase = "drain"
+	PhaseFlush         Phase = "flush"
+	PhaseClose         Phase = "close"
+)
+
+// DefaultPhaseTimeout is the default timeout of each shutdown phase.
+var DefaultPhaseTimeout = time.Second * 10
+
+// PhaseError is the error of the shutdown phase.
+type PhaseError struct {
+	Phase Phase
+
+	// TimedOut reports whether the phase timed out, and Pending is
+	// the names of the hooks that had not finished.
+	TimedOut bool
+	Pending  []string
+
+	// Errors is the errors returned by the hooks.
+	Errors []error
+}
+
+func (e *PhaseError) Error() string {
+	if e.TimedOut {
+		return fmt.Sprintf("shutdown phase '%s' timed out, pending: %s",
+			e.Phase, strings.Join(e.Pending, ", "))
+	}
+	return fmt.Sprintf("shutdown phase '%s' failed: %s", e.Phase, errors2.JoinFormatter("; ")(e.Errors))
+}
+
+type shutdownHook struct {
+	name string
+	hook func(ctx context.Context) error
+}
+
+type shutdownPhase struct {
+	phase   Phase
+	timeout time.Duration
+	hooks   []shutdownHook
+}
+
+// Shutdown is the coordinator to shut down the program gracefully
+// by the phases in sequence, each of which has its own timeout.
+//
+// The hooks in the same phase are run concurrently, and the next phase
+// starts after all of them finish or the phase times out.
+type Shutdown struct {
+	lock   sync.Mutex
+	phases []*shutdownPhase
+	once   sync.Once
+	err    error
+}
+
+// NewShutdown returns a new Shutdown with the predefined phases,
+// PhaseStopAccepting, PhaseDrain, PhaseFlush and PhaseClose,
+// whose timeouts are DefaultPhaseTimeout.
+func NewShutdown() *Shutdown {
+	s := &Shutdown{}
+	for _, phase := range []Phase{PhaseStopAccepting, PhaseDrain, PhaseFlush, PhaseClose} {
+		s.SetPhase(phase, DefaultPhaseTimeout)
+	}
+	return s
+}
+
+func (s *Shutdown) getPhase(phase Phase) *shutdownPhase {
+	for _, p := range s.phases {
+		if p.phase == phase {
+			return p
+		}
+	}
+	return nil
+}
+
+// SetPhase sets the timeout of the phase, which is appended
+// after the existed phases if not exist.
+func (s *Shutdown) SetPhase(phase Phase, timeout time.Duration) *Shutdown {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if p := s.getPhase(phase); p != nil {
+		p.timeout = timeout
+	} else {
+		s.phases = append(s.phases, &shutdownPhase{phase: phase, timeout: timeout})
+	}
+	return s
+}
+
+// Phases returns the names of all the phases in the order.
+func (s *Shutdown) Phases() []Phase {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	phases := make([]Phase, len(s.phases))
+	for i, p := range s.phases {
+		phases[i] = p.phase
+	}
+	return phases
+}
+
+// Register registers the hook named name into the phase, which should
+// return when ctx is done. If the phase does not exist, it is appended
+// with DefaultPhaseTimeout.
+//
+// For example,
+//
+//	s := NewShutdown()
+//	s.Register(PhaseStopAccepting, "server", Hook(server.Stop))
+//	s.Register(PhaseDrain, "server", WaitHook(server.WaitTimeout))
+//	s.Register(PhaseDrain, "workers", Hook(pool.Drain))
+//	s.Register(PhaseClose, "workers", Hook(pool.Stop))
+func (s *Shutdown) Register(phase Phase, name string, hook func(ctx context.Context) error) *Shutdown {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	p := s.getPhase(phase)
+	if p == nil {
+		p = &shutdownPhase{phase: phase, timeout: DefaultPhaseTimeout}
+		s.phases = append(s.phases, p)
+	}
+	p.hooks = append(p.hooks, shutdownHook{name: name, hook: hook})
+	return s
+}
+
+// Run runs all the phases in sequence, and returns the errors
+// as *errors2.Multi of *PhaseError, or nil if all succeed.
+//
+// If ctx is done, the rest phases are not run. Run only takes effect
+// for the first time, and returns the same result later.
+func (s *Shutdown) Run(ctx context.Context) error {
+	s.once.Do(func() {
+		s.lock.Lock()
+		phases := make([]shutdownPhase, len(s.phases))
+		for i, p := range s.phases {
+			phases[i] = *p
+		}
+		s.lock.Unlock()
+
+		var errs []error
+		for _, p := range phases {
+			if ctx.Err() != nil {
+				errs = append(errs, &PhaseError{Phase: p.phase, Errors: []error{ctx.Err()}})
+				break
+			}
+			if err := runPhase(ctx, p); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if len(errs) > 0 {
+			s.err = &errors2.Multi{Errors: errs}
+		}
+	})
+	return s.err
+}
+
+func runPhase(ctx context.Context, p shutdownPhase) *PhaseError {
+	if len(p.hooks) == 0 {
+		return nil
+	}
+
+	if p.timeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		index int
+		err   error
+	}
+
+	results := make(chan result, len(p.hooks))
+	for i, h := range p.hooks {
+		go func(i int, h shutdownHook) {
+			defer func() {
+				if v := recover(); v != nil {
+					results <- result{i, fmt.Errorf("%s: panic: %v", h.name, v)}
+				}
+			}()
+
+			err := h.hook(ctx)
+			if err != nil {
+				err = fmt.Errorf("%s: %s", h.name, err)
+			}
+			results <- result{i, err}
+		}(i, h)
+	}
+
+	var errs []error
+	finished := make([]bool, len(p.hooks))
+	for n := 0; n < len(p.hooks); n++ {
+		select {
+		case r := <-results:
+			finished[r.index] = true
+			if r.err != nil {
+				errs = append(errs, r.err)
+			}
+		case <-ctx.Done():
+			var pending []string
+			for i, done := range finished {
+				if !done {
+					pending = append(pending, p.hooks[i].name)
+				}
+			}
+			sort.Strings(pending)
+			return &PhaseError{Phase: p.phase, TimedOut: true, Pending: pending, Errors: errs}
+		}
+	}
+
+	if len(errs) > 0 {
+		return &PhaseError{Phase: p.phase, Errors: errs}
+	}
+	return nil
+}
+
+// Hook converts the function f without the context to the shutdown hook,
+// such as the Stop method of the server.
+func Hook(f func()) func(ctx context.Context) error {
+	return func(context.Context) error { f(); return nil }
+}
+
+// WaitHook converts the function wait with the timeout to the shutdown hook,
+// such as the WaitTimeout method of the server, which waits until
+// the deadline of ctx and returns context.DeadlineExceeded if wait
+// returns false.
+func WaitHook(wait func(timeout time.Duration) bool) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var timeout time.Duration
+		if deadline, ok := ctx.Deadline(); ok {
+			if timeout = time.Until(deadline); timeout <= 0 {
+				return context.DeadlineExceeded
+			}
+		}
+
+		if !wait(timeout) {
+			return context.DeadlineExceeded
+		}
+		return nil
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-tools/errors2"
+	"github.com/xgfone/go-tools/net2"
+)
+
+func TestShutdown(t *testing.T) {
+	var lock sync.Mutex
+	var steps []string
+	newHook := func(step string, err error) func(context.Context) error {
+		return func(context.Context) error {
+			lock.Lock()
+			steps = append(steps, step)
+			lock.Unlock()
+			return err
+		}
+	}
+
+	s := NewShutdown().
+		Register(PhaseClose, "db", newHook("close", nil)).
+		Register(PhaseFlush, "log", newHook("flush", errors.New("error"))).
+		Register(PhaseStopAccepting, "server", newHook("stop", nil)).
+		Register("custom", "other", newHook("custom", nil))
+
+	if phases := s.Phases(); len(phases) != 5 || phases[4] != "custom" {
+		t.Errorf("unexpected phases: %v", phases)
+	}
+
+	err := s.Run(context.Background())
+	if s := strings.Join(steps, ","); s != "stop,flush,close,custom" {
+		t.Errorf("unexpected steps: %s", s)
+	}
+
+	m, ok := err.(*errors2.Multi)
+	errs := m.Unwrap()
+	if !ok || len(errs) != 1 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pe := errs[0].(*PhaseError); pe.Phase != PhaseFlush || pe.TimedOut ||
+		pe.Error() != "shutdown phase 'flush' failed: log: error" {
+		t.Errorf("unexpected phase error: %v", pe)
+	}
+
+	if e := s.Run(context.Background()); e == nil || e.Error() != err.Error() {
+		t.Error("expect the same result when running again")
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	var closed bool
+	block := func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }
+	s := NewShutdown().
+		SetPhase(PhaseDrain, time.Millisecond*20).
+		Register(PhaseDrain, "fast", func(context.Context) error { return nil }).
+		Register(PhaseDrain, "slow2", block).
+		Register(PhaseDrain, "slow1", block).
+		Register(PhaseClose, "db", func(context.Context) error { closed = true; return nil })
+
+	start := time.Now()
+	err := s.Run(context.Background())
+	if cost := time.Since(start); cost > time.Millisecond*500 {
+		t.Errorf("the phase timeout is not respected: %s", cost)
+	}
+	if !closed {
+		t.Error("expect to run the next phase after timeout")
+	}
+
+	m, ok := err.(*errors2.Multi)
+	errs := m.Unwrap()
+	if !ok || len(errs) != 1 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pe := errs[0].(*PhaseError); pe.Phase != PhaseDrain || !pe.TimedOut ||
+		pe.Error() != "shutdown phase 'drain' timed out, pending: slow1, slow2" {
+		t.Errorf("unexpected phase error: %v", pe)
+	}
+}
+
+func TestShutdownServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := net2.NewServer(net2.HandlerFunc(func(ctx context.Context, conn net.Conn) {
+		<-ctx.Done()
+		time.Sleep(time.Millisecond * 20) // Drain the in-flight requests.
+	}))
+	go server.Serve(ln)
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	time.Sleep(time.Millisecond * 10)
+
+	s := NewShutdown().
+		Register(PhaseStopAccepting, "server", Hook(server.Stop)).
+		Register(PhaseDrain, "server", WaitHook(server.WaitTimeout))
+	if err := s.Run(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if !server.IsStopped() || !server.WaitTimeout(time.Millisecond) {
+		t.Error("the server has not been shut down")
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/xgfone/go-tools/types"
+)
+
+// ErrWriterClosed is returned when writing the data into the closed writer.
+var ErrWriterClosed = errors.New("the writer has been closed")
+
+// OverflowPolicy is the policy when the queue of AsyncWriter is full.
+type OverflowPolicy int
+
+// Predefine some overflow policies.
+const (
+	// OverflowBlock blocks the write until the queue has the free space.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropOldest drops the oldest record in the queue.
+	OverflowDropOldest
+)
+
+// AsyncWriter is a writer to queue the data in a bounded queue and write
+// them into the underlying writer in the background goroutine, so that
+// the caller is not blocked by the slow writes, such as the disk.
+//
+// You can flush the logs when shutting down, such as
+//
+//	shutdown.Register(lifecycle.PhaseFlush, "logs", writer.FlushContext)
+type AsyncWriter struct {
+	// OnError is called when failing to write the data into the underlying
+	// writer, which is ignored by default.
+	OnError func(error)
+
+	writer  io.Writer
+	size    int
+	policy  OverflowPolicy
+	lock    sync.Mutex
+	cond    *sync.Cond
+	queue   *types.Deque
+	queued  uint64
+	written uint64
+	dropped uint64
+	closed  bool
+	done    chan struct{}
+}
+
+// NewAsyncWriter returns a new AsyncWriter, which queues size records
+// at most and starts a background goroutine to write them into w.
+//
+// If size is equal to or less than 0, it is 1024 by default.
+func NewAsyncWriter(w io.Writer, size int, policy OverflowPolicy) *AsyncWriter {
+	if size <= 0 {
+		size = 1024
+	}
+
+	aw := &AsyncWriter{
+		writer: w,
+		size:   size,
+		policy: policy,
+		queue:  types.NewDeque(),
+		done:   make(chan struct{}),
+	}
+	aw.cond = sync.NewCond(&aw.lock)
+	go aw.loop()
+	return aw
+}
+
+// Write implements the interface io.Writer, which copies and queues p.
+func (w *AsyncWriter) Write(p []byte) (n int, err error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for !w.closed && w.queue.Len() >= w.size {
+		if w.policy == OverflowDropOldest {
+			w.queue.PopFront()
+			w.dropped++
+			w.written++ // The dropped is regarded as being handled.
+			break
+		}
+		w.cond.Wait()
+	}
+
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+
+	w.queue.PushBack(data)
+	w.queued++
+	w.cond.Broadcast()
+	return len(p), nil
+}
+
+func (w *AsyncWriter) loop() {
+	defer close(w.done)
+
+	buffers := make([][]byte, 0, 64)
+	for {
+		w.lock.Lock()
+		for !w.closed && w.queue.Len() == 0 {
+			w.cond.Wait()
+		}
+		if w.closed && w.queue.Len() == 0 {
+			w.lock.Unlock()
+			return
+		}
+
+		for w.queue.Len() > 0 && len(buffers) < cap(buffers) {
+			v, _ := w.queue.PopFront()
+			buffers = append(buffers, v.([]byte))
+		}
+		w.cond.Broadcast() // Wake up the blocked writers.
+		w.lock.Unlock()
+
+		for _, data := range buffers {
+			if _, err := w.writer.Write(data); err != nil && w.OnError != nil {
+				w.OnError(err)
+			}
+		}
+
+		w.lock.Lock()
+		w.written += uint64(len(buffers))
+		w.cond.Broadcast() // Wake up the flushers.
+		w.lock.Unlock()
+
+		for i := range buffers {
+			buffers[i] = nil
+		}
+		buffers = buffers[:0]
+	}
+}
+
+// Dropped returns the number of the dropped records by OverflowDropOldest.
+func (w *AsyncWriter) Dropped() uint64 {
+	w.lock.Lock()
+	n := w.dropped
+	w.lock.Unlock()
+	return n
+}
+
+// Flush waits until all the queued data before calling it has been written
+// into the underlying writer.
+func (w *AsyncWriter) Flush() error {
+	return w.FlushContext(context.Background())
+}
+
+// FlushContext is the same as Flush, but returns ctx.Err() if ctx is done
+// before finishing, which may be used as the shutdown hook of lifecycle.
+func (w *AsyncWriter) FlushContext(ctx context.Context) (err error) {
+	w.lock.Lock()
+	target := w.queued
+	if w.written >= target {
+		w.lock.Unlock()
+		return w.sync()
+	}
+	w.lock.Unlock()
+
+	flushed := make(chan struct{})
+	go func() {
+		w.lock.Lock()
+		for w.written < target {
+			w.cond.Wait()
+		}
+		w.lock.Unlock()
+		close(flushed)
+	}()
+
+	select {
+	case <-flushed:
+		return w.sync()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *AsyncWriter) sync() error {
+	if s, ok := w.writer.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close drains the queue and closes the underlying writer
+// if it implements io.Closer.
+func (w *AsyncWriter) Close() error {
+	w.lock.Lock()
+	if w.closed {
+		w.lock.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.cond.Broadcast()
+	w.lock.Unlock()
+
+	<-w.done
+	if c, ok := w.writer.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type slowWriter struct {
+	lock  sync.Mutex
+	buf   bytes.Buffer
+	delay time.Duration
+	block chan struct{}
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	if w.block != nil {
+		<-w.block
+	}
+	time.Sleep(w.delay)
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *slowWriter) String() string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriterBlock(t *testing.T) {
+	sw := &slowWriter{delay: time.Millisecond}
+	w := NewAsyncWriter(sw, 2, OverflowBlock)
+
+	buf := []byte("a")
+	for _, c := range []byte("abcdef") {
+		buf[0] = c
+		w.Write(buf)
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Error(err)
+	} else if s := sw.String(); s != "abcdef" {
+		t.Errorf("expected 'abcdef', but got '%s'", s)
+	}
+
+	w.Write([]byte("g"))
+	w.Close()
+	if s := sw.String(); s != "abcdefg" {
+		t.Errorf("expected 'abcdefg', but got '%s'", s)
+	}
+	if _, err := w.Write([]byte("h")); err != ErrWriterClosed {
+		t.Errorf("expected ErrWriterClosed, but got %v", err)
+	}
+}
+
+func TestAsyncWriterDropOldest(t *testing.T) {
+	sw := &slowWriter{block: make(chan struct{})}
+	w := NewAsyncWriter(sw, 2, OverflowDropOldest)
+
+	w.Write([]byte("a"))
+	time.Sleep(time.Millisecond * 10) // Wait that "a" is being written.
+	for _, s := range []string{"b", "c", "d"} {
+		w.Write([]byte(s))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := w.FlushContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected DeadlineExceeded, but got %v", err)
+	}
+
+	close(sw.block)
+	w.Close()
+	if s := sw.String(); s != "acd" {
+		t.Errorf("expected 'acd', but got '%s'", s)
+	}
+	if n := w.Dropped(); n != 1 {
+		t.Errorf("expected 1 dropped, but got %d", n)
+	}
+}
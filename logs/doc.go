@@ -0,0 +1,31 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logs supplies a leveled structured logger, which outputs
+// the message with the key-value fields, such as
+//
+//	logger := logs.New(os.Stderr, logs.LvlInfo)
+//	logger.Info("start the server", "addr", ":80")
+//	logger.Module("db").Debug("connect to the database", "host", "127.0.0.1")
+//
+//	(Output)
+//	2019-06-01T10:00:00.000+08:00 INFO start the server addr=:80
+//
+// The level of a module can be overridden by SetModuleLevel, such as
+//
+//	logger.SetModuleLevel("db", logs.LvlDebug)
+//
+// The interface Logger is the minimal logger accepted by the other packages,
+// such as net2.Server, so you can inject your own logger.
+package logs
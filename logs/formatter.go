@@ -0,0 +1,163 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Record is a log record.
+type Record struct {
+	Time   time.Time
+	Level  Level
+	Module string
+	Msg    string
+
+	// Fields is the key-value pairs, which has been normalized
+	// so that the length is even and the keys are strings.
+	Fields []interface{}
+}
+
+// Formatter is used to format the log record into the buffer.
+type Formatter interface {
+	Format(buf *bytes.Buffer, r Record)
+}
+
+// FormatterFunc is a function formatter.
+type FormatterFunc func(buf *bytes.Buffer, r Record)
+
+// Format implements the interface Formatter.
+func (f FormatterFunc) Format(buf *bytes.Buffer, r Record) { f(buf, r) }
+
+// TimeFormat is the format of the time used by the builtin formatters.
+var TimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// TextFormatter returns a formatter to output the record as the line,
+// such as
+//
+//	2019-06-01T10:00:00.000+08:00 INFO module msg key1=value1 key2="value 2"
+func TextFormatter() Formatter {
+	return FormatterFunc(func(buf *bytes.Buffer, r Record) {
+		buf.WriteString(r.Time.Format(TimeFormat))
+		buf.WriteByte(' ')
+		buf.WriteString(r.Level.String())
+		if r.Module != "" {
+			buf.WriteByte(' ')
+			buf.WriteString(r.Module)
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(r.Msg)
+
+		for i := 0; i < len(r.Fields); i += 2 {
+			buf.WriteByte(' ')
+			buf.WriteString(r.Fields[i].(string))
+			buf.WriteByte('=')
+			writeTextValue(buf, r.Fields[i+1])
+		}
+		buf.WriteByte('\n')
+	})
+}
+
+func writeTextValue(buf *bytes.Buffer, v interface{}) {
+	var s string
+	switch value := v.(type) {
+	case nil:
+		s = "<nil>"
+	case string:
+		s = value
+	case error:
+		s = value.Error()
+	case fmt.Stringer:
+		s = value.String()
+	case time.Time:
+		s = value.Format(TimeFormat)
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	if needQuote(s) {
+		buf.WriteString(strconv.Quote(s))
+	} else {
+		buf.WriteString(s)
+	}
+}
+
+func needQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, c := range s {
+		if c <= ' ' || c == '"' || c == '=' || c == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONFormatter returns a formatter to output the record as a JSON line,
+// such as
+//
+//	{"t":"2019-06-01T10:00:00.000+08:00","lvl":"INFO","mod":"module","msg":"msg","key":"value"}
+func JSONFormatter() Formatter {
+	return FormatterFunc(func(buf *bytes.Buffer, r Record) {
+		buf.WriteString(`{"t":"`)
+		buf.WriteString(r.Time.Format(TimeFormat))
+		buf.WriteString(`","lvl":"`)
+		buf.WriteString(r.Level.String())
+		buf.WriteByte('"')
+		if r.Module != "" {
+			buf.WriteString(`,"mod":`)
+			writeJSONValue(buf, r.Module)
+		}
+		buf.WriteString(`,"msg":`)
+		writeJSONValue(buf, r.Msg)
+
+		for i := 0; i < len(r.Fields); i += 2 {
+			buf.WriteByte(',')
+			writeJSONValue(buf, r.Fields[i])
+			buf.WriteByte(':')
+			writeJSONValue(buf, r.Fields[i+1])
+		}
+		buf.WriteString("}\n")
+	})
+}
+
+func writeJSONValue(buf *bytes.Buffer, v interface{}) {
+	switch value := v.(type) {
+	case string:
+		buf.WriteString(strconv.Quote(value))
+		return
+	case error:
+		buf.WriteString(strconv.Quote(value.Error()))
+		return
+	case time.Time:
+		buf.WriteString(strconv.Quote(value.Format(TimeFormat)))
+		return
+	case time.Duration:
+		buf.WriteString(strconv.Quote(value.String()))
+		return
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		buf.WriteString(strconv.Quote(fmt.Sprint(v)))
+	} else {
+		buf.Write(data)
+	}
+}
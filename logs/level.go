@@ -0,0 +1,67 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is the level of the log.
+type Level int32
+
+// Predefine some levels.
+const (
+	LvlDebug Level = iota
+	LvlInfo
+	LvlWarn
+	LvlError
+	LvlOff
+)
+
+func (l Level) String() string {
+	switch l {
+	case LvlDebug:
+		return "DEBUG"
+	case LvlInfo:
+		return "INFO"
+	case LvlWarn:
+		return "WARN"
+	case LvlError:
+		return "ERROR"
+	case LvlOff:
+		return "OFF"
+	default:
+		return fmt.Sprintf("Level(%d)", l)
+	}
+}
+
+// ParseLevel parses the level from the string, which is case-insensitive.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "DEBUG":
+		return LvlDebug, nil
+	case "INFO":
+		return LvlInfo, nil
+	case "WARN", "WARNING":
+		return LvlWarn, nil
+	case "ERROR":
+		return LvlError, nil
+	case "OFF":
+		return LvlOff, nil
+	default:
+		return LvlOff, fmt.Errorf("unknown log level '%s'", s)
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xgfone/go-tools/pools"
+)
+
+// Logger is the minimal interface of the leveled structured logger,
+// which is accepted by the other packages, such as net2.Server.
+//
+// kvs is the key-value pairs, such as "key1", value1, "key2", value2.
+type Logger interface {
+	Debug(msg string, kvs ...interface{})
+	Info(msg string, kvs ...interface{})
+	Warn(msg string, kvs ...interface{})
+	Error(msg string, kvs ...interface{})
+}
+
+// Discard is a Logger to discard all the logs.
+var Discard Logger = discard{}
+
+type discard struct{}
+
+func (discard) Debug(string, ...interface{}) {}
+func (discard) Info(string, ...interface{})  {}
+func (discard) Warn(string, ...interface{})  {}
+func (discard) Error(string, ...interface{}) {}
+
+// DefaultLogger is the default global logger, which outputs the logs
+// to os.Stderr with the level LvlInfo.
+var DefaultLogger = New(os.Stderr, LvlInfo)
+
+// Debug is equal to DefaultLogger.Debug(msg, kvs...).
+func Debug(msg string, kvs ...interface{}) { DefaultLogger.log(LvlDebug, msg, kvs) }
+
+// Info is equal to DefaultLogger.Info(msg, kvs...).
+func Info(msg string, kvs ...interface{}) { DefaultLogger.log(LvlInfo, msg, kvs) }
+
+// Warn is equal to DefaultLogger.Warn(msg, kvs...).
+func Warn(msg string, kvs ...interface{}) { DefaultLogger.log(LvlWarn, msg, kvs) }
+
+// Error is equal to DefaultLogger.Error(msg, kvs...).
+func Error(msg string, kvs ...interface{}) { DefaultLogger.log(LvlError, msg, kvs) }
+
+type core struct {
+	level     int32
+	lock      sync.RWMutex
+	modules   map[string]Level
+	formatter Formatter
+	writer    io.Writer
+	wlock     sync.Mutex
+	buffers   pools.BufferPool
+	onError   func(error)
+}
+
+// StructLogger is a leveled structured logger, which implements
+// the interface Logger.
+//
+// The loggers derived by With and Module share the level, the formatter
+// and the writer with the parent.
+type StructLogger struct {
+	core   *core
+	module string
+	fields []interface{}
+}
+
+// New returns a new StructLogger, which outputs the logs to w
+// with the text formatter.
+//
+// If w is nil, it discards all the logs.
+func New(w io.Writer, level Level) *StructLogger {
+	if w == nil {
+		w = ioutil.Discard
+	}
+
+	return &StructLogger{core: &core{
+		level:     int32(level),
+		modules:   make(map[string]Level),
+		formatter: TextFormatter(),
+		writer:    w,
+		buffers:   pools.NewBufferPool(256),
+	}}
+}
+
+// SetLevel resets the global level of the logger.
+func (l *StructLogger) SetLevel(level Level) {
+	atomic.StoreInt32(&l.core.level, int32(level))
+}
+
+// GetLevel returns the global level of the logger.
+func (l *StructLogger) GetLevel() Level {
+	return Level(atomic.LoadInt32(&l.core.level))
+}
+
+// SetModuleLevel overrides the level of the module, which takes precedence
+// over the global level.
+func (l *StructLogger) SetModuleLevel(module string, level Level) {
+	l.core.lock.Lock()
+	l.core.modules[module] = level
+	l.core.lock.Unlock()
+}
+
+// ResetModuleLevel removes the override of the level of the module.
+func (l *StructLogger) ResetModuleLevel(module string) {
+	l.core.lock.Lock()
+	delete(l.core.modules, module)
+	l.core.lock.Unlock()
+}
+
+// SetFormatter resets the formatter, which is TextFormatter by default.
+func (l *StructLogger) SetFormatter(f Formatter) {
+	l.core.lock.Lock()
+	l.core.formatter = f
+	l.core.lock.Unlock()
+}
+
+// SetWriter resets the writer of the logs.
+func (l *StructLogger) SetWriter(w io.Writer) {
+	l.core.wlock.Lock()
+	l.core.writer = w
+	l.core.wlock.Unlock()
+}
+
+// SetErrorHandler sets the handler to be called when failing to write
+// the log, which is ignored by default.
+func (l *StructLogger) SetErrorHandler(f func(error)) {
+	l.core.lock.Lock()
+	l.core.onError = f
+	l.core.lock.Unlock()
+}
+
+// Module returns a new child logger with the module name.
+func (l *StructLogger) Module(name string) *StructLogger {
+	return &StructLogger{core: l.core, module: name, fields: l.fields}
+}
+
+// With returns a new child logger with the key-value fields,
+// which are output before the fields of each log.
+func (l *StructLogger) With(kvs ...interface{}) *StructLogger {
+	fields := make([]interface{}, 0, len(l.fields)+len(kvs)+1)
+	fields = append(fields, l.fields...)
+	fields = normalize(fields, kvs)
+	return &StructLogger{core: l.core, module: l.module, fields: fields}
+}
+
+// Enabled reports whether the level is enabled for the module of the logger.
+func (l *StructLogger) Enabled(level Level) bool {
+	if l.module != "" {
+		l.core.lock.RLock()
+		lvl, ok := l.core.modules[l.module]
+		l.core.lock.RUnlock()
+		if ok {
+			return level >= lvl && level < LvlOff
+		}
+	}
+	return level >= l.GetLevel() && level < LvlOff
+}
+
+// Debug outputs the log with the level LvlDebug.
+func (l *StructLogger) Debug(msg string, kvs ...interface{}) { l.log(LvlDebug, msg, kvs) }
+
+// Info outputs the log with the level LvlInfo.
+func (l *StructLogger) Info(msg string, kvs ...interface{}) { l.log(LvlInfo, msg, kvs) }
+
+// Warn outputs the log with the level LvlWarn.
+func (l *StructLogger) Warn(msg string, kvs ...interface{}) { l.log(LvlWarn, msg, kvs) }
+
+// Error outputs the log with the level LvlError.
+func (l *StructLogger) Error(msg string, kvs ...interface{}) { l.log(LvlError, msg, kvs) }
+
+// Log outputs the log with the level.
+func (l *StructLogger) Log(level Level, msg string, kvs ...interface{}) {
+	l.log(level, msg, kvs)
+}
+
+func (l *StructLogger) log(level Level, msg string, kvs []interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	fields := l.fields
+	if len(kvs) > 0 {
+		fields = make([]interface{}, 0, len(l.fields)+len(kvs)+1)
+		fields = append(fields, l.fields...)
+		fields = normalize(fields, kvs)
+	}
+
+	l.core.lock.RLock()
+	formatter, onError := l.core.formatter, l.core.onError
+	l.core.lock.RUnlock()
+
+	buf := l.core.buffers.Get()
+	formatter.Format(buf, Record{
+		Time:   time.Now(),
+		Level:  level,
+		Module: l.module,
+		Msg:    msg,
+		Fields: fields,
+	})
+
+	l.core.wlock.Lock()
+	_, err := l.core.writer.Write(buf.Bytes())
+	l.core.wlock.Unlock()
+	l.core.buffers.Put(buf)
+
+	if err != nil && onError != nil {
+		onError(err)
+	}
+}
+
+// normalize appends the key-value pairs kvs into fields, which converts
+// the non-string keys to strings and pads the missing value of the last key.
+func normalize(fields, kvs []interface{}) []interface{} {
+	for i, n := 0, len(kvs); i < n; i += 2 {
+		key, ok := kvs[i].(string)
+		if !ok {
+			key = fmt.Sprint(kvs[i])
+		}
+
+		if i+1 < n {
+			fields = append(fields, key, kvs[i+1])
+		} else {
+			fields = append(fields, key, "MISSING")
+		}
+	}
+	return fields
+}
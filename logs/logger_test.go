@@ -0,0 +1,79 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStructLogger(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	logger := New(buf, LvlInfo)
+
+	logger.Debug("debug")
+	logger.Info("info", "key1", "value1", "key2", "value 2", 3)
+	logger.With("key", "value").Warn("warn", "err", errors.New("error"))
+
+	db := logger.Module("db")
+	db.Debug("module debug")
+	logger.SetModuleLevel("db", LvlDebug)
+	db.Debug("module debug", "delay", 1)
+	logger.Debug("debug")
+	logger.SetModuleLevel("db", LvlOff)
+	db.Error("module error")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expects := []string{
+		`INFO info key1=value1 key2="value 2" 3=MISSING`,
+		`WARN warn key=value err=error`,
+		`DEBUG db module debug delay=1`,
+	}
+	if len(lines) != len(expects) {
+		t.Fatalf("expected %d lines, but got %d: %v", len(expects), len(lines), lines)
+	}
+	for i, line := range lines {
+		if index := strings.IndexByte(line, ' '); line[index+1:] != expects[i] {
+			t.Errorf("expected '%s', but got '%s'", expects[i], line[index+1:])
+		}
+	}
+}
+
+func TestJSONFormatter(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	logger := New(buf, LvlDebug)
+	logger.SetFormatter(JSONFormatter())
+	logger.Module("mod").Info("msg", "int", 1, "str", "a\"b", "list", []int{1, 2})
+
+	s := buf.String()
+	expect := `"lvl":"INFO","mod":"mod","msg":"msg","int":1,"str":"a\"b","list":[1,2]}` + "\n"
+	if !strings.HasSuffix(s, expect) {
+		t.Errorf("unexpected json: %s", s)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, lvl := range []Level{LvlDebug, LvlInfo, LvlWarn, LvlError, LvlOff} {
+		if l, err := ParseLevel(strings.ToLower(lvl.String())); err != nil || l != lvl {
+			t.Errorf("expected level '%s', but got '%s': %v", lvl, l, err)
+		}
+	}
+
+	if _, err := ParseLevel("abc"); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const backupTimeFormat = "20060102-150405.000000000"
+
+// RotatingFile is a file writer to rotate the file at the maximum size
+// or daily, which is safe for the concurrent writes.
+//
+// The rotated file is renamed to "FILENAME.TIMESTAMP", such as
+// "app.log.20190601-150405.000000000", and appended the suffix ".gz"
+// if compressed.
+type RotatingFile struct {
+	// Filename is the path of the log file. It is required.
+	Filename string
+
+	// MaxSize is the maximum size in bytes of the log file before rotating.
+	// The default is 0, which means no limit.
+	MaxSize int64
+
+	// Daily reports whether to rotate the file when the day changes.
+	Daily bool
+
+	// MaxBackups is the maximum number of the rotated files to keep,
+	// and the older will be removed. The default is 0, which keeps all.
+	MaxBackups int
+
+	// Compress reports whether to compress the rotated files by gzip,
+	// which is done in the background.
+	Compress bool
+
+	// LocalTime reports whether to use the local time, or UTC by default,
+	// to name the rotated files and decide the day.
+	LocalTime bool
+
+	lock  sync.Mutex
+	file  *os.File
+	size  int64
+	day   int
+	mlock sync.Mutex // serialize the compression and the cleanup.
+	waits sync.WaitGroup
+	now   func() time.Time
+}
+
+// NewRotatingFile returns a new RotatingFile to rotate the file
+// when its size reaches maxSize, and keep maxBackups rotated files at most.
+func NewRotatingFile(filename string, maxSize int64, maxBackups int) *RotatingFile {
+	return &RotatingFile{Filename: filename, MaxSize: maxSize, MaxBackups: maxBackups}
+}
+
+func (f *RotatingFile) getNow() time.Time {
+	now := time.Now
+	if f.now != nil {
+		now = f.now
+	}
+
+	if f.LocalTime {
+		return now()
+	}
+	return now().UTC()
+}
+
+func dayOf(t time.Time) int {
+	y, m, d := t.Date()
+	return y*10000 + int(m)*100 + d
+}
+
+// Write implements the interface io.Writer.
+func (f *RotatingFile) Write(p []byte) (n int, err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		if err = f.open(); err != nil {
+			return
+		}
+	}
+
+	if f.Daily && dayOf(f.getNow()) != f.day {
+		err = f.rotate()
+	} else if f.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.MaxSize {
+		err = f.rotate()
+	}
+	if err != nil {
+		return
+	}
+
+	n, err = f.file.Write(p)
+	f.size += int64(n)
+	return
+}
+
+// WriteString implements the interface strings2.StringWriter.
+func (f *RotatingFile) WriteString(s string) (n int, err error) {
+	return f.Write([]byte(s))
+}
+
+func (f *RotatingFile) open() error {
+	if f.Filename == "" {
+		return errors.New("the log filename is empty")
+	}
+
+	if dir := filepath.Dir(f.Filename); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(f.Filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	modTime := fi.ModTime()
+	if !f.LocalTime {
+		modTime = modTime.UTC()
+	}
+	if fi.Size() == 0 {
+		modTime = f.getNow()
+	}
+
+	f.file, f.size, f.day = file, fi.Size(), dayOf(modTime)
+	return nil
+}
+
+// Rotate closes the current file, renames it as a backup,
+// and opens a new file.
+func (f *RotatingFile) Rotate() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.rotate()
+}
+
+func (f *RotatingFile) rotate() (err error) {
+	if f.file != nil {
+		if err = f.file.Close(); err != nil {
+			return
+		}
+		f.file = nil
+	}
+
+	backup := f.Filename + "." + f.getNow().Format(backupTimeFormat)
+	if err = os.Rename(f.Filename, backup); err != nil && !os.IsNotExist(err) {
+		return
+	} else if err = f.open(); err != nil {
+		return
+	}
+
+	f.waits.Add(1)
+	go f.mill(backup)
+	return
+}
+
+// mill compresses the backup file and removes the old backups.
+func (f *RotatingFile) mill(backup string) {
+	defer f.waits.Done()
+	f.mlock.Lock()
+	defer f.mlock.Unlock()
+
+	if f.Compress {
+		if err := compressFile(backup); err == nil {
+			os.Remove(backup)
+		}
+	}
+
+	if f.MaxBackups > 0 {
+		backups := f.Backups()
+		for i, n := 0, len(backups)-f.MaxBackups; i < n; i++ {
+			os.Remove(backups[i])
+		}
+	}
+}
+
+// Backups returns the paths of the rotated files, which are sorted
+// from the oldest to the newest.
+func (f *RotatingFile) Backups() []string {
+	dir, base := filepath.Split(f.Filename)
+	if dir == "" {
+		dir = "."
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return nil
+	}
+	names, _ := d.Readdirnames(-1)
+	d.Close()
+
+	prefix := base + "."
+	backups := make([]string, 0, len(names))
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		ts := strings.TrimSuffix(name[len(prefix):], ".gz")
+		if _, err := time.Parse(backupTimeFormat, ts); err == nil {
+			backups = append(backups, filepath.Join(dir, name))
+		}
+	}
+
+	// The timestamp is sortable lexicographically.
+	sort.Slice(backups, func(i, j int) bool {
+		return strings.TrimSuffix(backups[i], ".gz") < strings.TrimSuffix(backups[j], ".gz")
+	})
+	return backups
+}
+
+func compressFile(filename string) (err error) {
+	src, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(filename+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return
+	}
+
+	gw := gzip.NewWriter(dst)
+	if _, err = io.Copy(gw, src); err == nil {
+		err = gw.Close()
+	}
+	if e := dst.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		os.Remove(filename + ".gz")
+	}
+	return
+}
+
+// Sync commits the current contents of the file to the disk.
+func (f *RotatingFile) Sync() (err error) {
+	f.lock.Lock()
+	if f.file != nil {
+		err = f.file.Sync()
+	}
+	f.lock.Unlock()
+	return
+}
+
+// Close closes the file, and waits until the background compressions
+// and cleanups finish.
+func (f *RotatingFile) Close() (err error) {
+	f.lock.Lock()
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.lock.Unlock()
+	f.waits.Wait()
+	return
+}
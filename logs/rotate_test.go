@@ -0,0 +1,100 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "app.log")
+	f := NewRotatingFile(filename, 10, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.Write([]byte("123456\n"))
+		}()
+	}
+	wg.Wait()
+	f.Close()
+
+	if backups := f.Backups(); len(backups) != 2 {
+		t.Errorf("expected 2 backups, but got %v", backups)
+	}
+
+	if data, err := ioutil.ReadFile(filename); err != nil {
+		t.Error(err)
+	} else if string(data) != "123456\n" {
+		t.Errorf("unexpected the content '%s'", data)
+	}
+}
+
+func TestRotatingFileDailyCompress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2019, 6, 1, 23, 59, 0, 0, time.UTC)
+	f := &RotatingFile{
+		Filename: filepath.Join(dir, "app.log"),
+		Daily:    true,
+		Compress: true,
+		now:      func() time.Time { return now },
+	}
+
+	f.WriteString("day1\n")
+	now = now.Add(time.Minute)
+	f.WriteString("day2\n")
+	f.Close()
+
+	backups := f.Backups()
+	if len(backups) != 1 || !strings.HasSuffix(backups[0], ".20190602-000000.000000000.gz") {
+		t.Fatalf("unexpected backups: %v", backups)
+	}
+
+	file, err := os.Open(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	r, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ioutil.ReadAll(r); err != nil {
+		t.Error(err)
+	} else if string(data) != "day1\n" {
+		t.Errorf("unexpected the content '%s'", data)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ACL is the access control list of the IP addresses by the CIDRs.
+//
+// The deny list takes precedence over the allow list. If the allow list
+// is empty, all the addresses not denied are allowed.
+type ACL struct {
+	allows []*net.IPNet
+	denies []*net.IPNet
+}
+
+// NewACL returns a new ACL with the allow and deny lists, each element of
+// which is a CIDR, such as "192.168.0.0/16", or an IP, such as "10.0.0.1".
+func NewACL(allows, denies []string) (*ACL, error) {
+	var acl ACL
+	var err error
+	if acl.allows, err = parseIPNets(allows); err != nil {
+		return nil, err
+	}
+	if acl.denies, err = parseIPNets(denies); err != nil {
+		return nil, err
+	}
+	return &acl, nil
+}
+
+func parseIPNet(s string) (*net.IPNet, error) {
+	if strings.IndexByte(s, '/') > -1 {
+		_, ipnet, err := net.ParseCIDR(s)
+		return ipnet, err
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP '%s'", s)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+func parseIPNets(ss []string) ([]*net.IPNet, error) {
+	ipnets := make([]*net.IPNet, 0, len(ss))
+	for _, s := range ss {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+
+		ipnet, err := parseIPNet(s)
+		if err != nil {
+			return nil, err
+		}
+		ipnets = append(ipnets, ipnet)
+	}
+	return ipnets, nil
+}
+
+func containsIP(ipnets []*net.IPNet, ip net.IP) bool {
+	for _, ipnet := range ipnets {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allow reports whether the ip is allowed.
+func (a *ACL) Allow(ip net.IP) bool {
+	if containsIP(a.denies, ip) {
+		return false
+	}
+	return len(a.allows) == 0 || containsIP(a.allows, ip)
+}
+
+// AllowAddr reports whether the address is allowed.
+//
+// The address that has no IP, such as the unix socket, is always allowed.
+func (a *ACL) AllowAddr(addr net.Addr) bool {
+	switch v := addr.(type) {
+	case *net.TCPAddr:
+		return a.Allow(v.IP)
+	case *net.UDPAddr:
+		return a.Allow(v.IP)
+	case *net.IPAddr:
+		return a.Allow(v.IP)
+	default:
+		return true
+	}
+}
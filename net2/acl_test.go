@@ -0,0 +1,75 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestACL(t *testing.T) {
+	acl, err := NewACL([]string{"10.0.0.0/8", "192.168.1.1"}, []string{"10.1.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for ip, allowed := range map[string]bool{
+		"10.0.0.1":    true,
+		"10.1.0.1":    false,
+		"192.168.1.1": true,
+		"192.168.1.2": false,
+		"127.0.0.1":   false,
+	} {
+		if acl.Allow(net.ParseIP(ip)) != allowed {
+			t.Errorf("%s: expect %v", ip, allowed)
+		}
+	}
+
+	if _, err = NewACL([]string{"abc"}, nil); err == nil {
+		t.Error("expect an error")
+	}
+}
+
+func TestServerACL(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(echoHandler)
+	s.ACL, _ = NewACL(nil, []string{"127.0.0.0/8"})
+	go s.Serve(ln)
+	defer s.Stop()
+
+	// The connection may be reset by the server during dialing.
+	if conn, err := net.Dial("tcp", ln.Addr().String()); err == nil {
+		buf := make([]byte, 3)
+		conn.SetDeadline(time.Now().Add(time.Second))
+		conn.Write([]byte("abc"))
+		if _, err = io.ReadFull(conn, buf); err == nil {
+			t.Error("expect the connection to be denied")
+		}
+		conn.Close()
+	}
+
+	for i := 0; i < 100 && s.Stats().Denied == 0; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+	if stats := s.Stats(); stats.Denied != 1 {
+		t.Errorf("expect 1 denied connection, but got %d", stats.Denied)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.19
+// +build go1.19
+
+package net2
+
+import (
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTLSOptionsRootCAsNotModified(t *testing.T) {
+	dir := generateCerts(t)
+	defer os.RemoveAll(dir)
+
+	base := x509.NewCertPool()
+	opts := TLSOptions{RootCAs: base, RootCAFiles: []string{filepath.Join(dir, "ca.crt")}}
+
+	c1, err := opts.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := opts.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !base.Equal(x509.NewCertPool()) {
+		t.Error("RootCAs has been modified")
+	}
+	if c1.RootCAs == base || !c1.RootCAs.Equal(c2.RootCAs) {
+		t.Error("expect a new pool containing the same certificates")
+	}
+}
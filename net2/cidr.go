@@ -0,0 +1,173 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"math/big"
+	"net"
+)
+
+// CIDR is the wrapper of net.IPNet to do the address math.
+type CIDR struct {
+	net.IPNet
+}
+
+// ParseCIDR parses the CIDR, such as "192.168.0.0/16". If s is a single IP,
+// it's considered as the CIDR containing only itself, such as "1.2.3.4/32".
+//
+// The IP of the returned CIDR is the network address.
+func ParseCIDR(s string) (CIDR, error) {
+	ipnet, err := parseIPNet(s)
+	if err != nil {
+		return CIDR{}, err
+	}
+	return CIDR{IPNet: *ipnet}, nil
+}
+
+// MustParseCIDR is the same as ParseCIDR, but panics if there is an error.
+func MustParseCIDR(s string) CIDR {
+	cidr, err := ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return cidr
+}
+
+// ContainsString is the same as Contains, but the ip is a string.
+// It returns false if ip is invalid.
+func (c CIDR) ContainsString(ip string) bool {
+	_ip := net.ParseIP(ip)
+	return _ip != nil && c.Contains(_ip)
+}
+
+// Overlaps reports whether the two CIDRs have the common addresses.
+func (c CIDR) Overlaps(other CIDR) bool {
+	return c.Contains(other.IP) || other.Contains(c.IP)
+}
+
+// Bits returns the number of the bits of the IP, that's, 32 or 128.
+func (c CIDR) Bits() int {
+	_, bits := c.Mask.Size()
+	return bits
+}
+
+// Prefix returns the length of the prefix.
+func (c CIDR) Prefix() int {
+	ones, _ := c.Mask.Size()
+	return ones
+}
+
+// First returns the first address, that's, the network address.
+func (c CIDR) First() net.IP {
+	return c.IP.Mask(c.Mask)
+}
+
+// Last returns the last address, such as the broadcast address for IPv4.
+func (c CIDR) Last() net.IP {
+	first := c.First()
+	last := make(net.IP, len(first))
+	for i := range first {
+		last[i] = first[i] | ^c.Mask[i]
+	}
+	return last
+}
+
+// Size returns the number of all the addresses.
+func (c CIDR) Size() *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(c.Bits()-c.Prefix()))
+}
+
+// hasNetworkBroadcast reports whether the first and last addresses
+// are reserved as the network and broadcast addresses, which is only
+// for IPv4 whose prefix is less than 31.
+func (c CIDR) hasNetworkBroadcast() bool {
+	return c.Bits() == 32 && c.Prefix() < 31
+}
+
+// Usable returns the number of the usable host addresses, which excludes
+// the network and broadcast addresses for IPv4 whose prefix is less than 31.
+func (c CIDR) Usable() *big.Int {
+	size := c.Size()
+	if c.hasNetworkBroadcast() {
+		size.Sub(size, big.NewInt(2))
+	}
+	return size
+}
+
+// FirstUsable returns the first usable host address.
+func (c CIDR) FirstUsable() net.IP {
+	if c.hasNetworkBroadcast() {
+		return IncIP(c.First())
+	}
+	return c.First()
+}
+
+// LastUsable returns the last usable host address.
+func (c CIDR) LastUsable() net.IP {
+	if c.hasNetworkBroadcast() {
+		return DecIP(c.Last())
+	}
+	return c.Last()
+}
+
+// Hosts iterates over all the usable host addresses in order
+// until f returns false.
+//
+// Notice: the ip passed to f must not be modified or retained.
+func (c CIDR) Hosts(f func(ip net.IP) bool) {
+	last := c.LastUsable()
+	for ip := c.FirstUsable(); ; ip = incIP(ip) {
+		if !f(ip) || ip.Equal(last) {
+			return
+		}
+	}
+}
+
+// IncIP returns a new IP which is equal to ip plus 1.
+// It wraps around to zero if overflowing.
+func IncIP(ip net.IP) net.IP {
+	return incIP(copyIP(ip))
+}
+
+// DecIP returns a new IP which is equal to ip minus 1.
+// It wraps around to the maximum if underflowing.
+func DecIP(ip net.IP) net.IP {
+	ip = copyIP(ip)
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]--
+		if ip[i] != 0xff {
+			break
+		}
+	}
+	return ip
+}
+
+func copyIP(ip net.IP) net.IP {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return append(net.IP(nil), ip...)
+}
+
+// incIP increases ip in place.
+func incIP(ip net.IP) net.IP {
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]++
+		if ip[i] != 0 {
+			break
+		}
+	}
+	return ip
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"net"
+	"testing"
+)
+
+func TestCIDR(t *testing.T) {
+	cidr := MustParseCIDR("192.168.1.10/24")
+	if s := cidr.String(); s != "192.168.1.0/24" {
+		t.Errorf("unexpected cidr '%s'", s)
+	}
+	if !cidr.ContainsString("192.168.1.255") || cidr.ContainsString("192.168.2.1") {
+		t.Error("unexpected Contains result")
+	}
+	if first, last := cidr.First().String(), cidr.Last().String(); first != "192.168.1.0" || last != "192.168.1.255" {
+		t.Errorf("first=%s, last=%s", first, last)
+	}
+	if first, last := cidr.FirstUsable().String(), cidr.LastUsable().String(); first != "192.168.1.1" || last != "192.168.1.254" {
+		t.Errorf("first=%s, last=%s", first, last)
+	}
+	if size, usable := cidr.Size().Int64(), cidr.Usable().Int64(); size != 256 || usable != 254 {
+		t.Errorf("size=%d, usable=%d", size, usable)
+	}
+
+	if !cidr.Overlaps(MustParseCIDR("192.168.0.0/16")) || cidr.Overlaps(MustParseCIDR("192.168.2.0/24")) {
+		t.Error("unexpected Overlaps result")
+	}
+
+	var hosts []string
+	MustParseCIDR("10.0.0.0/30").Hosts(func(ip net.IP) bool {
+		hosts = append(hosts, ip.String())
+		return true
+	})
+	if len(hosts) != 2 || hosts[0] != "10.0.0.1" || hosts[1] != "10.0.0.2" {
+		t.Errorf("unexpected hosts %v", hosts)
+	}
+
+	if size := MustParseCIDR("::/64").Size().String(); size != "18446744073709551616" {
+		t.Errorf("unexpected size %s", size)
+	}
+
+	if ip := IncIP(net.ParseIP("10.0.0.255")).String(); ip != "10.0.1.0" {
+		t.Errorf("unexpected ip %s", ip)
+	}
+	if ip := DecIP(net.ParseIP("10.0.1.0")).String(); ip != "10.0.0.255" {
+		t.Errorf("unexpected ip %s", ip)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/xgfone/go-tools/pools"
+)
+
+// ErrMessageTooLong is returned when the message is longer than the limit.
+var ErrMessageTooLong = errors.New("the message is too long")
+
+// DelimiterCodec is a codec to read and write the messages terminated
+// by the delimiter, such as "\n", "\r\n" or other custom bytes, which is
+// used by the text protocols.
+type DelimiterCodec struct {
+	// Delimiter is the bytes terminating the message. The default is "\n".
+	Delimiter []byte
+
+	// MaxLength is the maximum length of the message read, which does not
+	// include the delimiter. 0 means no limit.
+	MaxLength int
+
+	// Escape is the escape byte. If not 0, when writing the message,
+	// it escapes the first byte of each delimiter and the escape byte itself
+	// in the message by prefixing the escape byte, and unescapes them when
+	// reading the message. So the message may contain the delimiter.
+	Escape byte
+}
+
+// NewDelimiterCodec returns a new DelimiterCodec.
+//
+// If delimiter is empty, it is "\n" by default.
+func NewDelimiterCodec(delimiter []byte, maxLength int) *DelimiterCodec {
+	if len(delimiter) == 0 {
+		delimiter = []byte{'\n'}
+	}
+	return &DelimiterCodec{Delimiter: delimiter, MaxLength: maxLength}
+}
+
+func (c *DelimiterCodec) delimiter() []byte {
+	if len(c.Delimiter) == 0 {
+		return []byte{'\n'}
+	}
+	return c.Delimiter
+}
+
+// ReadMessage reads a message from r, which does not include the delimiter.
+//
+// If the message exceeds MaxLength, it returns ErrMessageTooLong,
+// and the reader should not be used again.
+//
+// It returns io.EOF only if r has no data. If the data is terminated without
+// the delimiter, it returns io.ErrUnexpectedEOF with the data read.
+func (c *DelimiterCodec) ReadMessage(r *bufio.Reader) (msg []byte, err error) {
+	delim := c.delimiter()
+	last := delim[len(delim)-1]
+
+	var escaped bool
+	var literal int // The number of the bytes that cannot be a part of the delimiter.
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF && (len(msg) > 0 || escaped) {
+				err = io.ErrUnexpectedEOF
+			}
+			return msg, err
+		}
+
+		if escaped {
+			escaped = false
+			msg = append(msg, b)
+			literal = len(msg)
+		} else if c.Escape != 0 && b == c.Escape {
+			escaped = true
+			continue
+		} else {
+			msg = append(msg, b)
+			if b == last && len(msg)-literal >= len(delim) && bytes.HasSuffix(msg, delim) {
+				return msg[:len(msg)-len(delim)], nil
+			}
+		}
+
+		if c.MaxLength > 0 && len(msg) > c.MaxLength+len(delim)-1 {
+			return nil, ErrMessageTooLong
+		}
+	}
+}
+
+// WriteMessage writes the message into w with the delimiter.
+func (c *DelimiterCodec) WriteMessage(w io.Writer, msg []byte) (err error) {
+	delim := c.delimiter()
+	buf := pools.GetBytes(len(msg) + len(delim))[:0]
+	defer func() { pools.PutBytes(buf) }()
+
+	if c.Escape == 0 {
+		buf = append(buf, msg...)
+	} else {
+		for i, b := range msg {
+			if b == c.Escape || bytes.HasPrefix(msg[i:], delim) {
+				buf = append(buf, c.Escape)
+			}
+			buf = append(buf, b)
+		}
+	}
+
+	buf = append(buf, delim...)
+	_, err = w.Write(buf)
+	return
+}
+
+// LineConn is a connection wrapper to read and write the messages by lines.
+type LineConn struct {
+	net.Conn
+
+	codec  *DelimiterCodec
+	reader *bufio.Reader
+}
+
+// NewLineConn returns a new LineConn.
+//
+// If codec is nil, it is NewDelimiterCodec(nil, 0) by default.
+func NewLineConn(conn net.Conn, codec *DelimiterCodec) *LineConn {
+	if codec == nil {
+		codec = NewDelimiterCodec(nil, 0)
+	}
+	return &LineConn{Conn: conn, codec: codec, reader: bufio.NewReader(conn)}
+}
+
+// Read implements the interface io.Reader, which reads from the buffer first.
+func (c *LineConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// ReadLine reads a line from the connection, which does not include
+// the delimiter.
+//
+// If the delimiter is "\n", the trailing "\r" will be removed, too.
+func (c *LineConn) ReadLine() (line []byte, err error) {
+	line, err = c.codec.ReadMessage(c.reader)
+	if err == nil && len(line) > 0 && line[len(line)-1] == '\r' {
+		if delim := c.codec.delimiter(); len(delim) == 1 && delim[0] == '\n' {
+			line = line[:len(line)-1]
+		}
+	}
+	return
+}
+
+// WriteLine writes a line into the connection with the delimiter.
+func (c *LineConn) WriteLine(line []byte) error {
+	return c.codec.WriteMessage(c.Conn, line)
+}
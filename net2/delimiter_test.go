@@ -0,0 +1,85 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestDelimiterCodec(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	codec := NewDelimiterCodec([]byte("\r\n"), 8)
+	codec.WriteMessage(buf, []byte("abc"))
+	codec.WriteMessage(buf, []byte(""))
+	codec.WriteMessage(buf, []byte("123456789"))
+
+	r := bufio.NewReader(buf)
+	if msg, err := codec.ReadMessage(r); err != nil || string(msg) != "abc" {
+		t.Errorf("msg=%s, err=%v", string(msg), err)
+	}
+	if msg, err := codec.ReadMessage(r); err != nil || len(msg) != 0 {
+		t.Errorf("msg=%s, err=%v", string(msg), err)
+	}
+	if _, err := codec.ReadMessage(r); err != ErrMessageTooLong {
+		t.Errorf("expect ErrMessageTooLong, but got %v", err)
+	}
+
+	r = bufio.NewReader(bytes.NewBufferString("abc"))
+	if msg, err := codec.ReadMessage(r); err != io.ErrUnexpectedEOF || string(msg) != "abc" {
+		t.Errorf("msg=%s, err=%v", string(msg), err)
+	}
+	if _, err := codec.ReadMessage(r); err != io.EOF {
+		t.Errorf("expect io.EOF, but got %v", err)
+	}
+}
+
+func TestDelimiterCodecEscape(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	codec := NewDelimiterCodec([]byte("\r\n"), 0)
+	codec.Escape = '\\'
+
+	msgs := []string{"a\r\nb", "a\\b", "\r\n\\", "\\\\r\\n"}
+	for _, msg := range msgs {
+		codec.WriteMessage(buf, []byte(msg))
+	}
+
+	r := bufio.NewReader(buf)
+	for _, expect := range msgs {
+		if msg, err := codec.ReadMessage(r); err != nil || string(msg) != expect {
+			t.Errorf("expect '%q', but got '%q': %v", expect, string(msg), err)
+		}
+	}
+}
+
+func TestLineConn(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	go func() {
+		c1.Write([]byte("line1\r\nline2\n"))
+	}()
+
+	conn := NewLineConn(c2, nil)
+	for _, expect := range []string{"line1", "line2"} {
+		if line, err := conn.ReadLine(); err != nil || string(line) != expect {
+			t.Errorf("expect '%s', but got '%s': %v", expect, string(line), err)
+		}
+	}
+}
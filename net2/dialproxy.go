@@ -0,0 +1,223 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DialProxy dials a TCP connection to addr through the proxy,
+// the URL of which is like "socks5://[user:pass@]host:port"
+// or "http://[user:pass@]host:port".
+func DialProxy(ctx context.Context, proxy, addr string) (net.Conn, error) {
+	return Dialer{Proxy: proxy}.DialContext(ctx, "tcp", addr)
+}
+
+func dialProxy(ctx context.Context, dialer *net.Dialer, proxy, addr string) (net.Conn, error) {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	var handshake func(net.Conn, *url.URL, string) (net.Conn, error)
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		handshake = socks5Handshake
+	case "http":
+		handshake = httpConnectHandshake
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme '%s'", u.Scheme)
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else if dialer.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(dialer.Timeout))
+	}
+
+	c, err := handshake(conn, u, addr)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s: %s", u.Host, err.Error())
+	}
+
+	conn.SetDeadline(time.Time{})
+	return c, nil
+}
+
+func socks5Handshake(conn net.Conn, u *url.URL, addr string) (net.Conn, error) {
+	host, _port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(_port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port '%s'", _port)
+	}
+
+	// Negotiate the authentication method.
+	buf := make([]byte, 0, 512)
+	if u.User != nil {
+		buf = append(buf, 5, 2, 0, 2)
+	} else {
+		buf = append(buf, 5, 1, 0)
+	}
+	if _, err = conn.Write(buf); err != nil {
+		return nil, err
+	}
+
+	buf = buf[:2]
+	if _, err = io.ReadFull(conn, buf); err != nil {
+		return nil, err
+	} else if buf[0] != 5 {
+		return nil, errors.New("invalid socks5 version")
+	}
+
+	switch buf[1] {
+	case 0:
+	case 2:
+		if u.User == nil {
+			return nil, errors.New("socks5 requires the authentication")
+		}
+
+		user := u.User.Username()
+		pass, _ := u.User.Password()
+		if len(user) > 255 || len(pass) > 255 {
+			return nil, errors.New("the socks5 username or password is too long")
+		}
+
+		buf = append(buf[:0], 1, byte(len(user)))
+		buf = append(buf, user...)
+		buf = append(buf, byte(len(pass)))
+		buf = append(buf, pass...)
+		if _, err = conn.Write(buf); err != nil {
+			return nil, err
+		}
+
+		buf = buf[:2]
+		if _, err = io.ReadFull(conn, buf); err != nil {
+			return nil, err
+		} else if buf[1] != 0 {
+			return nil, errors.New("socks5 authentication failed")
+		}
+	default:
+		return nil, errors.New("no acceptable socks5 authentication method")
+	}
+
+	// Send the CONNECT request.
+	buf = append(buf[:0], 5, 1, 0)
+	if ip := net.ParseIP(host); ip == nil {
+		if len(host) > 255 {
+			return nil, errors.New("the host is too long")
+		}
+		buf = append(buf, 3, byte(len(host)))
+		buf = append(buf, host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		buf = append(buf, 1)
+		buf = append(buf, ip4...)
+	} else {
+		buf = append(buf, 4)
+		buf = append(buf, ip.To16()...)
+	}
+	buf = append(buf, byte(port>>8), byte(port))
+	if _, err = conn.Write(buf); err != nil {
+		return nil, err
+	}
+
+	// Read the reply, and discard the bound address.
+	buf = buf[:4]
+	if _, err = io.ReadFull(conn, buf); err != nil {
+		return nil, err
+	} else if buf[1] != 0 {
+		return nil, fmt.Errorf("socks5 connect failed with the code %d", buf[1])
+	}
+
+	var n int
+	switch buf[3] {
+	case 1:
+		n = net.IPv4len
+	case 4:
+		n = net.IPv6len
+	case 3:
+		if _, err = io.ReadFull(conn, buf[:1]); err != nil {
+			return nil, err
+		}
+		n = int(buf[0])
+	default:
+		return nil, errors.New("invalid socks5 address type")
+	}
+
+	if _, err = io.ReadFull(conn, buf[:n+2]); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+func httpConnectHandshake(conn net.Conn, u *url.URL, addr string) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if u.User != nil {
+		pass, _ := u.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + pass))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, err
+	}
+	// Don't read the body, which is the tunnel for CONNECT.
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http connect failed: %s", resp.Status)
+	}
+
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn reads the data buffered by the reader first.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
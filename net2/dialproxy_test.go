@@ -0,0 +1,153 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func socks5Server(t *testing.T, user, pass string) net.Listener {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 256)
+				io.ReadFull(conn, buf[:2])
+				io.ReadFull(conn, buf[:buf[1]])
+				if user == "" {
+					conn.Write([]byte{5, 0})
+				} else {
+					conn.Write([]byte{5, 2})
+					io.ReadFull(conn, buf[:2])
+					u := make([]byte, buf[1])
+					io.ReadFull(conn, u)
+					io.ReadFull(conn, buf[:1])
+					p := make([]byte, buf[0])
+					io.ReadFull(conn, p)
+					if string(u) != user || string(p) != pass {
+						conn.Write([]byte{1, 1})
+						return
+					}
+					conn.Write([]byte{1, 0})
+				}
+
+				io.ReadFull(conn, buf[:4])
+				var host string
+				switch buf[3] {
+				case 1:
+					io.ReadFull(conn, buf[:4])
+					host = net.IP(buf[:4]).String()
+				case 3:
+					io.ReadFull(conn, buf[:1])
+					io.ReadFull(conn, buf[1:1+buf[0]])
+					host = string(buf[1 : 1+buf[0]])
+				}
+				io.ReadFull(conn, buf[:2])
+				port := binary.BigEndian.Uint16(buf[:2])
+
+				backend, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))))
+				if err != nil {
+					conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+					return
+				}
+				defer backend.Close()
+
+				conn.Write([]byte{5, 0, 0, 1, 127, 0, 0, 1, 0, 0})
+				Pipe(context.Background(), conn, backend, 0)
+			}()
+		}
+	}()
+
+	return ln
+}
+
+func testDialProxy(t *testing.T, proxy, addr string) {
+	conn, err := DialProxy(context.Background(), proxy, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 3)
+	conn.Write([]byte("abc"))
+	if _, err = io.ReadFull(conn, buf); err != nil || string(buf) != "abc" {
+		t.Errorf("buf=%s, err=%v", string(buf), err)
+	}
+}
+
+func TestDialProxy(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(echoHandler)
+	go s.Serve(ln)
+	defer s.Stop()
+	addr := ln.Addr().String()
+
+	socks1 := socks5Server(t, "", "")
+	defer socks1.Close()
+	testDialProxy(t, "socks5://"+socks1.Addr().String(), addr)
+
+	socks2 := socks5Server(t, "user", "pass")
+	defer socks2.Close()
+	testDialProxy(t, "socks5://user:pass@"+socks2.Addr().String(), addr)
+	if _, err = DialProxy(context.Background(), "socks5://"+socks2.Addr().String(), addr); err == nil {
+		t.Error("expect an error without the authentication")
+	}
+
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect || r.Header.Get("Proxy-Authorization") == "" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+
+		backend, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer backend.Close()
+
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		defer conn.Close()
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		Pipe(context.Background(), conn, backend, 0)
+	}))
+	defer hs.Close()
+
+	proxy := "http://user:pass@" + hs.Listener.Addr().String()
+	testDialProxy(t, proxy, addr)
+	if _, err = DialProxy(context.Background(), "http://"+hs.Listener.Addr().String(), addr); err == nil {
+		t.Error("expect an error without the authentication")
+	}
+}
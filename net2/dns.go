@@ -0,0 +1,114 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// DNSResolver is a DNS resolver with the timeout, which may direct
+// the lookups at the specific DNS server.
+type DNSResolver struct {
+	// Timeout is the timeout of each lookup. 0 means no timeout.
+	Timeout time.Duration
+
+	// Resolver is the underlying resolver.
+	Resolver *net.Resolver
+}
+
+// NewDNSResolver returns a new DNSResolver.
+//
+// If server is empty, use the default resolver of the system. Or, it's
+// the address of the DNS server, such as "8.8.8.8" or "8.8.8.8:53",
+// and the default port is 53.
+func NewDNSResolver(server string, timeout time.Duration) *DNSResolver {
+	resolver := net.DefaultResolver
+	if server != "" {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, "53")
+		}
+
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, server)
+			},
+		}
+	}
+	return &DNSResolver{Timeout: timeout, Resolver: resolver}
+}
+
+func (r *DNSResolver) context(ctx context.Context) (context.Context, func()) {
+	if r.Timeout > 0 {
+		return context.WithTimeout(ctx, r.Timeout)
+	}
+	return ctx, func() {}
+}
+
+// LookupHost looks up the host, and returns the ips.
+func (r *DNSResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	ctx, cancel := r.context(ctx)
+	defer cancel()
+	return r.Resolver.LookupHost(ctx, host)
+}
+
+// LookupIP looks up the host, and returns the ips.
+func (r *DNSResolver) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	ctx, cancel := r.context(ctx)
+	defer cancel()
+
+	addrs, err := r.Resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	ips := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		ips[i] = addr.IP
+	}
+	return ips, nil
+}
+
+// LookupSRV looks up the SRV records, which is the same as net.LookupSRV.
+func (r *DNSResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	ctx, cancel := r.context(ctx)
+	defer cancel()
+	return r.Resolver.LookupSRV(ctx, service, proto, name)
+}
+
+// LookupTXT looks up the TXT records of the domain name.
+func (r *DNSResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	ctx, cancel := r.context(ctx)
+	defer cancel()
+	return r.Resolver.LookupTXT(ctx, name)
+}
+
+// LookupHostTimeout looks up the host by the default resolver with timeout.
+func LookupHostTimeout(host string, timeout time.Duration) ([]string, error) {
+	return NewDNSResolver("", timeout).LookupHost(context.Background(), host)
+}
+
+// LookupSRV looks up the SRV records by the default resolver with ctx.
+func LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	return net.DefaultResolver.LookupSRV(ctx, service, proto, name)
+}
+
+// LookupTXT looks up the TXT records by the default resolver with ctx.
+func LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return net.DefaultResolver.LookupTXT(ctx, name)
+}
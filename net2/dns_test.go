@@ -0,0 +1,40 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDNSResolver(t *testing.T) {
+	if ips, err := LookupHostTimeout("localhost", time.Second); err != nil || len(ips) == 0 {
+		t.Errorf("ips=%v, err=%v", ips, err)
+	}
+
+	// Use a fake DNS server that never responds.
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	resolver := NewDNSResolver(conn.LocalAddr().String(), time.Millisecond*100)
+	if _, err = resolver.LookupIP(context.Background(), "www.example.com"); err == nil {
+		t.Error("expect an error")
+	}
+}
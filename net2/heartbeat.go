@@ -0,0 +1,176 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-tools/sync2"
+)
+
+// Heartbeat is the configuration of the heartbeat for the long-lived
+// connection, which writes the ping frame periodically and closes
+// the connection if no data, such as the pong frame, arrives within Timeout.
+type Heartbeat struct {
+	// Ping is the ping frame written to the peer periodically.
+	Ping []byte
+
+	// Interval is the interval duration to write the ping frame.
+	// The default is 30s.
+	Interval time.Duration
+
+	// Timeout is the maximum duration that no data arrives.
+	// The default is 3 times of Interval.
+	Timeout time.Duration
+
+	// OnTimeout is called before closing the connection when timeout.
+	OnTimeout func(conn net.Conn)
+}
+
+// NewHeartbeat returns a new Heartbeat.
+func NewHeartbeat(ping []byte, interval, timeout time.Duration) Heartbeat {
+	return Heartbeat{Ping: ping, Interval: interval, Timeout: timeout}
+}
+
+// Wrap is equal to NewHeartbeatConn(conn, h).
+func (h Heartbeat) Wrap(conn net.Conn) *HeartbeatConn {
+	return NewHeartbeatConn(conn, h)
+}
+
+// Middleware returns a middleware to wrap the connection by the heartbeat
+// for the server handler.
+func (h Heartbeat) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, conn net.Conn) {
+			c := NewHeartbeatConn(conn, h)
+			defer c.Close()
+			next.Handle(ctx, c)
+		})
+	}
+}
+
+// Dial wraps the dial function to return the connection with the heartbeat,
+// which may be used as ReconnectingConn.Dial. If dial is nil,
+// use DialTCPByAddr instead.
+//
+// Since the heartbeat closes the underlying connection when timeout,
+// ReconnectingConn will redial a new one.
+func (h Heartbeat) Dial(dial func(addr string) (net.Conn, error)) func(string) (net.Conn, error) {
+	return func(addr string) (net.Conn, error) {
+		var conn net.Conn
+		var err error
+		if dial != nil {
+			conn, err = dial(addr)
+		} else {
+			conn, err = DialTCPByAddr(addr)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		return NewHeartbeatConn(conn, h), nil
+	}
+}
+
+// HeartbeatConn is a connection with the heartbeat.
+//
+// Notice: the peer will receive the ping frames, which should be ignored
+// or responded by the protocol.
+type HeartbeatConn struct {
+	net.Conn
+
+	heartbeat Heartbeat
+	lastRead  sync2.AtomicInt64
+	wlock     sync.Mutex
+	closed    chan struct{}
+	once      sync.Once
+}
+
+// NewHeartbeatConn returns a new HeartbeatConn, which starts a goroutine
+// to write the ping frames until it's closed.
+func NewHeartbeatConn(conn net.Conn, h Heartbeat) *HeartbeatConn {
+	if h.Interval <= 0 {
+		h.Interval = time.Second * 30
+	}
+	if h.Timeout <= 0 {
+		h.Timeout = h.Interval * 3
+	}
+
+	c := &HeartbeatConn{Conn: conn, heartbeat: h, closed: make(chan struct{})}
+	c.lastRead.Set(time.Now().UnixNano())
+	go c.loop()
+	return c
+}
+
+// LastRead returns the last time when reading the data.
+func (c *HeartbeatConn) LastRead() time.Time {
+	return time.Unix(0, c.lastRead.Get())
+}
+
+func (c *HeartbeatConn) loop() {
+	ticker := time.NewTicker(c.heartbeat.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.closed:
+			return
+		case now := <-ticker.C:
+			if now.Sub(c.LastRead()) > c.heartbeat.Timeout {
+				if c.heartbeat.OnTimeout != nil {
+					c.heartbeat.OnTimeout(c.Conn)
+				}
+				c.Close()
+				return
+			}
+
+			if len(c.heartbeat.Ping) > 0 {
+				if _, err := c.Write(c.heartbeat.Ping); err != nil {
+					c.Close()
+					return
+				}
+			}
+		}
+	}
+}
+
+// Read implements the interface io.Reader, which updates the last read time.
+func (c *HeartbeatConn) Read(p []byte) (n int, err error) {
+	if n, err = c.Conn.Read(p); n > 0 {
+		c.lastRead.Set(time.Now().UnixNano())
+	}
+	return
+}
+
+// Write implements the interface io.Writer, which is serialized with
+// the ping frames.
+func (c *HeartbeatConn) Write(p []byte) (n int, err error) {
+	c.wlock.Lock()
+	n, err = c.Conn.Write(p)
+	c.wlock.Unlock()
+	return
+}
+
+// Close stops the heartbeat and closes the underlying connection.
+func (c *HeartbeatConn) Close() (err error) {
+	c.once.Do(func() {
+		close(c.closed)
+		err = c.Conn.Close()
+	})
+	return
+}
@@ -0,0 +1,45 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestHeartbeatConn(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c2.Close()
+
+	var timeout bool
+	hb := NewHeartbeat([]byte("ping"), time.Millisecond*20, time.Millisecond*100)
+	hb.OnTimeout = func(net.Conn) { timeout = true }
+	conn := hb.Wrap(c1)
+
+	// The peer never responds, so the connection is closed when timeout.
+	data, _ := ioutil.ReadAll(c2)
+	if !timeout {
+		t.Error("expect to timeout")
+	}
+	if len(data) == 0 || !bytes.HasPrefix(data, []byte("pingping")) {
+		t.Errorf("unexpected ping frames '%s'", string(data))
+	}
+	if _, err := conn.Write([]byte("abc")); err == nil {
+		t.Error("expect an error after closed")
+	}
+}
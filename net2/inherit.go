@@ -0,0 +1,129 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// listenFdsStart is the first file descriptor passed by systemd.
+const listenFdsStart = 3
+
+// InheritedListeners returns the listeners inherited from the parent
+// process in the style of the systemd socket activation, that's,
+// the environment variable LISTEN_FDS is the number of the file descriptors
+// starting from 3, and LISTEN_PID, if set, must be the current process id.
+//
+// It unsets the environment variables LISTEN_FDS and LISTEN_PID,
+// so the child processes won't inherit them again. If no listeners are
+// inherited, it returns (nil, nil).
+func InheritedListeners() ([]net.Listener, error) {
+	fds := os.Getenv("LISTEN_FDS")
+	if fds == "" {
+		return nil, nil
+	}
+
+	if pid := os.Getenv("LISTEN_PID"); pid != "" {
+		if pid != strconv.Itoa(os.Getpid()) {
+			return nil, nil
+		}
+	}
+
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_PID")
+
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS '%s'", fds)
+	}
+
+	lns := make([]net.Listener, 0, n)
+	for i := 0; i < n; i++ {
+		file := os.NewFile(uintptr(listenFdsStart+i), fmt.Sprintf("listener%d", i))
+		ln, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			for _, ln := range lns {
+				ln.Close()
+			}
+			return nil, err
+		}
+		lns = append(lns, ln)
+	}
+	return lns, nil
+}
+
+// InheritedListenersOrListen returns the inherited listeners
+// if there are, or listens on the addresses by Listen.
+func InheritedListenersOrListen(addrs ...string) ([]net.Listener, error) {
+	lns, err := InheritedListeners()
+	if err != nil || len(lns) > 0 {
+		return lns, err
+	}
+
+	lns = make([]net.Listener, 0, len(addrs))
+	for _, addr := range addrs {
+		ln, err := Listen(addr)
+		if err != nil {
+			for _, ln := range lns {
+				ln.Close()
+			}
+			return nil, err
+		}
+		lns = append(lns, ln)
+	}
+	return lns, nil
+}
+
+// NewChildCommand returns a command to start the new process with the same
+// executable, arguments and stdio, and passes the listeners to it, which
+// may get them by InheritedListeners.
+//
+// The caller should start the command, then stop the current server
+// and wait for the active connections to finish, so that the new binary
+// can be deployed without dropping the connections.
+func NewChildCommand(listeners ...net.Listener) (*exec.Cmd, error) {
+	files := make([]*os.File, 0, len(listeners))
+	for _, ln := range listeners {
+		f, ok := ln.(interface{ File() (*os.File, error) })
+		if !ok {
+			return nil, errors.New("the listener does not support File()")
+		}
+
+		file, err := f.File()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(os.Environ(), fmt.Sprintf("LISTEN_FDS=%d", len(files)))
+	return cmd, nil
+}
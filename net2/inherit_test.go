@@ -0,0 +1,52 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"os"
+	"testing"
+)
+
+func TestInheritedListeners(t *testing.T) {
+	os.Unsetenv("LISTEN_FDS")
+	if lns, err := InheritedListeners(); err != nil || lns != nil {
+		t.Errorf("lns=%v, err=%v", lns, err)
+	}
+
+	os.Setenv("LISTEN_FDS", "1")
+	os.Setenv("LISTEN_PID", "0")
+	if lns, err := InheritedListeners(); err != nil || lns != nil {
+		t.Errorf("lns=%v, err=%v", lns, err)
+	}
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_PID")
+
+	ln, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	cmd, err := NewChildCommand(ln)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range cmd.ExtraFiles {
+		file.Close()
+	}
+	if len(cmd.ExtraFiles) != 1 || cmd.Env[len(cmd.Env)-1] != "LISTEN_FDS=1" {
+		t.Errorf("files=%d, env=%v", len(cmd.ExtraFiles), cmd.Env)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"net"
+)
+
+// Middleware is used to wrap the handler to do something before or after
+// handling the connection, such as logging, authentication, metrics, etc.
+type Middleware func(Handler) Handler
+
+// Chain wraps the handler by the middlewares, and returns the new handler.
+//
+// The middlewares are executed in the order that they are given,
+// that's, the first middleware is the outermost.
+func Chain(handler Handler, mws ...Middleware) Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		handler = mws[i](handler)
+	}
+	return handler
+}
+
+// Recover returns a middleware to recover the panic of the handler,
+// then call the function handlePanic with the connection and the panic value
+// if it's not nil.
+func Recover(handlePanic func(conn net.Conn, v interface{})) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, conn net.Conn) {
+			defer func() {
+				if v := recover(); v != nil && handlePanic != nil {
+					handlePanic(conn, v)
+				}
+			}()
+			next.Handle(ctx, conn)
+		})
+	}
+}
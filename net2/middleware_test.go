@@ -0,0 +1,49 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+func ExampleChain() {
+	newMiddleware := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return HandlerFunc(func(ctx context.Context, conn net.Conn) {
+				fmt.Printf("before %s\n", name)
+				next.Handle(ctx, conn)
+				fmt.Printf("after %s\n", name)
+			})
+		}
+	}
+
+	handler := HandlerFunc(func(ctx context.Context, conn net.Conn) {
+		fmt.Println("handle")
+		panic("panic")
+	})
+
+	handlePanic := func(conn net.Conn, v interface{}) { fmt.Printf("recover: %v\n", v) }
+	h := Chain(handler, newMiddleware("m1"), Recover(handlePanic), newMiddleware("m2"))
+	h.Handle(context.Background(), nil)
+
+	// Output:
+	// before m1
+	// before m2
+	// handle
+	// recover: panic
+	// after m1
+}
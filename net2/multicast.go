@@ -0,0 +1,147 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"errors"
+	"net"
+)
+
+var errSockoptNotSupported = errors.New("the socket option is not supported")
+
+// MulticastOptions is the options to send the multicast
+// or broadcast packets.
+type MulticastOptions struct {
+	// TTL is the time-to-live of the packets, which is the hop limit
+	// for IPv6. The default is 0, which means to use the system default,
+	// that's, 1 for the multicast generally.
+	TTL int
+
+	// Loopback reports whether to loop the multicast packets back
+	// to the local host.
+	//
+	// Notice: it is ignored on the platforms not supporting to set
+	// the socket option, such as Windows, which uses the system default.
+	Loopback bool
+
+	// Interface is the name of the network interface to send
+	// the packets. If empty, it's chosen by the system.
+	Interface string
+}
+
+// JoinMulticast joins the multicast group address, such as "239.0.0.1:9999",
+// on the network interface iface, and returns the connection ready to read
+// the packets sent to the group.
+//
+// If iface is empty, use the default interface.
+func JoinMulticast(group, iface string) (*net.UDPConn, error) {
+	gaddr, err := net.ResolveUDPAddr("udp", group)
+	if err != nil {
+		return nil, err
+	} else if !gaddr.IP.IsMulticast() {
+		return nil, errors.New("not a multicast address")
+	}
+
+	var ifi *net.Interface
+	if iface != "" {
+		if ifi, err = net.InterfaceByName(iface); err != nil {
+			return nil, err
+		}
+	}
+
+	return net.ListenMulticastUDP("udp", ifi, gaddr)
+}
+
+func dialUDP(addr string, opts MulticastOptions) (*net.UDPConn, error) {
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var laddr *net.UDPAddr
+	if opts.Interface != "" {
+		ips, err := GetIP(opts.Interface)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ip := range ips {
+			if _ip := net.ParseIP(ip); (_ip.To4() != nil) == (raddr.IP.To4() != nil) {
+				laddr = &net.UDPAddr{IP: _ip}
+				break
+			}
+		}
+	}
+
+	conn, err := net.DialUDP("udp", laddr, raddr)
+	if err != nil {
+		return nil, err
+	}
+
+	if raddr.IP.IsMulticast() {
+		if err = SetMulticastLoopback(conn, opts.Loopback); err == errSockoptNotSupported {
+			err = nil // Loopback is best-effort.
+		}
+		if err == nil && opts.TTL > 0 {
+			err = SetMulticastTTL(conn, opts.TTL)
+		}
+	} else if opts.TTL > 0 {
+		err = SetTTL(conn, opts.TTL)
+	}
+
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// DialMulticast returns a connection to send the packets
+// to the multicast group address with the options.
+func DialMulticast(group string, opts MulticastOptions) (*net.UDPConn, error) {
+	return dialUDP(group, opts)
+}
+
+// SendMulticast sends a packet to the multicast group address.
+func SendMulticast(group string, data []byte, opts MulticastOptions) error {
+	conn, err := dialUDP(group, opts)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write(data)
+	return err
+}
+
+// SendBroadcast sends a packet to the limited broadcast address
+// "255.255.255.255:port". Only TTL and Interface of opts are used.
+func SendBroadcast(port int, data []byte, opts MulticastOptions) error {
+	return SendBroadcastTo(JoinHostPort("255.255.255.255", port), data, opts)
+}
+
+// SendBroadcastTo is the same as SendBroadcast, but sends the packet
+// to the broadcast address addr, such as "192.168.1.255:9999".
+func SendBroadcastTo(addr string, data []byte, opts MulticastOptions) error {
+	opts.Loopback = false
+	conn, err := dialUDP(addr, opts)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write(data)
+	return err
+}
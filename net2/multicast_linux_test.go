@@ -0,0 +1,36 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import "testing"
+
+func TestMulticast(t *testing.T) {
+	if _, err := JoinMulticast("127.0.0.1:9999", ""); err == nil {
+		t.Error("expect an error for the non-multicast address")
+	}
+
+	conn, err := DialMulticast("239.255.0.1:9999", MulticastOptions{TTL: 2, Loopback: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err = SetMulticastLoopback(conn, false); err != nil {
+		t.Error(err)
+	}
+	if err = SetTTL(conn, 64); err != nil {
+		t.Error(err)
+	}
+}
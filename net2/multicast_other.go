@@ -0,0 +1,35 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package net2
+
+import "net"
+
+// SetTTL is not supported on the current platform.
+func SetTTL(conn *net.UDPConn, ttl int) error {
+	return errSockoptNotSupported
+}
+
+// SetMulticastTTL is not supported on the current platform.
+func SetMulticastTTL(conn *net.UDPConn, ttl int) error {
+	return errSockoptNotSupported
+}
+
+// SetMulticastLoopback is not supported on the current platform.
+func SetMulticastLoopback(conn *net.UDPConn, enable bool) error {
+	return errSockoptNotSupported
+}
@@ -0,0 +1,80 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package net2
+
+import (
+	"net"
+	"syscall"
+)
+
+func setUDPSockopt(conn *net.UDPConn, f func(fd int, ipv4 bool) error) error {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	ipv4 := true
+	if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok && addr.IP.To4() == nil &&
+		!addr.IP.IsUnspecified() {
+		ipv4 = false
+	}
+
+	cerr := raw.Control(func(fd uintptr) { err = f(int(fd), ipv4) })
+	if cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// SetTTL sets the time-to-live of the unicast or broadcast packets
+// sent by conn, which is the hop limit for IPv6.
+func SetTTL(conn *net.UDPConn, ttl int) error {
+	return setUDPSockopt(conn, func(fd int, ipv4 bool) error {
+		if ipv4 {
+			return syscall.SetsockoptInt(fd, syscall.IPPROTO_IP, syscall.IP_TTL, ttl)
+		}
+		return syscall.SetsockoptInt(fd, syscall.IPPROTO_IPV6, syscall.IPV6_UNICAST_HOPS, ttl)
+	})
+}
+
+// SetMulticastTTL sets the time-to-live of the multicast packets
+// sent by conn, which is the hop limit for IPv6.
+func SetMulticastTTL(conn *net.UDPConn, ttl int) error {
+	return setUDPSockopt(conn, func(fd int, ipv4 bool) error {
+		if ipv4 {
+			return setIPv4MulticastOpt(fd, syscall.IP_MULTICAST_TTL, ttl)
+		}
+		return syscall.SetsockoptInt(fd, syscall.IPPROTO_IPV6, syscall.IPV6_MULTICAST_HOPS, ttl)
+	})
+}
+
+// SetMulticastLoopback sets whether to loop the multicast packets
+// sent by conn back to the local host.
+func SetMulticastLoopback(conn *net.UDPConn, enable bool) error {
+	var v int
+	if enable {
+		v = 1
+	}
+
+	return setUDPSockopt(conn, func(fd int, ipv4 bool) error {
+		if ipv4 {
+			return setIPv4MulticastOpt(fd, syscall.IP_MULTICAST_LOOP, v)
+		}
+		return syscall.SetsockoptInt(fd, syscall.IPPROTO_IPV6, syscall.IPV6_MULTICAST_LOOP, v)
+	})
+}
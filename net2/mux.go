@@ -0,0 +1,567 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-tools/pools"
+)
+
+// Predefine some errors about the multiplexing.
+var (
+	ErrSessionClosed = errors.New("the mux session has been closed")
+	ErrStreamClosed  = errors.New("the mux stream has been closed")
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+const (
+	muxVersion    = 1
+	muxHeaderSize = 8
+)
+
+const (
+	muxCmdSYN byte = iota // Open a new stream.
+	muxCmdFIN             // Close the stream.
+	muxCmdPSH             // Push the data.
+	muxCmdUPD             // Update the send window by the delta.
+)
+
+// MuxConfig is the configuration of the mux session.
+//
+// Notice: both sides of the session should use the same WindowSize.
+type MuxConfig struct {
+	// MaxFrameSize is the maximum size of the data in a frame,
+	// which must be no more than 65535. The default is 32KB.
+	MaxFrameSize int
+
+	// WindowSize is the size of the receive window of each stream,
+	// that's, the maximum number of the bytes that the peer can send
+	// without being read. If the peer exceeds it, the session will be
+	// closed. The default is 256KB.
+	WindowSize int
+
+	// AcceptBacklog is the maximum number of the streams waiting
+	// to be accepted. The default is 1024.
+	AcceptBacklog int
+}
+
+func (c *MuxConfig) init() {
+	if c.MaxFrameSize <= 0 || c.MaxFrameSize > 65535 {
+		c.MaxFrameSize = 32 * 1024
+	}
+	if c.WindowSize <= 0 {
+		c.WindowSize = 256 * 1024
+	}
+	if c.AcceptBacklog <= 0 {
+		c.AcceptBacklog = 1024
+	}
+}
+
+// MuxSession multiplexes several independent streams with the flow control
+// over a single connection, such as the TCP connection.
+//
+// The frame format is "VERSION(1)|CMD(1)|LENGTH(2)|STREAMID(4)|DATA(LENGTH)",
+// and the integers are encoded in big endian.
+//
+// MuxSession implements the interface net.Listener, so it may be served
+// by Server to handle the streams opened by the peer.
+type MuxSession struct {
+	conn   net.Conn
+	config MuxConfig
+
+	lock    sync.Mutex
+	nextID  uint32
+	streams map[uint32]*MuxStream
+	accepts chan *MuxStream
+
+	wlock  sync.Mutex
+	closed chan struct{}
+	once   sync.Once
+	err    error
+}
+
+// NewMuxClient returns a new client-side mux session over conn.
+// If config is nil, use the default.
+func NewMuxClient(conn net.Conn, config *MuxConfig) *MuxSession {
+	return newMuxSession(conn, config, 1)
+}
+
+// NewMuxServer returns a new server-side mux session over conn.
+// If config is nil, use the default.
+func NewMuxServer(conn net.Conn, config *MuxConfig) *MuxSession {
+	return newMuxSession(conn, config, 2)
+}
+
+func newMuxSession(conn net.Conn, config *MuxConfig, nextID uint32) *MuxSession {
+	var c MuxConfig
+	if config != nil {
+		c = *config
+	}
+	c.init()
+
+	s := &MuxSession{
+		conn:    conn,
+		config:  c,
+		nextID:  nextID,
+		streams: make(map[uint32]*MuxStream),
+		accepts: make(chan *MuxStream, c.AcceptBacklog),
+		closed:  make(chan struct{}),
+	}
+	go s.recvLoop()
+	return s
+}
+
+// NumStreams returns the number of the active streams.
+func (s *MuxSession) NumStreams() int {
+	s.lock.Lock()
+	n := len(s.streams)
+	s.lock.Unlock()
+	return n
+}
+
+// IsClosed reports whether the session has been closed.
+func (s *MuxSession) IsClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Err returns the error why the session is closed.
+func (s *MuxSession) Err() error {
+	if !s.IsClosed() {
+		return nil
+	}
+	return s.err
+}
+
+// Close closes the session, all the streams and the underlying connection.
+func (s *MuxSession) Close() error {
+	return s.close(ErrSessionClosed)
+}
+
+func (s *MuxSession) close(err error) (e error) {
+	s.once.Do(func() {
+		s.err = err
+		close(s.closed)
+		e = s.conn.Close()
+
+		s.lock.Lock()
+		for _, stream := range s.streams {
+			stream.notify(stream.readEvent)
+			stream.notify(stream.writeEvent)
+		}
+		s.streams = map[uint32]*MuxStream{}
+		s.lock.Unlock()
+	})
+	return
+}
+
+// OpenStream opens a new stream.
+func (s *MuxSession) OpenStream() (*MuxStream, error) {
+	if s.IsClosed() {
+		return nil, ErrSessionClosed
+	}
+
+	s.lock.Lock()
+	id := s.nextID
+	s.nextID += 2
+	stream := newMuxStream(s, id)
+	s.streams[id] = stream
+	s.lock.Unlock()
+
+	if err := s.writeFrame(muxCmdSYN, id, nil); err != nil {
+		s.removeStream(id)
+		return nil, err
+	}
+	return stream, nil
+}
+
+// AcceptStream waits for and returns the next stream opened by the peer.
+func (s *MuxSession) AcceptStream() (*MuxStream, error) {
+	select {
+	case stream := <-s.accepts:
+		return stream, nil
+	case <-s.closed:
+		return nil, ErrSessionClosed
+	}
+}
+
+// Accept implements the interface net.Listener, which is equal to
+// AcceptStream.
+func (s *MuxSession) Accept() (net.Conn, error) {
+	stream, err := s.AcceptStream()
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// Addr implements the interface net.Listener, which returns the local
+// address of the underlying connection.
+func (s *MuxSession) Addr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+func (s *MuxSession) removeStream(id uint32) {
+	s.lock.Lock()
+	delete(s.streams, id)
+	s.lock.Unlock()
+}
+
+func (s *MuxSession) getStream(id uint32) *MuxStream {
+	s.lock.Lock()
+	stream := s.streams[id]
+	s.lock.Unlock()
+	return stream
+}
+
+func (s *MuxSession) writeFrame(cmd byte, id uint32, data []byte) error {
+	buf := pools.GetBytes(muxHeaderSize + len(data))
+	defer pools.PutBytes(buf)
+
+	buf[0] = muxVersion
+	buf[1] = cmd
+	binary.BigEndian.PutUint16(buf[2:], uint16(len(data)))
+	binary.BigEndian.PutUint32(buf[4:], id)
+	copy(buf[muxHeaderSize:], data)
+
+	s.wlock.Lock()
+	defer s.wlock.Unlock()
+
+	if s.IsClosed() {
+		return ErrSessionClosed
+	}
+
+	if _, err := s.conn.Write(buf); err != nil {
+		s.close(err)
+		return err
+	}
+	return nil
+}
+
+func (s *MuxSession) recvLoop() {
+	header := make([]byte, muxHeaderSize)
+	for {
+		if _, err := io.ReadFull(s.conn, header); err != nil {
+			s.close(err)
+			return
+		} else if header[0] != muxVersion {
+			s.close(fmt.Errorf("invalid mux version %d", header[0]))
+			return
+		}
+
+		cmd := header[1]
+		length := int(binary.BigEndian.Uint16(header[2:]))
+		id := binary.BigEndian.Uint32(header[4:])
+
+		var data []byte
+		if length > 0 {
+			data = make([]byte, length)
+			if _, err := io.ReadFull(s.conn, data); err != nil {
+				s.close(err)
+				return
+			}
+		}
+
+		switch cmd {
+		case muxCmdSYN:
+			s.lock.Lock()
+			_, exist := s.streams[id]
+			stream := newMuxStream(s, id)
+			if !exist {
+				s.streams[id] = stream
+			}
+			s.lock.Unlock()
+
+			if !exist {
+				select {
+				case s.accepts <- stream:
+				default: // The backlog is full.
+					s.removeStream(id)
+					s.writeFrame(muxCmdFIN, id, nil)
+				}
+			}
+
+		case muxCmdFIN:
+			if stream := s.getStream(id); stream != nil {
+				stream.lock.Lock()
+				stream.eof = true
+				stream.lock.Unlock()
+				stream.notify(stream.readEvent)
+				stream.notify(stream.writeEvent)
+			}
+
+		case muxCmdPSH:
+			if stream := s.getStream(id); stream != nil && length > 0 {
+				stream.lock.Lock()
+				// The bytes consumed but not updated to the peer still
+				// occupy the receive window.
+				overflow := len(stream.buffer)+stream.consumed+length > s.config.WindowSize
+				if !overflow {
+					stream.buffer = append(stream.buffer, data...)
+				}
+				stream.lock.Unlock()
+
+				if overflow {
+					s.close(fmt.Errorf("mux stream %d exceeds the receive window", id))
+					return
+				}
+				stream.notify(stream.readEvent)
+			}
+
+		case muxCmdUPD:
+			if length != 4 {
+				s.close(errors.New("invalid mux window update frame"))
+				return
+			}
+
+			if stream := s.getStream(id); stream != nil {
+				stream.lock.Lock()
+				stream.sendWindow += int(binary.BigEndian.Uint32(data))
+				stream.lock.Unlock()
+				stream.notify(stream.writeEvent)
+			}
+
+		default:
+			s.close(fmt.Errorf("invalid mux command %d", cmd))
+			return
+		}
+	}
+}
+
+// MuxStream is a stream of the mux session, which implements
+// the interface net.Conn.
+type MuxStream struct {
+	id      uint32
+	session *MuxSession
+
+	lock       sync.Mutex
+	buffer     []byte
+	consumed   int
+	sendWindow int
+	eof        bool // The peer has closed the stream.
+	closed     bool
+
+	readEvent     chan struct{}
+	writeEvent    chan struct{}
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+func newMuxStream(s *MuxSession, id uint32) *MuxStream {
+	return &MuxStream{
+		id:         id,
+		session:    s,
+		sendWindow: s.config.WindowSize,
+		readEvent:  make(chan struct{}, 1),
+		writeEvent: make(chan struct{}, 1),
+	}
+}
+
+func (s *MuxStream) notify(event chan struct{}) {
+	select {
+	case event <- struct{}{}:
+	default:
+	}
+}
+
+// ID returns the id of the stream.
+func (s *MuxStream) ID() uint32 {
+	return s.id
+}
+
+// Session returns the session that the stream belongs to.
+func (s *MuxStream) Session() *MuxSession {
+	return s.session
+}
+
+func (s *MuxStream) wait(event chan struct{}, deadline time.Time) error {
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		d := time.Until(deadline)
+		if d <= 0 {
+			return timeoutError{}
+		}
+
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-event:
+		return nil
+	case <-s.session.closed:
+		return nil
+	case <-timeout:
+		return timeoutError{}
+	}
+}
+
+// Read implements the interface net.Conn.
+func (s *MuxStream) Read(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for {
+		s.lock.Lock()
+		if s.closed {
+			s.lock.Unlock()
+			return 0, ErrStreamClosed
+		}
+
+		if len(s.buffer) > 0 {
+			n = copy(p, s.buffer)
+			s.buffer = s.buffer[n:]
+			s.consumed += n
+
+			var delta int
+			if s.consumed >= s.session.config.WindowSize/2 {
+				delta, s.consumed = s.consumed, 0
+			}
+			s.lock.Unlock()
+
+			if delta > 0 {
+				var data [4]byte
+				binary.BigEndian.PutUint32(data[:], uint32(delta))
+				s.session.writeFrame(muxCmdUPD, s.id, data[:])
+			}
+			return n, nil
+		}
+
+		eof, deadline := s.eof, s.readDeadline
+		s.lock.Unlock()
+
+		if eof {
+			return 0, io.EOF
+		} else if s.session.IsClosed() {
+			return 0, ErrSessionClosed
+		} else if err = s.wait(s.readEvent, deadline); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Write implements the interface net.Conn.
+func (s *MuxStream) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		s.lock.Lock()
+		if s.closed {
+			s.lock.Unlock()
+			return n, ErrStreamClosed
+		} else if s.eof {
+			s.lock.Unlock()
+			return n, io.ErrClosedPipe
+		}
+
+		size := s.sendWindow
+		deadline := s.writeDeadline
+		if size > len(p) {
+			size = len(p)
+		}
+		if size > s.session.config.MaxFrameSize {
+			size = s.session.config.MaxFrameSize
+		}
+		s.sendWindow -= size
+		s.lock.Unlock()
+
+		if size == 0 {
+			if s.session.IsClosed() {
+				return n, ErrSessionClosed
+			} else if err = s.wait(s.writeEvent, deadline); err != nil {
+				return n, err
+			}
+			continue
+		}
+
+		if err = s.session.writeFrame(muxCmdPSH, s.id, p[:size]); err != nil {
+			return n, err
+		}
+		n += size
+		p = p[size:]
+	}
+	return n, nil
+}
+
+// Close implements the interface net.Conn, which closes the stream
+// and notifies the peer.
+func (s *MuxStream) Close() error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.lock.Unlock()
+
+	s.notify(s.readEvent)
+	s.notify(s.writeEvent)
+	s.session.removeStream(s.id)
+	if s.session.IsClosed() {
+		return nil
+	}
+	return s.session.writeFrame(muxCmdFIN, s.id, nil)
+}
+
+// LocalAddr implements the interface net.Conn.
+func (s *MuxStream) LocalAddr() net.Addr {
+	return s.session.conn.LocalAddr()
+}
+
+// RemoteAddr implements the interface net.Conn.
+func (s *MuxStream) RemoteAddr() net.Addr {
+	return s.session.conn.RemoteAddr()
+}
+
+// SetDeadline implements the interface net.Conn.
+func (s *MuxStream) SetDeadline(t time.Time) error {
+	s.SetReadDeadline(t)
+	s.SetWriteDeadline(t)
+	return nil
+}
+
+// SetReadDeadline implements the interface net.Conn.
+func (s *MuxStream) SetReadDeadline(t time.Time) error {
+	s.lock.Lock()
+	s.readDeadline = t
+	s.lock.Unlock()
+	s.notify(s.readEvent)
+	return nil
+}
+
+// SetWriteDeadline implements the interface net.Conn.
+func (s *MuxStream) SetWriteDeadline(t time.Time) error {
+	s.lock.Lock()
+	s.writeDeadline = t
+	s.lock.Unlock()
+	s.notify(s.writeEvent)
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMux(t *testing.T) {
+	c1, c2 := net.Pipe()
+	config := &MuxConfig{MaxFrameSize: 1024, WindowSize: 4096}
+	client := NewMuxClient(c1, config)
+	server := NewMuxServer(c2, config)
+	defer client.Close()
+
+	s := NewServer(echoHandler)
+	go s.Serve(server)
+	defer s.Stop()
+
+	// The data is larger than the window, so the flow control works.
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			stream, err := client.OpenStream()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer stream.Close()
+
+			go stream.Write(data)
+			buf := make([]byte, len(data))
+			if _, err = io.ReadFull(stream, buf); err != nil {
+				t.Error(err)
+			} else if !bytes.Equal(buf, data) {
+				t.Errorf("stream %d: the echoed data is not equal", stream.ID())
+			}
+		}()
+	}
+	wg.Wait()
+
+	stream, err := client.OpenStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.SetReadDeadline(time.Now().Add(time.Millisecond * 10))
+	if _, err = stream.Read(make([]byte, 1)); !isTimeout(err) {
+		t.Errorf("expect a timeout error, but got %v", err)
+	}
+	stream.Close()
+
+	client.Close()
+	if _, err = client.OpenStream(); err != ErrSessionClosed {
+		t.Errorf("expect ErrSessionClosed, but got %v", err)
+	}
+}
+
+func TestMuxExceedWindow(t *testing.T) {
+	c1, c2 := net.Pipe()
+	server := NewMuxServer(c2, &MuxConfig{WindowSize: 1024})
+	defer server.Close()
+
+	frame := func(cmd byte, id uint32, data []byte) []byte {
+		buf := make([]byte, muxHeaderSize+len(data))
+		buf[0], buf[1] = muxVersion, cmd
+		binary.BigEndian.PutUint16(buf[2:], uint16(len(data)))
+		binary.BigEndian.PutUint32(buf[4:], id)
+		copy(buf[muxHeaderSize:], data)
+		return buf
+	}
+
+	// The misbehaving peer ignores the receive window.
+	go io.Copy(ioutil.Discard, c1)
+	c1.Write(frame(muxCmdSYN, 1, nil))
+	c1.Write(frame(muxCmdPSH, 1, make([]byte, 600)))
+	c1.Write(frame(muxCmdPSH, 1, make([]byte, 600)))
+
+	select {
+	case <-server.closed:
+	case <-time.After(time.Second):
+		t.Fatal("the session is not closed")
+	}
+	if !server.IsClosed() {
+		t.Error("expect the session to be closed")
+	} else if err := server.Err(); err == nil || err.Error() != "mux stream 1 exceeds the receive window" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-tools/io2"
+)
+
+// ConnOptions is the options to tune the connection.
+type ConnOptions struct {
+	// KeepAlive is the period of the TCP keepalive.
+	//
+	// 0 means to use the default of the OS, and the negative disables it.
+	KeepAlive time.Duration
+
+	// ReadBuffer and WriteBuffer are the sizes of the receive and send buffers
+	// of the socket, that's, SO_RCVBUF and SO_SNDBUF. 0 means the default.
+	ReadBuffer  int
+	WriteBuffer int
+
+	// DisableNoDelay enables the Nagle's algorithm, which disables TCP_NODELAY.
+	// By default, TCP_NODELAY is enabled.
+	DisableNoDelay bool
+
+	// ReadTimeout and WriteTimeout are the timeouts of each read and write,
+	// which will reset the deadline before each read or write.
+	// 0 means no timeout.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// ReadLimiter and WriteLimiter are used to throttle the bandwidth
+	// of reading and writing, which are shared by all the connections
+	// applied by the options.
+	//
+	// NewLimiters, if set, returns the limiters for each connection,
+	// which override ReadLimiter and WriteLimiter. Either may be nil.
+	// If the returned limiter implements io.Closer, it will be closed
+	// when the connection is closed.
+	ReadLimiter  io2.RateLimiter
+	WriteLimiter io2.RateLimiter
+	NewLimiters  func(conn net.Conn) (read, write io2.RateLimiter)
+}
+
+// Apply applies the options to the connection, and returns the new one.
+//
+// The socket options are set only when conn is a *net.TCPConn.
+func (o ConnOptions) Apply(conn net.Conn) (net.Conn, error) {
+	if tc, ok := conn.(*net.TCPConn); ok {
+		if err := o.setSockopts(tc); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.ReadTimeout > 0 || o.WriteTimeout > 0 {
+		conn = &deadlineConn{Conn: conn, rtimeout: o.ReadTimeout, wtimeout: o.WriteTimeout}
+	}
+
+	rlimiter, wlimiter := o.ReadLimiter, o.WriteLimiter
+	if o.NewLimiters != nil {
+		rlimiter, wlimiter = o.NewLimiters(conn)
+	}
+	if rlimiter != nil || wlimiter != nil {
+		tc := &throttleConn{Conn: conn, rlimiter: rlimiter, wlimiter: wlimiter}
+		if o.NewLimiters != nil {
+			for _, l := range []io2.RateLimiter{rlimiter, wlimiter} {
+				if closer, ok := l.(io.Closer); ok {
+					tc.closers = append(tc.closers, closer)
+				}
+			}
+		}
+		conn = tc
+	}
+
+	return conn, nil
+}
+
+func (o ConnOptions) setSockopts(conn *net.TCPConn) (err error) {
+	if o.KeepAlive > 0 {
+		if err = conn.SetKeepAlive(true); err == nil {
+			err = conn.SetKeepAlivePeriod(o.KeepAlive)
+		}
+	} else if o.KeepAlive < 0 {
+		err = conn.SetKeepAlive(false)
+	}
+
+	if err == nil && o.ReadBuffer > 0 {
+		err = conn.SetReadBuffer(o.ReadBuffer)
+	}
+	if err == nil && o.WriteBuffer > 0 {
+		err = conn.SetWriteBuffer(o.WriteBuffer)
+	}
+	if err == nil && o.DisableNoDelay {
+		err = conn.SetNoDelay(false)
+	}
+	return
+}
+
+type deadlineConn struct {
+	net.Conn
+	rtimeout time.Duration
+	wtimeout time.Duration
+}
+
+func (c *deadlineConn) Read(p []byte) (int, error) {
+	if c.rtimeout > 0 {
+		if err := c.Conn.SetReadDeadline(time.Now().Add(c.rtimeout)); err != nil {
+			return 0, err
+		}
+	}
+	return c.Conn.Read(p)
+}
+
+func (c *deadlineConn) Write(p []byte) (int, error) {
+	if c.wtimeout > 0 {
+		if err := c.Conn.SetWriteDeadline(time.Now().Add(c.wtimeout)); err != nil {
+			return 0, err
+		}
+	}
+	return c.Conn.Write(p)
+}
+
+func (c *deadlineConn) CloseWrite() error {
+	return closeWrite(c.Conn)
+}
+
+type throttleConn struct {
+	net.Conn
+	rlimiter io2.RateLimiter
+	wlimiter io2.RateLimiter
+	closers  []io.Closer
+	once     sync.Once
+}
+
+func (c *throttleConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() {
+		for _, closer := range c.closers {
+			closer.Close()
+		}
+	})
+	return err
+}
+
+func (c *throttleConn) Read(p []byte) (n int, err error) {
+	if n, err = c.Conn.Read(p); n > 0 && c.rlimiter != nil {
+		c.rlimiter.WaitN(n)
+	}
+	return
+}
+
+func (c *throttleConn) Write(p []byte) (int, error) {
+	if len(p) > 0 && c.wlimiter != nil {
+		c.wlimiter.WaitN(len(p))
+	}
+	return c.Conn.Write(p)
+}
+
+func (c *throttleConn) CloseWrite() error {
+	return closeWrite(c.Conn)
+}
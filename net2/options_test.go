@@ -0,0 +1,85 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-tools/io2"
+)
+
+func TestConnOptions(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(echoHandler)
+	s.ConnOptions = ConnOptions{KeepAlive: time.Minute, ReadBuffer: 8192, ReadTimeout: 50 * time.Millisecond}
+	go s.Serve(ln)
+	defer s.Stop()
+
+	dialer := Dialer{ConnOptions: ConnOptions{DisableNoDelay: true, WriteTimeout: time.Second}}
+	conn, err := dialer.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 3)
+	conn.Write([]byte("abc"))
+	if _, err = io.ReadFull(conn, buf); err != nil || string(buf) != "abc" {
+		t.Errorf("buf=%s, err=%v", string(buf), err)
+	}
+
+	// The server closes the connection after the read timeout.
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err = conn.Read(buf); err != io.EOF {
+		t.Errorf("expect io.EOF, but got %v", err)
+	}
+}
+
+func TestConnOptionsThrottle(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(echoHandler)
+	s.ConnOptions.NewLimiters = func(net.Conn) (io2.RateLimiter, io2.RateLimiter) {
+		return nil, io2.NewBytesLimiter(1000, 100)
+	}
+	go s.Serve(ln)
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	buf := make([]byte, 300)
+	conn.Write(buf)
+	conn.SetReadDeadline(time.Now().Add(time.Second * 2))
+	if _, err = io.ReadFull(conn, buf); err != nil {
+		t.Error(err)
+	} else if cost := time.Since(start); cost < time.Millisecond*150 {
+		t.Errorf("the server writes too fast: %s", cost)
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned when the connection pool has been closed.
+var ErrPoolClosed = errors.New("the connection pool has been closed")
+
+// PoolConn is the connection got from ConnPool.
+type PoolConn struct {
+	net.Conn
+
+	addr     string
+	pool     *addrPool
+	broken   bool
+	returned bool
+}
+
+// Addr returns the address that the connection connects to.
+func (c *PoolConn) Addr() string {
+	return c.addr
+}
+
+// MarkBroken marks the connection broken, so it will be closed
+// instead of being put back to the pool.
+func (c *PoolConn) MarkBroken() {
+	c.broken = true
+}
+
+type idleConn struct {
+	conn *PoolConn
+	time time.Time
+}
+
+type addrPool struct {
+	idles []idleConn
+	slots chan struct{}
+}
+
+// ConnPool is a pool of the client connections keyed by the address.
+type ConnPool struct {
+	// Dial is used to dial a new connection. The default is DialTCPByAddr.
+	Dial func(addr string) (net.Conn, error)
+
+	// MaxIdle is the maximum number of the idle connections per address.
+	// The default is 2.
+	MaxIdle int
+
+	// MaxOpen is the maximum number of the open connections per address.
+	// The default is 0, which means no limit.
+	MaxOpen int
+
+	// IdleTimeout is the maximum duration that a connection can be idle.
+	// 0 means no timeout.
+	IdleTimeout time.Duration
+
+	// HealthCheck is called to check whether the idle connection is healthy
+	// before borrowing it. If it returns an error, the connection is closed
+	// and discarded.
+	HealthCheck func(conn net.Conn) error
+
+	lock   sync.Mutex
+	pools  map[string]*addrPool
+	done   chan struct{}
+	closed bool
+}
+
+// NewConnPool returns a new ConnPool.
+func NewConnPool(maxIdle, maxOpen int, idleTimeout time.Duration) *ConnPool {
+	return &ConnPool{MaxIdle: maxIdle, MaxOpen: maxOpen, IdleTimeout: idleTimeout}
+}
+
+func (p *ConnPool) getPool(addr string) (*addrPool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+
+	if p.pools == nil {
+		p.pools = make(map[string]*addrPool)
+	}
+	if p.done == nil {
+		p.done = make(chan struct{})
+	}
+
+	pool, ok := p.pools[addr]
+	if !ok {
+		pool = &addrPool{}
+		if p.MaxOpen > 0 {
+			pool.slots = make(chan struct{}, p.MaxOpen)
+		}
+		p.pools[addr] = pool
+	}
+	return pool, nil
+}
+
+func (p *ConnPool) popIdle(pool *addrPool) *PoolConn {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for len(pool.idles) > 0 {
+		idle := pool.idles[len(pool.idles)-1]
+		pool.idles = pool.idles[:len(pool.idles)-1]
+		if p.IdleTimeout > 0 && time.Since(idle.time) > p.IdleTimeout {
+			idle.conn.Conn.Close()
+			p.release(pool)
+			continue
+		}
+		idle.conn.returned = false
+		return idle.conn
+	}
+	return nil
+}
+
+// release gives back a MaxOpen slot of the pool. It never blocks.
+func (p *ConnPool) release(pool *addrPool) {
+	if pool.slots != nil {
+		select {
+		case <-pool.slots:
+		default:
+		}
+	}
+}
+
+// Get is equal to GetContext(context.Background(), addr).
+func (p *ConnPool) Get(addr string) (*PoolConn, error) {
+	return p.GetContext(context.Background(), addr)
+}
+
+// GetContext returns an idle connection to addr, or dials a new one.
+//
+// If the number of the open connections reaches MaxOpen, it will wait
+// until a connection is put back, ctx is done or the pool is closed.
+func (p *ConnPool) GetContext(ctx context.Context, addr string) (*PoolConn, error) {
+	pool, err := p.getPool(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		conn := p.popIdle(pool)
+		if conn == nil {
+			break
+		}
+
+		if p.HealthCheck == nil || p.HealthCheck(conn.Conn) == nil {
+			return conn, nil
+		}
+		conn.Conn.Close()
+		p.lock.Lock()
+		p.release(pool)
+		p.lock.Unlock()
+	}
+
+	if pool.slots != nil {
+		select {
+		case pool.slots <- struct{}{}:
+		case <-p.done:
+			return nil, ErrPoolClosed
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	var c net.Conn
+	if p.Dial != nil {
+		c, err = p.Dial(addr)
+	} else {
+		c, err = DialTCPByAddr(addr)
+	}
+
+	if err != nil {
+		p.lock.Lock()
+		p.release(pool)
+		p.lock.Unlock()
+		return nil, err
+	}
+	return &PoolConn{Conn: c, addr: addr, pool: pool}, nil
+}
+
+// Put puts the connection back to the pool.
+//
+// If the connection is marked broken, or the number of the idle connections
+// reaches MaxIdle, or the pool has been closed, it will be closed.
+//
+// Putting the same connection back more than once does nothing.
+func (p *ConnPool) Put(conn *PoolConn) {
+	maxIdle := p.MaxIdle
+	if maxIdle < 1 {
+		maxIdle = 2
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if conn.returned {
+		return
+	}
+	conn.returned = true
+
+	pool := conn.pool
+	if p.closed {
+		conn.Conn.Close()
+		p.release(pool)
+		return
+	}
+
+	if conn.broken || len(pool.idles) >= maxIdle {
+		conn.Conn.Close()
+		p.release(pool)
+		return
+	}
+
+	pool.idles = append(pool.idles, idleConn{conn: conn, time: time.Now()})
+}
+
+// Close closes the pool and all the idle connections.
+func (p *ConnPool) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.closed {
+		return nil
+	}
+
+	p.closed = true
+	if p.done != nil {
+		close(p.done)
+	}
+	for _, pool := range p.pools {
+		for _, idle := range pool.idles {
+			idle.conn.Conn.Close()
+		}
+	}
+	p.pools = nil
+	return nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestConnPool(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(echoHandler)
+	go s.Serve(ln)
+	defer s.Stop()
+
+	addr := ln.Addr().String()
+	pool := NewConnPool(1, 2, 0)
+	defer pool.Close()
+
+	c1, err := pool.Get(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := pool.Get(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reach MaxOpen
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if _, err = pool.GetContext(ctx, addr); err != context.DeadlineExceeded {
+		t.Errorf("expect context.DeadlineExceeded, but got %v", err)
+	}
+
+	pool.Put(c1)
+	pool.Put(c2) // Exceed MaxIdle, so close it.
+	if c, err := pool.Get(addr); err != nil {
+		t.Error(err)
+	} else if c != c1 {
+		t.Error("expect to reuse the idle connection")
+	} else {
+		c.MarkBroken()
+		pool.Put(c)
+	}
+
+	// The health check fails.
+	c3, _ := pool.Get(addr)
+	pool.Put(c3)
+	pool.HealthCheck = func(net.Conn) error { return errors.New("unhealthy") }
+	if c, err := pool.Get(addr); err != nil {
+		t.Error(err)
+	} else if c == c3 {
+		t.Error("expect a new connection")
+	}
+}
+
+func TestConnPoolClose(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(echoHandler)
+	go s.Serve(ln)
+	defer s.Stop()
+
+	addr := ln.Addr().String()
+	pool := NewConnPool(1, 1, 0)
+	c, err := pool.Get(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := pool.Get(addr)
+		errc <- err
+	}()
+
+	time.Sleep(time.Millisecond * 50)
+	pool.Close()
+	select {
+	case err := <-errc:
+		if err != ErrPoolClosed {
+			t.Errorf("expect ErrPoolClosed, but got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the waiter is not woken up after closing the pool")
+	}
+
+	// Put after Close.
+	pool.Put(c)
+	pool.Put(c)
+	if len(c.pool.slots) != 0 {
+		t.Errorf("expect the slot to be released, but got %d", len(c.pool.slots))
+	}
+}
+
+func TestConnPoolPutTwice(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(echoHandler)
+	go s.Serve(ln)
+	defer s.Stop()
+
+	addr := ln.Addr().String()
+	pool := NewConnPool(2, 2, 0)
+	defer pool.Close()
+
+	c1, err := pool.Get(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := pool.Get(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool.Put(c1)
+	pool.Put(c1)
+	if n := len(c1.pool.idles); n != 1 {
+		t.Errorf("expect 1 idle connection, but got %d", n)
+	}
+
+	c2.MarkBroken()
+	pool.Put(c2)
+	pool.Put(c2)
+	if n := len(c2.pool.slots); n != 1 {
+		t.Errorf("expect 1 used slot, but got %d", n)
+	}
+
+	// The idle connection can be borrowed and put back again.
+	if c, err := pool.Get(addr); err != nil {
+		t.Error(err)
+	} else if c != c1 {
+		t.Error("expect to reuse the idle connection")
+	} else {
+		pool.Put(c)
+		if n := len(c.pool.idles); n != 1 {
+			t.Errorf("expect 1 idle connection, but got %d", n)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// GetFreePort returns a free TCP port on the local host, which is chosen
+// by the kernel.
+//
+// Notice: the port may be used by others before using it.
+func GetFreePort() (int, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	return port, nil
+}
+
+// IsPortOpen reports whether the TCP port on the host can be connected
+// within timeout.
+func IsPortOpen(host string, port int, timeout time.Duration) bool {
+	conn, err := net.DialTimeout("tcp", JoinHostPort(host, port), timeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// WaitForPort polls the TCP address addr until it can be connected
+// or ctx is done. The default poll interval is 100ms.
+func WaitForPort(ctx context.Context, addr string, interval ...time.Duration) error {
+	_interval := time.Millisecond * 100
+	if len(interval) > 0 && interval[0] > 0 {
+		_interval = interval[0]
+	}
+
+	ticker := time.NewTicker(_interval)
+	defer ticker.Stop()
+
+	var dialer net.Dialer
+	for {
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPort(t *testing.T) {
+	port, err := GetFreePort()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if IsPortOpen("127.0.0.1", port, time.Second) {
+		t.Error("expect the port to be closed")
+	}
+
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		if ln, err := net.Listen("tcp", addr); err == nil {
+			time.Sleep(time.Second)
+			ln.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err = WaitForPort(ctx, addr, time.Millisecond*10); err != nil {
+		t.Error(err)
+	}
+	if !IsPortOpen("127.0.0.1", port, time.Second) {
+		t.Error("expect the port to be open")
+	}
+}
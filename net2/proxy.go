@@ -0,0 +1,184 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/xgfone/go-tools/sync2"
+)
+
+// ProxyStats is the snapshot of the statistics of the proxy.
+type ProxyStats struct {
+	Conns     int64 // The total number of the proxied connections.
+	Errors    int64 // The total number of the errors to dial the backend.
+	BytesUp   int64 // The total number of the bytes from the clients to the backend.
+	BytesDown int64 // The total number of the bytes from the backend to the clients.
+}
+
+// Proxy is a TCP proxy handler, which forwards the connection
+// to the backend and pipes the data bidirectionally.
+//
+// For TLS on the client leg, set the TLSConfig of the server.
+type Proxy struct {
+	// The 64-bit atomic counters must stay first so that they are 8-byte
+	// aligned on the 32-bit platforms.
+	conns     sync2.AtomicInt64
+	errors    sync2.AtomicInt64
+	bytesUp   sync2.AtomicInt64
+	bytesDown sync2.AtomicInt64
+
+	// Backend is the address of the backend.
+	Backend string
+
+	// Dialer is used to dial the backend.
+	Dialer Dialer
+
+	// TLSConfig is optional. If set, the connection to the backend
+	// will be wrapped by tls.Client.
+	TLSConfig *tls.Config
+
+	// IdleTimeout is the maximum duration that no data is transferred
+	// in both directions. 0 means no timeout.
+	IdleTimeout time.Duration
+}
+
+// NewProxy returns a new Proxy forwarding the connections to backend.
+func NewProxy(backend string) *Proxy {
+	return &Proxy{Backend: backend}
+}
+
+// ListenAndProxy is a convenient function to listen on addr and forward
+// all the connections to the backend.
+func ListenAndProxy(addr, backend string, opts ...ServerOption) error {
+	return ListenAndServe(addr, NewProxy(backend), opts...)
+}
+
+// Stats returns the snapshot of the statistics of the proxy.
+func (p *Proxy) Stats() ProxyStats {
+	return ProxyStats{
+		Conns:     p.conns.Get(),
+		Errors:    p.errors.Get(),
+		BytesUp:   p.bytesUp.Get(),
+		BytesDown: p.bytesDown.Get(),
+	}
+}
+
+// Handle implements the interface Handler.
+func (p *Proxy) Handle(ctx context.Context, conn net.Conn) {
+	backend, err := p.Dialer.DialContext(ctx, "tcp", p.Backend)
+	if err != nil {
+		p.errors.Add(1)
+		return
+	}
+
+	if p.TLSConfig != nil {
+		tc := tls.Client(backend, p.TLSConfig)
+		if err = tc.Handshake(); err != nil {
+			p.errors.Add(1)
+			backend.Close()
+			return
+		}
+		backend = tc
+	}
+	defer backend.Close()
+
+	p.conns.Add(1)
+	up, down := Pipe(ctx, conn, backend, p.IdleTimeout)
+	p.bytesUp.Add(up)
+	p.bytesDown.Add(down)
+}
+
+// Pipe copies the data between c1 and c2 bidirectionally until both
+// directions finish, the idle timeout expires, or ctx is done, then returns
+// the number of the bytes copied from c1 to c2 and from c2 to c1.
+//
+// When a direction finishes, it closes the write side of the destination
+// if supported, or closes both the connections.
+func Pipe(ctx context.Context, c1, c2 net.Conn, idleTimeout time.Duration) (up, down int64) {
+	var last sync2.AtomicInt64
+	last.Set(time.Now().UnixNano())
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			c1.Close()
+			c2.Close()
+		case <-done:
+		}
+	}()
+	defer close(done)
+
+	result := make(chan int64, 1)
+	go func() {
+		result <- pipe(c2, pipeReader{c1, idleTimeout, &last}, c1)
+	}()
+	down = pipe(c1, pipeReader{c2, idleTimeout, &last}, c2)
+	up = <-result
+	return
+}
+
+func pipe(dst net.Conn, src io.Reader, srcConn net.Conn) int64 {
+	n, _ := io.Copy(dst, src)
+	if closeWrite(dst) != nil {
+		dst.Close()
+		srcConn.Close()
+	}
+	return n
+}
+
+var errCloseWriteNotSupported = errors.New("the connection does not support CloseWrite")
+
+// closeWrite shuts down the write side of conn, or returns an error
+// if conn does not support it.
+func closeWrite(conn net.Conn) error {
+	if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+	return errCloseWriteNotSupported
+}
+
+// pipeReader refreshes the read deadline before reading, and only
+// reports the timeout when no data is transferred in both directions.
+type pipeReader struct {
+	conn    net.Conn
+	timeout time.Duration
+	last    *sync2.AtomicInt64
+}
+
+func (r pipeReader) Read(p []byte) (n int, err error) {
+	for {
+		if r.timeout > 0 {
+			r.conn.SetReadDeadline(time.Now().Add(r.timeout))
+		}
+
+		n, err = r.conn.Read(p)
+		if n > 0 {
+			r.last.Set(time.Now().UnixNano())
+		}
+
+		if n == 0 && r.timeout > 0 && isTimeout(err) &&
+			time.Since(time.Unix(0, r.last.Get())) < r.timeout {
+			continue
+		}
+		return
+	}
+}
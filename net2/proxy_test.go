@@ -0,0 +1,131 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-tools/io2"
+)
+
+func TestProxy(t *testing.T) {
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs := NewServer(echoHandler)
+	go bs.Serve(backend)
+	defer bs.Stop()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := NewProxy(backend.Addr().String())
+	proxy.IdleTimeout = time.Millisecond * 100
+	ps := NewServer(proxy)
+	go ps.Serve(ln)
+	defer ps.Stop()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 3)
+	conn.Write([]byte("abc"))
+	if _, err = io.ReadFull(conn, buf); err != nil || string(buf) != "abc" {
+		t.Errorf("buf=%s, err=%v", string(buf), err)
+	}
+
+	// Wait for the idle timeout.
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err = conn.Read(buf); err != io.EOF {
+		t.Errorf("expect io.EOF, but got %v", err)
+	}
+
+	for i := 0; i < 100 && proxy.Stats().BytesDown == 0; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+	if stats := proxy.Stats(); stats.Conns != 1 || stats.BytesUp != 3 || stats.BytesDown != 3 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestListenAndProxyHalfClose(t *testing.T) {
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := backend.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// Finish the backend-to-client direction first.
+		conn.Write([]byte("hello"))
+		conn.(*net.TCPConn).CloseWrite()
+		data, _ := ioutil.ReadAll(conn)
+		received <- string(data)
+	}()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	opts := ConnOptions{ReadTimeout: time.Second, WriteLimiter: io2.NewBytesLimiter(1<<20, 1<<20)}
+	go ListenAndProxy(addr, backend.Addr().String(), WithConnOptions(opts))
+
+	var conn net.Conn
+	for i := 0; i < 100; i++ {
+		if conn, err = net.Dial("tcp", addr); err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if data, err := ioutil.ReadAll(conn); err != nil || string(data) != "hello" {
+		t.Fatalf("data=%q, err=%v", data, err)
+	}
+
+	// The client-to-backend direction still works after the half-close.
+	conn.Write([]byte("world"))
+	conn.(*net.TCPConn).CloseWrite()
+	select {
+	case data := <-received:
+		if data != "world" {
+			t.Errorf("expect 'world', but got %q", data)
+		}
+	case <-time.After(time.Second):
+		t.Error("timeout")
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-tools/io2"
+	"github.com/xgfone/go-tools/wait"
+)
+
+// ErrConnClosed is returned when the connection has been closed.
+var ErrConnClosed = errors.New("the connection has been closed")
+
+// ReconnectingConn is a long-lived client connection, which transparently
+// redials with the exponential backoff and jitter when reading or writing
+// fails, then continues to read or write on the new connection.
+//
+// Notice: the data having been sent by the Write method may be lost
+// when the connection is broken, so it is suitable for the message-based
+// protocol that can tolerate it.
+type ReconnectingConn struct {
+	// Addr is the address of the server.
+	Addr string
+
+	// Dial is used to dial a new connection. The default is DialTCPByAddr.
+	Dial func(addr string) (net.Conn, error)
+
+	// MinDelay and MaxDelay are the minimum and maximum delays between
+	// two redials, and the delay is multiplied by Factor after each redial.
+	// Jitter is the factor to add the random delay for each redial.
+	//
+	// The defaults are 100ms, 30s, 2.0 and 0.1.
+	MinDelay time.Duration
+	MaxDelay time.Duration
+	Factor   float64
+	Jitter   float64
+
+	// MaxRetries is the maximum number of the continuous redials.
+	// 0 means to redial forever until closed.
+	MaxRetries int
+
+	// OnConnect is called when a new connection is established.
+	OnConnect func(conn net.Conn)
+
+	// OnDisconnect is called when the connection is broken by err.
+	OnDisconnect func(conn net.Conn, err error)
+
+	// WriteLimiter is optional, which is used to throttle the writes
+	// by the number of the bytes before writing, such as a pacer to smooth
+	// the traffic to the upstream with the strict rate cap.
+	WriteLimiter io2.RateLimiter
+
+	lock   sync.Mutex
+	dlock  sync.Mutex
+	conn   net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+// NewReconnectingConn returns a new ReconnectingConn connecting to addr.
+//
+// It will start to dial the first connection when reading or writing.
+// If you want to check the connection immediately, call the method Connect.
+func NewReconnectingConn(addr string) *ReconnectingConn {
+	return &ReconnectingConn{Addr: addr, closed: make(chan struct{})}
+}
+
+func (c *ReconnectingConn) init() {
+	c.once.Do(func() {
+		if c.closed == nil {
+			c.closed = make(chan struct{})
+		}
+	})
+}
+
+func (c *ReconnectingConn) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Conn returns the current underlying connection, which may be nil.
+func (c *ReconnectingConn) Conn() net.Conn {
+	c.lock.Lock()
+	conn := c.conn
+	c.lock.Unlock()
+	return conn
+}
+
+// Connect returns the current connection, or dials a new one.
+func (c *ReconnectingConn) Connect() (net.Conn, error) {
+	if conn := c.Conn(); conn != nil {
+		return conn, nil
+	}
+	return c.reconnect(nil, nil)
+}
+
+func (c *ReconnectingConn) dial() (net.Conn, error) {
+	if c.Dial != nil {
+		return c.Dial(c.Addr)
+	}
+
+	conn, err := DialTCPByAddr(c.Addr)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// disconnect closes the broken connection old if it is still the current one,
+// and returns false and the current connection if not.
+//
+// The caller must hold the lock dlock.
+func (c *ReconnectingConn) disconnect(old net.Conn, cause error) (net.Conn, bool) {
+	c.lock.Lock()
+	conn := c.conn
+	c.lock.Unlock()
+	if conn != old {
+		return conn, false
+	}
+
+	if old != nil {
+		old.Close()
+		c.lock.Lock()
+		c.conn = nil
+		c.lock.Unlock()
+		if c.OnDisconnect != nil {
+			c.OnDisconnect(old, cause)
+		}
+	}
+	return nil, true
+}
+
+// reconnect replaces the broken connection old by the new one.
+func (c *ReconnectingConn) reconnect(old net.Conn, cause error) (net.Conn, error) {
+	c.init()
+	c.dlock.Lock()
+	defer c.dlock.Unlock()
+
+	if conn, ok := c.disconnect(old, cause); !ok {
+		return conn, nil // Have been reconnected by other goroutine.
+	}
+
+	delay, max, factor, jitter := c.MinDelay, c.MaxDelay, c.Factor, c.Jitter
+	if delay <= 0 {
+		delay = time.Millisecond * 100
+	}
+	if max <= 0 {
+		max = time.Second * 30
+	}
+	if factor < 1 {
+		factor = 2.0
+	}
+	if jitter <= 0 {
+		jitter = 0.1
+	}
+
+	for retries := 0; ; retries++ {
+		if c.isClosed() {
+			return nil, ErrConnClosed
+		}
+
+		conn, err := c.dial()
+		if err == nil {
+			c.lock.Lock()
+			if c.isClosed() {
+				c.lock.Unlock()
+				conn.Close()
+				return nil, ErrConnClosed
+			}
+			c.conn = conn
+			c.lock.Unlock()
+
+			if c.OnConnect != nil {
+				c.OnConnect(conn)
+			}
+			return conn, nil
+		} else if c.MaxRetries > 0 && retries >= c.MaxRetries {
+			return nil, err
+		}
+
+		timer := time.NewTimer(wait.Jitter(delay, jitter))
+		select {
+		case <-timer.C:
+		case <-c.closed:
+			timer.Stop()
+			return nil, ErrConnClosed
+		}
+
+		if delay = time.Duration(float64(delay) * factor); delay > max {
+			delay = max
+		}
+	}
+}
+
+func isTimeout(err error) bool {
+	ne, ok := err.(net.Error)
+	return ok && ne.Timeout()
+}
+
+// Read implements the interface net.Conn, which will redial and read again
+// when failing to read except for the timeout.
+//
+// If some data has been read before the error, it returns the data first,
+// and redials on the next read.
+func (c *ReconnectingConn) Read(p []byte) (n int, err error) {
+	conn, err := c.Connect()
+	for err == nil {
+		if n, err = conn.Read(p); err == nil || isTimeout(err) {
+			return
+		} else if n > 0 {
+			// Return the data read, and reconnect on the next read.
+			return n, nil
+		}
+		conn, err = c.reconnect(conn, err)
+	}
+	return
+}
+
+// Write implements the interface net.Conn, which will redial and write
+// the whole data again when failing to write except for the timeout.
+//
+// If some data has been written into the broken connection, it will not
+// write the rest data into the new connection, which would corrupt
+// the framed protocols, but close the broken connection and return
+// the number of the written bytes and the error, so that the next write
+// redials and the caller decides whether to resend the whole data.
+func (c *ReconnectingConn) Write(p []byte) (n int, err error) {
+	if c.WriteLimiter != nil && len(p) > 0 {
+		c.WriteLimiter.WaitN(len(p))
+	}
+
+	conn, err := c.Connect()
+	for err == nil {
+		if n, err = conn.Write(p); err == nil || isTimeout(err) {
+			return
+		} else if n > 0 {
+			c.init()
+			c.dlock.Lock()
+			c.disconnect(conn, err)
+			c.dlock.Unlock()
+			return
+		}
+		conn, err = c.reconnect(conn, err)
+	}
+	return
+}
+
+// Close closes the connection and stops redialing.
+func (c *ReconnectingConn) Close() (err error) {
+	c.init()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.isClosed() {
+		return nil
+	}
+
+	close(c.closed)
+	if c.conn != nil {
+		err = c.conn.Close()
+		c.conn = nil
+	}
+	return
+}
+
+// LocalAddr implements the interface net.Conn.
+//
+// Return nil if no connection.
+func (c *ReconnectingConn) LocalAddr() net.Addr {
+	if conn := c.Conn(); conn != nil {
+		return conn.LocalAddr()
+	}
+	return nil
+}
+
+// RemoteAddr implements the interface net.Conn.
+//
+// Return nil if no connection.
+func (c *ReconnectingConn) RemoteAddr() net.Addr {
+	if conn := c.Conn(); conn != nil {
+		return conn.RemoteAddr()
+	}
+	return nil
+}
+
+// SetDeadline implements the interface net.Conn, which only sets
+// the deadline of the current connection.
+func (c *ReconnectingConn) SetDeadline(t time.Time) error {
+	conn, err := c.Connect()
+	if err == nil {
+		err = conn.SetDeadline(t)
+	}
+	return err
+}
+
+// SetReadDeadline implements the interface net.Conn, which only sets
+// the read deadline of the current connection.
+func (c *ReconnectingConn) SetReadDeadline(t time.Time) error {
+	conn, err := c.Connect()
+	if err == nil {
+		err = conn.SetReadDeadline(t)
+	}
+	return err
+}
+
+// SetWriteDeadline implements the interface net.Conn, which only sets
+// the write deadline of the current connection.
+func (c *ReconnectingConn) SetWriteDeadline(t time.Time) error {
+	conn, err := c.Connect()
+	if err == nil {
+		err = conn.SetWriteDeadline(t)
+	}
+	return err
+}
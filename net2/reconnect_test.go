@@ -0,0 +1,155 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestReconnectingConn(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The server sends a message, then closes the connection.
+	s := NewServer(HandlerFunc(func(ctx context.Context, conn net.Conn) {
+		conn.Write([]byte("abc"))
+	}))
+	go s.Serve(ln)
+	defer s.Stop()
+
+	var connects, disconnects int
+	conn := NewReconnectingConn(ln.Addr().String())
+	conn.MinDelay = time.Millisecond * 10
+	conn.OnConnect = func(net.Conn) { connects++ }
+	conn.OnDisconnect = func(net.Conn, error) { disconnects++ }
+	defer conn.Close()
+
+	buf := make([]byte, 3)
+	for i := 0; i < 3; i++ {
+		if _, err = io.ReadFull(conn, buf); err != nil || string(buf) != "abc" {
+			t.Fatalf("buf=%s, err=%v", string(buf), err)
+		}
+	}
+
+	if connects != 3 || disconnects != 2 {
+		t.Errorf("connects=%d, disconnects=%d", connects, disconnects)
+	}
+
+	conn.Close()
+	if _, err = conn.Write([]byte("abc")); err != ErrConnClosed {
+		t.Errorf("expect ErrConnClosed, but got %v", err)
+	}
+}
+
+func TestReconnectingConnMaxRetries(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	conn := NewReconnectingConn(addr)
+	conn.MinDelay = time.Millisecond
+	conn.MaxRetries = 2
+	if _, err = conn.Connect(); err == nil {
+		t.Error("expect an error")
+	}
+}
+
+type partialConn struct {
+	net.Conn
+	written []byte
+	limit   int
+}
+
+func (c *partialConn) Write(p []byte) (int, error) {
+	if c.limit > 0 && len(p) > c.limit {
+		c.written = append(c.written, p[:c.limit]...)
+		return c.limit, errors.New("broken pipe")
+	}
+	c.written = append(c.written, p...)
+	return len(p), nil
+}
+
+func (c *partialConn) Close() error { return nil }
+
+func TestReconnectingConnPartialWrite(t *testing.T) {
+	var conns []*partialConn
+	conn := NewReconnectingConn("")
+	conn.Dial = func(string) (net.Conn, error) {
+		c := &partialConn{}
+		if len(conns) == 0 {
+			c.limit = 2
+		}
+		conns = append(conns, c)
+		return c, nil
+	}
+	defer conn.Close()
+
+	if n, err := conn.Write([]byte("abcd")); n != 2 || err == nil {
+		t.Errorf("expect the partial write, but got n=%d, err=%v", n, err)
+	} else if len(conns) != 1 {
+		t.Errorf("expect not to redial, but got %d connections", len(conns))
+	}
+
+	if n, err := conn.Write([]byte("abcd")); n != 4 || err != nil {
+		t.Errorf("n=%d, err=%v", n, err)
+	} else if len(conns) != 2 || string(conns[1].written) != "abcd" {
+		t.Errorf("expect the whole data on the new connection")
+	}
+}
+
+type eofConn struct {
+	net.Conn
+	data string
+}
+
+func (c *eofConn) Read(p []byte) (int, error) {
+	n := copy(p, c.data)
+	c.data = c.data[n:]
+	return n, io.EOF
+}
+
+func (c *eofConn) Close() error { return nil }
+
+func TestReconnectingConnReadWithEOF(t *testing.T) {
+	var dials int
+	conn := NewReconnectingConn("")
+	conn.Dial = func(string) (net.Conn, error) {
+		dials++
+		return &eofConn{data: fmt.Sprintf("data%d", dials)}, nil
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 16)
+	if n, err := conn.Read(buf); err != nil || string(buf[:n]) != "data1" {
+		t.Errorf("n=%d, err=%v, data=%q", n, err, buf[:n])
+	} else if dials != 1 {
+		t.Errorf("expect not to redial, but dialed %d times", dials)
+	}
+
+	if n, err := conn.Read(buf); err != nil || string(buf[:n]) != "data2" {
+		t.Errorf("n=%d, err=%v, data=%q", n, err, buf[:n])
+	}
+}
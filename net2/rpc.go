@@ -0,0 +1,355 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xgfone/go-tools/pools"
+)
+
+// DefaultRPCMaxMessageSize is the default maximum size of the RPC message.
+const DefaultRPCMaxMessageSize = 16 * 1024 * 1024
+
+// DefaultRPCMaxConcurrency is the default maximum number of the concurrent
+// calls on a RPC connection.
+const DefaultRPCMaxConcurrency = 64
+
+// RPCError is the error returned by the remote RPC handler.
+type RPCError struct {
+	Message string
+}
+
+func (e RPCError) Error() string {
+	return e.Message
+}
+
+// rpcMessage is the RPC request or response, which is encoded by JSON
+// and prefixed by the 4-byte length in big endian.
+type rpcMessage struct {
+	ID       uint64          `json:"id"`
+	Method   string          `json:"method,omitempty"`
+	Params   json.RawMessage `json:"params,omitempty"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Deadline int64           `json:"deadline,omitempty"` // The unix nanoseconds.
+}
+
+func readRPCMessage(r io.Reader, max int) (msg rpcMessage, err error) {
+	var header [4]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return
+	}
+
+	size := int(binary.BigEndian.Uint32(header[:]))
+	if size > max {
+		return msg, ErrMessageTooLong
+	}
+
+	data := pools.GetBytes(size)
+	defer pools.PutBytes(data)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &msg)
+	return
+}
+
+func writeRPCMessage(w io.Writer, msg rpcMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	buf := pools.GetBytes(4 + len(data))
+	defer pools.PutBytes(buf)
+
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	copy(buf[4:], data)
+	_, err = w.Write(buf)
+	return err
+}
+
+// RPCHandler is the handler of a RPC method, which decodes the params
+// and returns the result to be encoded by JSON.
+//
+// ctx will be cancelled when the deadline of the caller expires
+// or the connection is closed.
+type RPCHandler func(ctx context.Context, params json.RawMessage) (result interface{}, err error)
+
+// RPCServer is a simple RPC server handling the requests by the method name,
+// which implements the interface Handler, so it should be served by Server.
+//
+// The calls on a connection are handled concurrently.
+type RPCServer struct {
+	// MaxMessageSize is the maximum size of the request.
+	// The default is DefaultRPCMaxMessageSize.
+	MaxMessageSize int
+
+	// MaxConcurrency is the maximum number of the concurrent calls
+	// on a connection. When reaching it, the next request waits to be
+	// handled until a call finishes. The default is DefaultRPCMaxConcurrency.
+	MaxConcurrency int
+
+	lock     sync.RWMutex
+	handlers map[string]RPCHandler
+}
+
+// NewRPCServer returns a new RPCServer.
+func NewRPCServer() *RPCServer {
+	return &RPCServer{handlers: make(map[string]RPCHandler)}
+}
+
+// Register registers the handler of the method, which will override
+// the old one.
+func (s *RPCServer) Register(method string, handler RPCHandler) {
+	s.lock.Lock()
+	if s.handlers == nil {
+		s.handlers = make(map[string]RPCHandler)
+	}
+	s.handlers[method] = handler
+	s.lock.Unlock()
+}
+
+func (s *RPCServer) getHandler(method string) RPCHandler {
+	s.lock.RLock()
+	handler := s.handlers[method]
+	s.lock.RUnlock()
+	return handler
+}
+
+// Handle implements the interface Handler.
+func (s *RPCServer) Handle(ctx context.Context, conn net.Conn) {
+	max := s.MaxMessageSize
+	if max <= 0 {
+		max = DefaultRPCMaxMessageSize
+	}
+
+	concurrency := s.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultRPCMaxConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wlock sync.Mutex
+	var calls sync.WaitGroup
+	defer func() {
+		cancel() // Cancel the calls in progress when the connection is closed.
+		calls.Wait()
+	}()
+
+	slots := make(chan struct{}, concurrency)
+	reader := bufio.NewReader(conn)
+	for {
+		req, err := readRPCMessage(reader, max)
+		if err != nil {
+			return
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		calls.Add(1)
+		go func() {
+			defer func() { <-slots; calls.Done() }()
+			resp := s.call(ctx, req)
+			wlock.Lock()
+			writeRPCMessage(conn, resp)
+			wlock.Unlock()
+		}()
+	}
+}
+
+func (s *RPCServer) call(ctx context.Context, req rpcMessage) (resp rpcMessage) {
+	resp.ID = req.ID
+	handler := s.getHandler(req.Method)
+	if handler == nil {
+		resp.Error = fmt.Sprintf("no rpc method '%s'", req.Method)
+		return
+	}
+
+	if req.Deadline > 0 {
+		var cancel func()
+		ctx, cancel = context.WithDeadline(ctx, time.Unix(0, req.Deadline))
+		defer cancel()
+	}
+
+	result, err := s.safeCall(ctx, handler, req.Params)
+	if err != nil {
+		resp.Error = err.Error()
+	} else if resp.Result, err = json.Marshal(result); err != nil {
+		resp.Error = err.Error()
+	}
+	return
+}
+
+func (s *RPCServer) safeCall(ctx context.Context, handler RPCHandler,
+	params json.RawMessage) (result interface{}, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("rpc handler panics: %v", v)
+		}
+	}()
+	return handler(ctx, params)
+}
+
+// RPCClient is the client of RPCServer, which supports the concurrent calls
+// on a connection.
+type RPCClient struct {
+	nextID uint64 // It must stay first to be 8-byte aligned on 32-bit.
+
+	maxMessageSize int
+	conn           net.Conn
+	wlock          sync.Mutex
+	lock           sync.Mutex
+	pending        map[uint64]chan rpcMessage
+	closed         chan struct{}
+	once           sync.Once
+	err            error
+}
+
+// DialRPC dials the RPC server on the TCP address addr by the dialer,
+// and returns a new RPCClient.
+func DialRPC(addr string, dialer ...Dialer) (*RPCClient, error) {
+	var d Dialer
+	if len(dialer) > 0 {
+		d = dialer[0]
+	}
+
+	conn, err := d.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewRPCClient(conn), nil
+}
+
+// RPCClientOption is used to configure the RPC client.
+type RPCClientOption func(*RPCClient)
+
+// WithRPCMaxMessageSize returns a RPC client option to set the maximum size
+// of the response, which is DefaultRPCMaxMessageSize by default.
+func WithRPCMaxMessageSize(size int) RPCClientOption {
+	return func(c *RPCClient) { c.maxMessageSize = size }
+}
+
+// NewRPCClient returns a new RPCClient on the connection.
+func NewRPCClient(conn net.Conn, opts ...RPCClientOption) *RPCClient {
+	c := &RPCClient{
+		conn:    conn,
+		pending: make(map[uint64]chan rpcMessage),
+		closed:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	go c.recvLoop()
+	return c
+}
+
+// Close closes the client and the underlying connection.
+func (c *RPCClient) Close() error {
+	return c.close(ErrConnClosed)
+}
+
+func (c *RPCClient) close(err error) (e error) {
+	c.once.Do(func() {
+		c.err = err
+		close(c.closed)
+		e = c.conn.Close()
+	})
+	return
+}
+
+func (c *RPCClient) recvLoop() {
+	max := c.maxMessageSize
+	if max <= 0 {
+		max = DefaultRPCMaxMessageSize
+	}
+
+	reader := bufio.NewReader(c.conn)
+	for {
+		resp, err := readRPCMessage(reader, max)
+		if err != nil {
+			c.close(err)
+			return
+		}
+
+		c.lock.Lock()
+		ch, ok := c.pending[resp.ID]
+		delete(c.pending, resp.ID)
+		c.lock.Unlock()
+		if ok {
+			ch <- resp
+		}
+	}
+}
+
+// Call calls the remote method with params, and decodes the response
+// into result if it's not nil. The deadline of ctx is passed to the server.
+func (c *RPCClient) Call(ctx context.Context, method string, params, result interface{}) (err error) {
+	req := rpcMessage{ID: atomic.AddUint64(&c.nextID, 1), Method: method}
+	if params != nil {
+		if req.Params, err = json.Marshal(params); err != nil {
+			return
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Deadline = deadline.UnixNano()
+	}
+
+	ch := make(chan rpcMessage, 1)
+	c.lock.Lock()
+	c.pending[req.ID] = ch
+	c.lock.Unlock()
+	defer func() {
+		c.lock.Lock()
+		delete(c.pending, req.ID)
+		c.lock.Unlock()
+	}()
+
+	c.wlock.Lock()
+	err = writeRPCMessage(c.conn, req)
+	c.wlock.Unlock()
+	if err != nil {
+		c.close(err)
+		return
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != "" {
+			return RPCError{Message: resp.Error}
+		} else if result != nil && len(resp.Result) > 0 {
+			return json.Unmarshal(resp.Result, result)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.closed:
+		return c.err
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRPC(t *testing.T) {
+	rpc := NewRPCServer()
+	rpc.Register("add", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var args [2]int
+		if err := json.Unmarshal(params, &args); err != nil {
+			return nil, err
+		}
+		return args[0] + args[1], nil
+	})
+	rpc.Register("sleep", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(rpc)
+	go s.Serve(ln)
+	defer s.Stop()
+
+	client, err := DialRPC(ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var sum int
+			if err := client.Call(context.Background(), "add", []int{i, i}, &sum); err != nil {
+				t.Error(err)
+			} else if sum != i+i {
+				t.Errorf("expect %d, but got %d", i+i, sum)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err = client.Call(context.Background(), "none", nil, nil); err == nil {
+		t.Error("expect an error")
+	} else if _, ok := err.(RPCError); !ok {
+		t.Errorf("expect RPCError, but got %T", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if err = client.Call(ctx, "sleep", nil, nil); err != context.DeadlineExceeded {
+		t.Errorf("expect context.DeadlineExceeded, but got %v", err)
+	}
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	small := NewRPCClient(conn, WithRPCMaxMessageSize(4))
+	defer small.Close()
+	if err = small.Call(context.Background(), "add", []int{1, 2}, nil); err == nil {
+		t.Error("expect an error for the too large response")
+	}
+}
+
+func TestRPCServerConnClosed(t *testing.T) {
+	started := make(chan struct{})
+	rpc := NewRPCServer()
+	rpc.MaxConcurrency = 1
+	rpc.Register("wait", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	sconn, cconn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		rpc.Handle(context.Background(), sconn)
+		close(done)
+	}()
+
+	client := NewRPCClient(cconn)
+	go client.Call(context.Background(), "wait", nil, nil)
+	<-started
+
+	client.Close()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the rpc call is not cancelled after the connection is closed")
+	}
+}
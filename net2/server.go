@@ -0,0 +1,596 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xgfone/go-tools/logs"
+	"github.com/xgfone/go-tools/sync2"
+)
+
+// ErrServerStopped is returned when the server has been stopped.
+var ErrServerStopped = errors.New("the server has been stopped")
+
+// Handler is used to handle the connection accepted by the server.
+//
+// ctx will be cancelled when the server is stopped or Handle returns.
+type Handler interface {
+	Handle(ctx context.Context, conn net.Conn)
+}
+
+// HandlerFunc is an adapter to allow the use of the ordinary function
+// as Handler.
+type HandlerFunc func(ctx context.Context, conn net.Conn)
+
+// Handle implements the interface Handler.
+func (f HandlerFunc) Handle(ctx context.Context, conn net.Conn) {
+	f(ctx, conn)
+}
+
+// FullPolicy is the policy how to handle the new connection
+// when the number of the concurrent connections reaches the limit.
+type FullPolicy int
+
+// Predefine some policies when the connections are full.
+const (
+	// FullBlock stops accepting the new connections until a connection
+	// is closed, so the new connections will wait in the backlog of the kernel.
+	FullBlock FullPolicy = iota
+
+	// FullQueue accepts the new connection, but the handler does not
+	// handle it until a connection is closed.
+	FullQueue
+
+	// FullReject accepts and closes the new connection immediately,
+	// which will send RST to the client for the TCP connection.
+	FullReject
+)
+
+// TCPServerForeverTLS is the same as TCPServerForever, but wraps
+// the accepted connection by TLS with the config.
+func TCPServerForeverTLS(addr string, config *tls.Config, handler func(net.Conn)) error {
+	s := NewServer(HandlerFunc(func(ctx context.Context, conn net.Conn) { handler(conn) }))
+	s.TLSConfig = config
+	return s.ListenAndServe(addr)
+}
+
+// NewServerTLSConfig returns a new TLS config for the server with the pair
+// of the certificate and the key files.
+//
+// If giving clientCAFiles, it will require and verify the certificates
+// of the clients by the CAs.
+func NewServerTLSConfig(certFile, keyFile string, clientCAFiles ...string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if len(clientCAFiles) > 0 {
+		pool, err := loadCertPool(nil, clientCAFiles...)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// Server is a stream server based on net.Listener, such as TCP, TLS, etc.
+type Server struct {
+	// Handler handles the accepted connection, which will be closed
+	// after the handler returns.
+	Handler Handler
+
+	// Middlewares is used to wrap Handler, which are executed in order.
+	Middlewares []Middleware
+
+	// ConnContext is optional, which is used to modify the context
+	// for the new connection, such as adding the values or the deadline.
+	// The context of the server is cancelled when the server is stopped.
+	ConnContext func(ctx context.Context, conn net.Conn) context.Context
+
+	// TLSConfig is optional. If set, the accepted connection will be wrapped
+	// by tls.Server, and the handshake is finished before calling Handler.
+	TLSConfig *tls.Config
+
+	// ConnOptions is used to tune the accepted connection.
+	ConnOptions ConnOptions
+
+	// MaxConns is the maximum number of the concurrent connections,
+	// and FullPolicy is the policy when reaching the limit.
+	//
+	// The default is 0, which means no limit.
+	MaxConns   int
+	FullPolicy FullPolicy
+
+	// ACL is optional, which is used to close the connections from
+	// the denied addresses immediately before handling them.
+	ACL *ACL
+
+	// IdleTimeout is the maximum duration that a connection has no data
+	// to read or write, and the idle connections will be closed
+	// by the reaper periodically. The default is 0, which means no timeout.
+	IdleTimeout time.Duration
+
+	// MaxAcceptErrors is the maximum number of the consecutive temporary
+	// errors to accept the connections, such as EMFILE, which will back off
+	// exponentially before accepting again. If reaching the limit, or the error
+	// is not temporary, Serve returns the error.
+	//
+	// The default is 0, which means no limit.
+	MaxAcceptErrors int
+
+	// OnConnect is called when a connection is accepted, before handling it.
+	OnConnect func(conn net.Conn)
+
+	// OnDisconnect is called when the connection is closed
+	// after the handler returns.
+	OnDisconnect func(conn net.Conn, stats *ConnStats)
+
+	// OnError is called when failing to accept, tune or handshake
+	// the connection. conn is nil if failing to accept.
+	OnError func(conn net.Conn, err error)
+
+	// Logger is used to log the errors of the connections, such as failing
+	// to accept, tune or handshake. The default is nil, which does not log.
+	Logger logs.Logger
+
+	stats     serverStats
+	lock      sync.Mutex
+	handler   Handler
+	slots     *sync2.Semaphore
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]*ConnStats
+	connLock  sync.Mutex
+	waits     sync2.WaitGroupTimeout
+	closed    int32
+	ctx       context.Context
+	cancel    func()
+}
+
+// ServerOption is used to configure the server.
+type ServerOption func(*Server)
+
+// WithMiddlewares returns a server option to append the middlewares.
+func WithMiddlewares(mws ...Middleware) ServerOption {
+	return func(s *Server) { s.Middlewares = append(s.Middlewares, mws...) }
+}
+
+// WithConnContext returns a server option to set ConnContext.
+func WithConnContext(f func(ctx context.Context, conn net.Conn) context.Context) ServerOption {
+	return func(s *Server) { s.ConnContext = f }
+}
+
+// WithTLSConfig returns a server option to set TLSConfig.
+func WithTLSConfig(config *tls.Config) ServerOption {
+	return func(s *Server) { s.TLSConfig = config }
+}
+
+// WithConnOptions returns a server option to set ConnOptions.
+func WithConnOptions(opts ConnOptions) ServerOption {
+	return func(s *Server) { s.ConnOptions = opts }
+}
+
+// WithMaxConns returns a server option to set MaxConns and FullPolicy.
+func WithMaxConns(maxConns int, policy FullPolicy) ServerOption {
+	return func(s *Server) { s.MaxConns, s.FullPolicy = maxConns, policy }
+}
+
+// WithACL returns a server option to set ACL.
+func WithACL(acl *ACL) ServerOption {
+	return func(s *Server) { s.ACL = acl }
+}
+
+// WithIdleTimeout returns a server option to set IdleTimeout.
+func WithIdleTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) { s.IdleTimeout = timeout }
+}
+
+// WithLogger returns a server option to set Logger.
+func WithLogger(logger logs.Logger) ServerOption {
+	return func(s *Server) { s.Logger = logger }
+}
+
+// WithHooks returns a server option to set OnConnect, OnDisconnect
+// and OnError, which are ignored if nil.
+func WithHooks(onConnect func(net.Conn), onDisconnect func(net.Conn, *ConnStats),
+	onError func(net.Conn, error)) ServerOption {
+	return func(s *Server) {
+		if onConnect != nil {
+			s.OnConnect = onConnect
+		}
+		if onDisconnect != nil {
+			s.OnDisconnect = onDisconnect
+		}
+		if onError != nil {
+			s.OnError = onError
+		}
+	}
+}
+
+// ListenAndServe is a convenient function to create a new server
+// with the handler and the options, then listens on the address addr
+// and serves it.
+func ListenAndServe(addr string, handler Handler, opts ...ServerOption) error {
+	return NewServer(handler, opts...).ListenAndServe(addr)
+}
+
+// NewServer returns a new Server.
+func NewServer(handler Handler, opts ...ServerOption) *Server {
+	s := &Server{Handler: handler}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Use appends the middlewares to wrap the handler, which must be called
+// before serving.
+func (s *Server) Use(mws ...Middleware) *Server {
+	s.Middlewares = append(s.Middlewares, mws...)
+	return s
+}
+
+// Context returns the context of the server, which will be cancelled
+// when the server is stopped.
+func (s *Server) Context() context.Context {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.context()
+}
+
+func (s *Server) context() context.Context {
+	if s.ctx == nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	return s.ctx
+}
+
+// Listen listens on the address addr, the format of which is
+// "[NETWORK://]ADDRESS", such as ":8000", "tcp6://[::1]:8000", etc.
+// The default network is "tcp".
+//
+// For the unix socket, it may be "unix:/path/to/socket" or
+// "unix:///path/to/socket", and the stale socket file will be removed
+// before listening.
+func Listen(addr string) (net.Listener, error) {
+	network := "tcp"
+	if index := strings.Index(addr, "://"); index > -1 {
+		network, addr = addr[:index], addr[index+3:]
+	} else if strings.HasPrefix(addr, "unix:") {
+		network, addr = "unix", addr[5:]
+	}
+
+	if network == "unix" {
+		if fi, err := os.Stat(addr); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			os.Remove(addr)
+		}
+	}
+
+	return net.Listen(network, addr)
+}
+
+// ListenAndServe listens on all the addresses by Listen, then serves them
+// by the shared handler, middlewares and shutdown.
+//
+// It will block until the server is stopped, and returns the first error.
+// If failing to listen on or serve any address, it will stop the server
+// and close all the listeners.
+func (s *Server) ListenAndServe(addrs ...string) error {
+	if len(addrs) == 0 {
+		return errors.New("no listen address")
+	}
+
+	lns := make([]net.Listener, 0, len(addrs))
+	for _, addr := range addrs {
+		ln, err := Listen(addr)
+		if err != nil {
+			for _, ln := range lns {
+				ln.Close()
+			}
+			return err
+		}
+		lns = append(lns, ln)
+	}
+
+	if len(lns) == 1 {
+		return s.Serve(lns[0])
+	}
+
+	errs := make(chan error, len(lns))
+	for _, ln := range lns {
+		go func(ln net.Listener) {
+			err := s.Serve(ln)
+			if err != nil {
+				s.Stop()
+			}
+			errs <- err
+		}(ln)
+	}
+
+	var err error
+	for range lns {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (s *Server) addListener(ln net.Listener) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.IsStopped() {
+		return false
+	}
+	if s.listeners == nil {
+		s.listeners = make(map[net.Listener]struct{})
+	}
+	s.listeners[ln] = struct{}{}
+	if s.handler == nil {
+		s.handler = Chain(s.Handler, s.Middlewares...)
+	}
+	if s.slots == nil && s.MaxConns > 0 {
+		s.slots = sync2.NewSemaphore(s.MaxConns, 0)
+	}
+	s.context()
+	if s.conns == nil && s.IdleTimeout > 0 {
+		s.conns = make(map[net.Conn]*ConnStats)
+		go s.reap(s.ctx, s.IdleTimeout)
+	}
+	return true
+}
+
+// reap closes the connections which have been idle for timeout
+// periodically until ctx is done.
+func (s *Server) reap(ctx context.Context, timeout time.Duration) {
+	interval := timeout / 2
+	if interval <= 0 {
+		interval = timeout
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.connLock.Lock()
+			for conn, stats := range s.conns {
+				if stats.IdleTime() > timeout {
+					delete(s.conns, conn)
+					s.stats.reaped.Add(1)
+					conn.Close()
+				}
+			}
+			s.connLock.Unlock()
+		}
+	}
+}
+
+// Addrs returns the addresses of all the listeners being served.
+func (s *Server) Addrs() []net.Addr {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	addrs := make([]net.Addr, 0, len(s.listeners))
+	for ln := range s.listeners {
+		addrs = append(addrs, ln.Addr())
+	}
+	return addrs
+}
+
+func (s *Server) removeListener(ln net.Listener) {
+	s.lock.Lock()
+	delete(s.listeners, ln)
+	s.lock.Unlock()
+}
+
+// Serve accepts the connections on the listener ln and handles them
+// in the new goroutines, which will block until the server is stopped
+// or the listener fails.
+//
+// It may be called more than once to serve on several listeners,
+// and returns nil if the server is stopped.
+func (s *Server) Serve(ln net.Listener) error {
+	defer ln.Close()
+	if !s.addListener(ln) {
+		return ErrServerStopped
+	}
+	defer s.removeListener(ln)
+
+	s.waits.Add(1)
+	defer s.waits.Done()
+
+	backoff := acceptBackoff{max: s.MaxAcceptErrors}
+	for {
+		acquired := s.slots != nil && s.FullPolicy == FullBlock && s.slots.Acquire()
+		conn, err := ln.Accept()
+		if err != nil {
+			if acquired {
+				s.slots.Release()
+			}
+			if s.IsStopped() {
+				return nil
+			}
+
+			s.stats.acceptErrors.Add(1)
+			s.onError(nil, err)
+			if backoff.retry(err) {
+				continue
+			}
+			return err
+		}
+
+		backoff.reset()
+		s.stats.accepted.Add(1)
+		if s.ACL != nil && !s.ACL.AllowAddr(conn.RemoteAddr()) {
+			if acquired {
+				s.slots.Release()
+			}
+			s.stats.denied.Add(1)
+			s.stats.closed.Add(1)
+			reject(conn)
+			continue
+		}
+
+		if s.slots != nil && !acquired && s.FullPolicy == FullReject {
+			if acquired = s.slots.TryAcquire(); !acquired {
+				s.stats.rejected.Add(1)
+				s.stats.closed.Add(1)
+				reject(conn)
+				continue
+			}
+		}
+
+		s.waits.Add(1)
+		go s.handle(s.ctx, conn, acquired)
+	}
+}
+
+func (s *Server) onError(conn net.Conn, err error) {
+	s.stats.errors.Add(1)
+	if s.Logger != nil {
+		if conn == nil {
+			s.Logger.Error("failed to accept the connection", "err", err)
+		} else {
+			s.Logger.Error("failed to set up the connection",
+				"remote", conn.RemoteAddr().String(), "err", err)
+		}
+	}
+	if s.OnError != nil {
+		s.OnError(conn, err)
+	}
+}
+
+func reject(conn net.Conn) {
+	if tc, ok := conn.(*net.TCPConn); ok {
+		tc.SetLinger(0) // Send RST instead of FIN.
+	}
+	conn.Close()
+}
+
+func (s *Server) handle(ctx context.Context, conn net.Conn, acquired bool) {
+	if s.slots != nil {
+		if !acquired {
+			s.slots.Acquire()
+		}
+		defer s.slots.Release()
+	}
+
+	if s.ConnContext != nil {
+		ctx = s.ConnContext(ctx, conn)
+	}
+
+	stats := newConnStats()
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, connStatsKey{}, stats))
+	s.stats.active.Add(1)
+	if s.conns != nil {
+		s.connLock.Lock()
+		s.conns[conn] = stats
+		s.connLock.Unlock()
+	}
+
+	if s.OnConnect != nil {
+		s.OnConnect(conn)
+	}
+
+	raw := conn
+	defer func() {
+		cancel()
+		conn.Close()
+		s.stats.active.Add(-1)
+		s.stats.closed.Add(1)
+		if s.conns != nil {
+			s.connLock.Lock()
+			delete(s.conns, raw)
+			s.connLock.Unlock()
+		}
+		if s.OnDisconnect != nil {
+			s.OnDisconnect(raw, stats)
+		}
+		s.waits.Done()
+	}()
+
+	c, err := s.ConnOptions.Apply(conn)
+	if err != nil {
+		s.onError(raw, err)
+		return
+	}
+	conn = countConn{Conn: c, server: &s.stats, conn: stats}
+
+	if s.TLSConfig != nil {
+		tlsConn := tls.Server(conn, s.TLSConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			s.onError(raw, err)
+			return
+		}
+		conn = tlsConn
+	}
+
+	start := time.Now()
+	defer s.stats.recordHandle(start)
+	s.handler.Handle(ctx, conn)
+}
+
+// Stop stops the server, which closes all the listeners.
+func (s *Server) Stop() {
+	if atomic.CompareAndSwapInt32(&s.closed, 0, 1) {
+		s.lock.Lock()
+		for ln := range s.listeners {
+			ln.Close()
+		}
+		if s.cancel != nil {
+			s.cancel()
+		}
+		s.lock.Unlock()
+	}
+}
+
+// Wait waits until all the connections are closed and exit.
+func (s *Server) Wait() {
+	s.waits.Wait(0)
+}
+
+// WaitTimeout is the same as Wait, but waits for the timeout at most,
+// and reports whether all the connections have been closed, which is used
+// to shut down the server gracefully, for example,
+//
+//	server.Stop()
+//	if !server.WaitTimeout(time.Second * 30) {
+//		// Some connections are still being handled.
+//	}
+func (s *Server) WaitTimeout(timeout time.Duration) bool {
+	return s.waits.Wait(timeout)
+}
+
+// IsStopped reports whether the server is stopped
+func (s *Server) IsStopped() bool {
+	return atomic.LoadInt32(&s.closed) == 1
+}
@@ -0,0 +1,481 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-tools/logs"
+	"github.com/xgfone/go-tools/sync2"
+)
+
+// generateCert generates a certificate signed by parent, or a self-signed CA
+// certificate if parent is nil, and writes the certificate and the key
+// into dir with the name.
+func generateCert(t *testing.T, dir, name string, parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err = ioutil.WriteFile(filepath.Join(dir, name+".crt"), certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+// generateCerts generates the certificates of ca, server and client into dir.
+func generateCerts(t *testing.T) (dir string) {
+	dir, err := ioutil.TempDir("", "net2_tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca, caKey := generateCert(t, dir, "ca", nil, nil)
+	generateCert(t, dir, "server", ca, caKey)
+	generateCert(t, dir, "client", ca, caKey)
+	return dir
+}
+
+var echoHandler = HandlerFunc(func(ctx context.Context, conn net.Conn) {
+	io.Copy(conn, conn)
+})
+
+func TestServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(echoHandler)
+	go s.Serve(ln)
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 3)
+	conn.Write([]byte("abc"))
+	if _, err = io.ReadFull(conn, buf); err != nil || string(buf) != "abc" {
+		t.Errorf("buf=%s, err=%v", string(buf), err)
+	}
+	conn.Close()
+
+	s.Stop()
+	s.Wait()
+	if err = s.Serve(ln); err != ErrServerStopped {
+		t.Errorf("expect ErrServerStopped, but got %v", err)
+	}
+}
+
+func TestServerWaitTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(echoHandler)
+	go s.Serve(ln)
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 3)
+	conn.Write([]byte("abc"))
+	if _, err = io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Stop()
+	if s.WaitTimeout(time.Millisecond * 50) {
+		t.Error("expect the timeout with the active connection")
+	}
+
+	conn.Close()
+	if !s.WaitTimeout(time.Second) {
+		t.Error("expect all the connections to be closed")
+	}
+}
+
+func TestListenAndServe(t *testing.T) {
+	mw := Recover(nil)
+	s := NewServer(echoHandler, WithMiddlewares(mw), WithMaxConns(10, FullReject))
+	if len(s.Middlewares) != 1 || s.MaxConns != 10 || s.FullPolicy != FullReject {
+		t.Errorf("the options are not applied: %+v", s)
+	}
+	if err := ListenAndServe("127.0.0.1:-1", echoHandler); err == nil {
+		t.Error("expect an error")
+	}
+}
+
+func TestServerIdleTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(echoHandler, WithIdleTimeout(time.Millisecond*100))
+	go s.Serve(ln)
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err = conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("expect io.EOF, but got %v", err)
+	} else if cost := time.Since(start); cost < time.Millisecond*100 {
+		t.Errorf("the connection is closed too early: %s", cost)
+	}
+	if stats := s.Stats(); stats.Reaped != 1 {
+		t.Errorf("expect 1 reaped connection, but got %d", stats.Reaped)
+	}
+}
+
+func TestServerReapTinyIdleTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	NewServer(echoHandler).reap(ctx, time.Nanosecond) // Must not panic.
+}
+
+func TestServerHooks(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var connects, disconnects, errs sync2.AtomicInt64
+	s := NewServer(echoHandler, WithHooks(
+		func(net.Conn) { connects.Add(1) },
+		func(net.Conn, *ConnStats) { disconnects.Add(1) },
+		func(net.Conn, error) { errs.Add(1) },
+	))
+	s.TLSConfig = &tls.Config{}
+	go s.Serve(ln)
+
+	// The TLS handshake fails.
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(time.Second))
+	conn.Write([]byte("GET / HTTP/1.1\r\n\r\n"))
+	conn.Read(make([]byte, 1))
+	conn.Close()
+
+	s.Stop()
+	s.Wait()
+	if connects.Get() != 1 || disconnects.Get() != 1 || errs.Get() != 1 {
+		t.Errorf("connects=%d, disconnects=%d, errors=%d", connects.Get(), disconnects.Get(), errs.Get())
+	}
+}
+
+type errListener struct {
+	net.Listener
+	errs []error
+}
+
+func (l *errListener) Accept() (net.Conn, error) {
+	err := l.errs[0]
+	if len(l.errs) > 1 {
+		l.errs = l.errs[1:]
+	}
+	return nil, err
+}
+
+func TestServerAcceptBackoff(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	temp := timeoutError{}
+	fatal := errors.New("fatal")
+
+	s := NewServer(echoHandler)
+	if err = s.Serve(&errListener{ln, []error{temp, temp, temp, fatal}}); err != fatal {
+		t.Errorf("expect the fatal error, but got %v", err)
+	} else if n := s.Stats().AcceptErrors; n != 4 {
+		t.Errorf("expect 4 accept errors, but got %d", n)
+	}
+
+	s = NewServer(echoHandler)
+	s.MaxAcceptErrors = 2
+	if err = s.Serve(&errListener{ln, []error{temp, temp, temp, fatal}}); err != temp {
+		t.Errorf("expect the temporary error, but got %v", err)
+	} else if n := s.Stats().AcceptErrors; n != 3 {
+		t.Errorf("expect 3 accept errors, but got %d", n)
+	}
+}
+
+func TestServerMultiAddrs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "net2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := NewServer(echoHandler)
+	done := make(chan error)
+	go func() { done <- s.ListenAndServe("127.0.0.1:0", "unix:"+filepath.Join(dir, "sock")) }()
+	for i := 0; i < 100 && len(s.Addrs()) < 2; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	addrs := s.Addrs()
+	if len(addrs) != 2 {
+		t.Fatalf("expect 2 addresses, but got %d", len(addrs))
+	}
+
+	buf := make([]byte, 3)
+	for _, addr := range addrs {
+		conn, err := net.Dial(addr.Network(), addr.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Write([]byte("abc"))
+		if _, err = io.ReadFull(conn, buf); err != nil || string(buf) != "abc" {
+			t.Errorf("%s: buf=%s, err=%v", addr.Network(), string(buf), err)
+		}
+		conn.Close()
+	}
+
+	s.Stop()
+	if err = <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServerTLS(t *testing.T) {
+	dir := generateCerts(t)
+	defer os.RemoveAll(dir)
+
+	config, err := NewServerTLSConfig(filepath.Join(dir, "server.crt"),
+		filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connected := make(chan net.Conn, 2)
+	errored := make(chan net.Conn, 1)
+	s := NewServer(echoHandler, WithHooks(func(c net.Conn) { connected <- c },
+		nil, func(c net.Conn, err error) { errored <- c }))
+	s.TLSConfig = config
+	go s.Serve(ln)
+	defer s.Stop()
+
+	caPEM, _ := ioutil.ReadFile(filepath.Join(dir, "ca.crt"))
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(caPEM)
+
+	// No client certificate
+	conn, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{RootCAs: roots})
+	if err == nil {
+		conn.SetDeadline(time.Now().Add(time.Second))
+		conn.Write([]byte("abc"))
+		if _, err = conn.Read(make([]byte, 3)); err == nil {
+			t.Error("expect an error without the client certificate")
+		}
+		conn.Close()
+	}
+
+	select {
+	case c := <-errored:
+		if c != <-connected {
+			t.Error("expect the same connection passed to OnConnect and OnError")
+		}
+	case <-time.After(time.Second):
+		t.Error("expect OnError to be called for the failed handshake")
+	}
+
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err = tls.Dial("tcp", ln.Addr().String(), &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{cert},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 3)
+	conn.Write([]byte("abc"))
+	if _, err = io.ReadFull(conn, buf); err != nil || string(buf) != "abc" {
+		t.Errorf("buf=%s, err=%v", string(buf), err)
+	}
+}
+
+func TestServerContext(t *testing.T) {
+	type ctxkey struct{}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := make(chan interface{}, 1)
+	done := make(chan struct{})
+	s := NewServer(HandlerFunc(func(ctx context.Context, conn net.Conn) {
+		values <- ctx.Value(ctxkey{})
+		<-ctx.Done()
+		close(done)
+	}))
+	s.ConnContext = func(ctx context.Context, conn net.Conn) context.Context {
+		return context.WithValue(ctx, ctxkey{}, "value")
+	}
+	go s.Serve(ln)
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if v := <-values; v != "value" {
+		t.Errorf("expect the value 'value', but got '%v'", v)
+	}
+
+	s.Stop()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("the context of the handler is not cancelled")
+	}
+	s.Wait()
+}
+
+func TestServerMaxConns(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(echoHandler)
+	s.MaxConns = 1
+	s.FullPolicy = FullReject
+	go s.Serve(ln)
+	defer s.Stop()
+
+	conn1, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn1.Close()
+
+	buf := make([]byte, 3)
+	conn1.Write([]byte("abc"))
+	if _, err = io.ReadFull(conn1, buf); err != nil || string(buf) != "abc" {
+		t.Errorf("buf=%s, err=%v", string(buf), err)
+	}
+
+	conn2, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn2.Close()
+
+	conn2.SetDeadline(time.Now().Add(time.Second))
+	conn2.Write([]byte("abc"))
+	if _, err = io.ReadFull(conn2, buf); err == nil {
+		t.Error("expect the second connection to be rejected")
+	}
+}
+
+func TestServerLogger(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	buf := bytes.NewBuffer(nil)
+	fatal := errors.New("fatal")
+	s := NewServer(echoHandler, WithLogger(logs.New(buf, logs.LvlInfo)))
+	if err = s.Serve(&errListener{ln, []error{fatal}}); err != fatal {
+		t.Errorf("expect the fatal error, but got %v", err)
+	}
+
+	if s := buf.String(); !strings.Contains(s, "ERROR failed to accept the connection err=fatal") {
+		t.Errorf("unexpected log: %s", s)
+	}
+}
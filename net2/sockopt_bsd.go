@@ -0,0 +1,27 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin || freebsd
+// +build darwin freebsd
+
+package net2
+
+import "syscall"
+
+const soReusePort = syscall.SO_REUSEPORT
+
+// The IPv4 multicast options of BSD take the value of byte.
+func setIPv4MulticastOpt(fd, opt, value int) error {
+	return syscall.SetsockoptByte(fd, syscall.IPPROTO_IP, opt, byte(value))
+}
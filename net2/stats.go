@@ -0,0 +1,255 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/xgfone/go-tools/sync2"
+)
+
+// ServerStats is the snapshot of the statistics of the server.
+type ServerStats struct {
+	Accepted int64 // The total number of the accepted connections.
+	Active   int64 // The number of the connections being handled.
+	Closed   int64 // The total number of the closed connections.
+	Rejected int64 // The total number of the rejected connections.
+	Denied   int64 // The total number of the connections denied by ACL.
+	Reaped   int64 // The total number of the connections closed for idle.
+	Errors   int64 // The total number of the errors, such as accept, handshake, etc.
+
+	AcceptErrors int64 // The total number of the errors to accept the connections.
+
+	BytesIn  int64 // The total number of the bytes read from the connections.
+	BytesOut int64 // The total number of the bytes written to the connections.
+
+	// The total and maximum durations of the handlers having returned.
+	HandleDuration    time.Duration
+	MaxHandleDuration time.Duration
+}
+
+// WritePrometheus writes the statistics into w by the Prometheus text
+// exposition format, the names of which are prefixed by prefix.
+func (s ServerStats) WritePrometheus(w io.Writer, prefix string) (err error) {
+	metrics := []struct {
+		name  string
+		kind  string
+		value interface{}
+	}{
+		{"connections_accepted_total", "counter", s.Accepted},
+		{"connections_active", "gauge", s.Active},
+		{"connections_closed_total", "counter", s.Closed},
+		{"connections_rejected_total", "counter", s.Rejected},
+		{"connections_denied_total", "counter", s.Denied},
+		{"connections_reaped_total", "counter", s.Reaped},
+		{"errors_total", "counter", s.Errors},
+		{"accept_errors_total", "counter", s.AcceptErrors},
+		{"bytes_in_total", "counter", s.BytesIn},
+		{"bytes_out_total", "counter", s.BytesOut},
+		{"handle_duration_seconds_total", "counter", s.HandleDuration.Seconds()},
+		{"handle_duration_seconds_max", "gauge", s.MaxHandleDuration.Seconds()},
+	}
+
+	for _, m := range metrics {
+		name := prefix + m.name
+		if _, err = fmt.Fprintf(w, "# TYPE %s %s\n%s %v\n", name, m.kind, name, m.value); err != nil {
+			return
+		}
+	}
+	return
+}
+
+type serverStats struct {
+	accepted sync2.AtomicInt64
+	active   sync2.AtomicInt64
+	closed   sync2.AtomicInt64
+	rejected sync2.AtomicInt64
+	denied   sync2.AtomicInt64
+	reaped   sync2.AtomicInt64
+	errors   sync2.AtomicInt64
+
+	acceptErrors sync2.AtomicInt64
+	bytesIn      sync2.AtomicInt64
+	bytesOut     sync2.AtomicInt64
+
+	handleDuration    sync2.AtomicDuration
+	maxHandleDuration sync2.AtomicDuration
+}
+
+func (s *serverStats) recordHandle(start time.Time) {
+	cost := time.Since(start)
+	s.handleDuration.Add(cost)
+	for {
+		max := s.maxHandleDuration.Get()
+		if cost <= max || s.maxHandleDuration.CompareAndSwap(max, cost) {
+			return
+		}
+	}
+}
+
+// Stats returns the snapshot of the statistics of the server.
+func (s *Server) Stats() ServerStats {
+	return ServerStats{
+		Accepted: s.stats.accepted.Get(),
+		Active:   s.stats.active.Get(),
+		Closed:   s.stats.closed.Get(),
+		Rejected: s.stats.rejected.Get(),
+		Denied:   s.stats.denied.Get(),
+		Reaped:   s.stats.reaped.Get(),
+		Errors:   s.stats.errors.Get(),
+
+		AcceptErrors: s.stats.acceptErrors.Get(),
+		BytesIn:      s.stats.bytesIn.Get(),
+		BytesOut:     s.stats.bytesOut.Get(),
+
+		HandleDuration:    s.stats.handleDuration.Get(),
+		MaxHandleDuration: s.stats.maxHandleDuration.Get(),
+	}
+}
+
+// StatsJSON returns the stats in JSON format.
+func (s *Server) StatsJSON() string {
+	data, _ := json.Marshal(s.Stats())
+	return string(data)
+}
+
+// PublishExpvar publishes the statistics of the server into expvar
+// with the name.
+//
+// Notice: it will panic if the name has been published.
+func (s *Server) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} { return s.Stats() }))
+}
+
+// ConnStats is the statistics of a connection.
+type ConnStats struct {
+	// The 64-bit atomic fields must stay first so that they are 8-byte
+	// aligned on the 32-bit platforms.
+	BytesIn   sync2.AtomicInt64
+	BytesOut  sync2.AtomicInt64
+	lastRead  sync2.AtomicInt64
+	lastWrite sync2.AtomicInt64
+
+	Start time.Time
+}
+
+func newConnStats() *ConnStats {
+	now := time.Now()
+	stats := &ConnStats{Start: now}
+	stats.lastRead.Set(now.UnixNano())
+	stats.lastWrite.Set(now.UnixNano())
+	return stats
+}
+
+// LastRead returns the last time when reading the data from the connection.
+func (c *ConnStats) LastRead() time.Time {
+	return time.Unix(0, c.lastRead.Get())
+}
+
+// LastWrite returns the last time when writing the data to the connection.
+func (c *ConnStats) LastWrite() time.Time {
+	return time.Unix(0, c.lastWrite.Get())
+}
+
+// IdleTime returns the duration since the last read or write.
+func (c *ConnStats) IdleTime() time.Duration {
+	last := c.lastRead.Get()
+	if w := c.lastWrite.Get(); w > last {
+		last = w
+	}
+	return time.Since(time.Unix(0, last))
+}
+
+type connStatsKey struct{}
+
+// ConnStatsFromContext returns the statistics of the connection from ctx,
+// which is passed to the handler by the server.
+//
+// Return nil if no statistics in ctx.
+func ConnStatsFromContext(ctx context.Context) *ConnStats {
+	stats, _ := ctx.Value(connStatsKey{}).(*ConnStats)
+	return stats
+}
+
+type countConn struct {
+	net.Conn
+	server *serverStats
+	conn   *ConnStats
+}
+
+func (c countConn) Read(p []byte) (n int, err error) {
+	n, err = c.Conn.Read(p)
+	c.countIn(int64(n))
+	return
+}
+
+func (c countConn) Write(p []byte) (n int, err error) {
+	n, err = c.Conn.Write(p)
+	c.countOut(int64(n))
+	return
+}
+
+func (c countConn) countOut(n int64) {
+	if n > 0 {
+		c.conn.BytesOut.Add(n)
+		c.conn.lastWrite.Set(time.Now().UnixNano())
+		c.server.bytesOut.Add(n)
+	}
+}
+
+func (c countConn) countIn(n int64) {
+	if n > 0 {
+		c.conn.BytesIn.Add(n)
+		c.conn.lastRead.Set(time.Now().UnixNano())
+		c.server.bytesIn.Add(n)
+	}
+}
+
+// ReadFrom implements the interface io.ReaderFrom, which forwards to
+// the underlying connection so that the fast path, such as sendfile or
+// splice of *net.TCPConn, is still used.
+func (c countConn) ReadFrom(r io.Reader) (n int64, err error) {
+	if rf, ok := c.Conn.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		n, err = io.Copy(c.Conn, r)
+	}
+	c.countOut(n)
+	return
+}
+
+// WriteTo implements the interface io.WriterTo, which forwards to
+// the underlying connection, the same as ReadFrom.
+func (c countConn) WriteTo(w io.Writer) (n int64, err error) {
+	if wt, ok := c.Conn.(io.WriterTo); ok {
+		n, err = wt.WriteTo(w)
+	} else {
+		n, err = io.Copy(w, c.Conn)
+	}
+	c.countIn(n)
+	return
+}
+
+// CloseWrite shuts down the write side of the underlying connection
+// if supported, so that the half-close still works through the wrapper.
+func (c countConn) CloseWrite() error {
+	return closeWrite(c.Conn)
+}
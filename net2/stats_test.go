@@ -0,0 +1,105 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-tools/io2"
+)
+
+func TestServerStats(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connStats := make(chan *ConnStats, 1)
+	s := NewServer(HandlerFunc(func(ctx context.Context, conn net.Conn) {
+		io.Copy(conn, conn)
+		connStats <- ConnStatsFromContext(ctx)
+	}))
+	go s.Serve(ln)
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 3)
+	conn.Write([]byte("abc"))
+	io.ReadFull(conn, buf)
+	conn.Close()
+
+	if cs := <-connStats; cs == nil || cs.BytesIn.Get() != 3 || cs.BytesOut.Get() != 3 {
+		t.Errorf("unexpected connection stats: %+v", cs)
+	}
+
+	for i := 0; i < 100 && s.Stats().Closed == 0; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	stats := s.Stats()
+	if stats.Accepted != 1 || stats.Active != 0 || stats.Closed != 1 ||
+		stats.BytesIn != 3 || stats.BytesOut != 3 {
+		t.Errorf("unexpected server stats: %+v", stats)
+	}
+
+	out := bytes.NewBuffer(nil)
+	stats.WritePrometheus(out, "server_")
+	if !strings.Contains(out.String(), "server_bytes_in_total 3\n") {
+		t.Error(out.String())
+	}
+}
+
+func TestServerConnReadFrom(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connStats := make(chan *ConnStats, 1)
+	s := NewServer(HandlerFunc(func(ctx context.Context, conn net.Conn) {
+		if _, ok := conn.(io.ReaderFrom); !ok {
+			t.Error("the connection does not implement io.ReaderFrom")
+		}
+		io2.CopyFast(conn, strings.NewReader("hello"))
+		connStats <- ConnStatsFromContext(ctx)
+	}))
+	go s.Serve(ln)
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 5)
+	if _, err = io.ReadFull(conn, buf); err != nil || string(buf) != "hello" {
+		t.Errorf("unexpected data '%s': %v", buf, err)
+	}
+
+	if cs := <-connStats; cs == nil || cs.BytesOut.Get() != 5 {
+		t.Errorf("unexpected connection stats: %+v", cs)
+	}
+}
@@ -15,13 +15,22 @@
 package net2
 
 import (
-	"fmt"
+	"context"
 	"net"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	"github.com/xgfone/go-tools/logs"
 )
 
-// TCPServerForever starts a TCP server. If starting successfully, never return.
+// TCPServerForever starts a TCP server. If starting successfully, never return
+// unless failing to accept the connection with a non-temporary error.
+//
+// The accept errors are logged by logs.DefaultLogger.
+//
+// Deprecated: use ListenAndServe instead, which returns the accept error
+// instead of logging it, and supports the middlewares and the options.
 func TCPServerForever(addr string, handler func(*net.TCPConn)) error {
 	_addr, err := net.ResolveTCPAddr("tcp", addr)
 	if err != nil {
@@ -34,17 +43,56 @@ func TCPServerForever(addr string, handler func(*net.TCPConn)) error {
 	}
 	defer ln.Close()
 
+	var backoff acceptBackoff
 	for {
 		conn, err := ln.AcceptTCP()
 		if err != nil {
-			fmt.Printf("AcceptTCP get an error: %v\n", err)
+			logs.DefaultLogger.Error("failed to accept the tcp connection", "err", err)
+			if !backoff.retry(err) {
+				return err
+			}
 		} else {
+			backoff.reset()
 			go handler(conn)
 		}
 	}
+}
+
+// acceptBackoff is used to back off exponentially when failing to accept
+// the connection with the temporary error, such as EMFILE, so that
+// the accept loop does not spin.
+type acceptBackoff struct {
+	delay  time.Duration
+	errors int
+
+	// max is the maximum number of the consecutive errors.
+	// 0 means no limit.
+	max int
+}
+
+// retry waits for a while and returns true if err is temporary
+// and does not reach the maximum number of the consecutive errors.
+func (b *acceptBackoff) retry(err error) bool {
+	if ne, ok := err.(net.Error); !ok || !ne.Temporary() {
+		return false
+	}
+
+	b.errors++
+	if b.max > 0 && b.errors > b.max {
+		return false
+	}
+
+	if b.delay == 0 {
+		b.delay = time.Millisecond * 5
+	} else if b.delay *= 2; b.delay > time.Second {
+		b.delay = time.Second
+	}
+	time.Sleep(b.delay)
+	return true
+}
 
-	// Never execute forever.
-	// return nil
+func (b *acceptBackoff) reset() {
+	b.delay, b.errors = 0, 0
 }
 
 // TCPServer is used to manage a TCP server.
@@ -81,12 +129,17 @@ func (s *TCPServer) Start() {
 	s.waits.Add(1)
 	defer s.waits.Done()
 
+	var backoff acceptBackoff
 	for {
 		conn, err := s.Listener.AcceptTCP()
 		if err != nil {
+			if !s.IsStopped() && backoff.retry(err) {
+				continue
+			}
 			return
 		}
 
+		backoff.reset()
 		s.waits.Add(1)
 		go func() {
 			defer func() {
@@ -129,3 +182,23 @@ func DialTCPByAddr(addr string) (*net.TCPConn, error) {
 	}
 	return conn.(*net.TCPConn), nil
 }
+
+// DialTCPTimeout is the same as DialTCPByAddr, but fails if the connection
+// is not established within timeout.
+func DialTCPTimeout(addr string, timeout time.Duration) (*net.TCPConn, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*net.TCPConn), nil
+}
+
+// DialTCPContext is the same as DialTCPByAddr, but may be cancelled by ctx.
+func DialTCPContext(ctx context.Context, addr string) (*net.TCPConn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*net.TCPConn), nil
+}
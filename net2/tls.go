@@ -0,0 +1,156 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"time"
+)
+
+// TLSOptions is the options of the TLS client.
+type TLSOptions struct {
+	// RootCAFiles and RootCAs are the CAs to verify the certificate
+	// of the server. If both are set, RootCAFiles are appended into a copy
+	// of RootCAs, which requires Go 1.19+, so RootCAs is never modified.
+	// If both are empty, use the CAs of the system.
+	RootCAFiles []string
+	RootCAs     *x509.CertPool
+
+	// CertFile and KeyFile are the pair of the certificate and the key
+	// of the client for the mutual TLS, which are optional.
+	CertFile string
+	KeyFile  string
+
+	// ServerName is used to override the server name for SNI and
+	// verifying the certificate of the server. The default is the host
+	// of the dialed address.
+	ServerName string
+
+	// InsecureSkipVerify disables to verify the certificate of the server.
+	InsecureSkipVerify bool
+
+	// MinVersion is the minimum TLS version, such as tls.VersionTLS12.
+	// The default is tls.VersionTLS12.
+	MinVersion uint16
+
+	// Timeout is the timeout to dial and finish the handshake.
+	// 0 means no timeout.
+	Timeout time.Duration
+}
+
+// loadCertPool returns a new pool containing the certificates in base
+// and the files, which does not modify base.
+func loadCertPool(base *x509.CertPool, files ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if base != nil {
+		if pool = cloneCertPool(base); pool == nil {
+			return nil, errors.New("cannot append RootCAFiles into RootCAs before Go 1.19")
+		}
+	}
+
+	for _, file := range files {
+		pem, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		} else if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificate in '%s'", file)
+		}
+	}
+	return pool, nil
+}
+
+// Config returns a new TLS config for the client by the options.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+		MinVersion:         o.MinVersion,
+		RootCAs:            o.RootCAs,
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+
+	if len(o.RootCAFiles) > 0 {
+		pool, err := loadCertPool(o.RootCAs, o.RootCAFiles...)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// DialTLS is equal to DialTLSContext(context.Background(), addr, opts).
+func DialTLS(addr string, opts TLSOptions) (*tls.Conn, error) {
+	return DialTLSContext(context.Background(), addr, opts)
+}
+
+// DialTLSContext dials a TLS connection to the TCP address addr
+// with the options, and finishes the handshake.
+func DialTLSContext(ctx context.Context, addr string, opts TLSOptions) (*tls.Conn, error) {
+	config, err := opts.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		config.ServerName = host
+	}
+
+	if opts.Timeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err = tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDialTLS(t *testing.T) {
+	dir := generateCerts(t)
+	defer os.RemoveAll(dir)
+
+	config, err := NewServerTLSConfig(filepath.Join(dir, "server.crt"),
+		filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(echoHandler, WithTLSConfig(config))
+	go s.Serve(ln)
+	defer s.Stop()
+
+	opts := TLSOptions{
+		RootCAFiles: []string{filepath.Join(dir, "ca.crt")},
+		CertFile:    filepath.Join(dir, "client.crt"),
+		KeyFile:     filepath.Join(dir, "client.key"),
+		Timeout:     time.Second,
+	}
+	conn, err := DialTLS(ln.Addr().String(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 3)
+	conn.Write([]byte("abc"))
+	if _, err = io.ReadFull(conn, buf); err != nil || string(buf) != "abc" {
+		t.Errorf("buf=%s, err=%v", string(buf), err)
+	}
+
+	// The server name does not match the certificate.
+	opts.ServerName = "www.example.com"
+	if _, err = DialTLS(ln.Addr().String(), opts); err == nil {
+		t.Error("expect an error")
+	}
+}
@@ -16,8 +16,124 @@ package net2
 
 import (
 	"net"
+	"runtime"
+	"sync"
+	"sync/atomic"
 )
 
+// UDPHandler is the handler to handle the UDP packet from addr.
+//
+// If the returned response is not empty, it will be sent back to addr.
+// If returning an error, no response is sent back.
+//
+// packet is the reused buffer of the worker, which is only valid until
+// the handler returns, so copy it if it is used after that.
+type UDPHandler func(addr *net.UDPAddr, packet []byte) (response []byte, err error)
+
+// UDPServerForever starts a UDP server. If starting successfully, never return
+// unless failing to read the packet.
+func UDPServerForever(addr string, handler UDPHandler) error {
+	s, err := NewUDPServerFromAddr(addr, handler)
+	if err != nil {
+		return err
+	}
+	return s.Start()
+}
+
+// UDPServer is used to manage a UDP server.
+type UDPServer struct {
+	Conn    *net.UDPConn
+	Handler UDPHandler
+
+	// BufferSize is the size of the buffer to read the packet.
+	// The default is 65536, which is enough for any UDP packet.
+	BufferSize int
+
+	// Workers is the number of the goroutines to read and handle the packets.
+	// The default is the number of the CPUs.
+	Workers int
+
+	waits  sync.WaitGroup
+	closed int32
+	once   sync.Once
+	err    error
+}
+
+// NewUDPServer returns a new UDPServer.
+func NewUDPServer(conn *net.UDPConn, handler UDPHandler) *UDPServer {
+	return &UDPServer{Conn: conn, Handler: handler}
+}
+
+// NewUDPServerFromAddr returns a new UDPServer listening on addr.
+func NewUDPServerFromAddr(addr string, handler UDPHandler) (*UDPServer, error) {
+	conn, err := ListenUDP(addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewUDPServer(conn, handler), nil
+}
+
+// Start starts the UDP server, which will block until the server is stopped.
+//
+// If a worker fails to read the packet with a non-temporary error, the server
+// will be stopped and Start returns the error. Or return nil after Stop.
+func (s *UDPServer) Start() error {
+	size := s.BufferSize
+	if size < 1 {
+		size = 65536
+	}
+
+	workers := s.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	s.waits.Add(workers)
+	for i := 0; i < workers; i++ {
+		go s.serve(make([]byte, size))
+	}
+	s.waits.Wait()
+	return s.err
+}
+
+func (s *UDPServer) serve(buf []byte) {
+	defer s.waits.Done()
+	for {
+		n, addr, err := s.Conn.ReadFromUDP(buf)
+		if err != nil {
+			if s.IsStopped() {
+				return
+			} else if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				continue
+			}
+
+			s.once.Do(func() { s.err = err; s.Stop() })
+			return
+		}
+
+		if resp, err := s.Handler(addr, buf[:n]); err == nil && len(resp) > 0 {
+			s.Conn.WriteToUDP(resp, addr)
+		}
+	}
+}
+
+// Stop stops the UDP server.
+func (s *UDPServer) Stop() {
+	if atomic.CompareAndSwapInt32(&s.closed, 0, 1) {
+		s.Conn.Close()
+	}
+}
+
+// Wait waits until all the workers exit.
+func (s *UDPServer) Wait() {
+	s.waits.Wait()
+}
+
+// IsStopped reports whether the UDP server is stopped
+func (s *UDPServer) IsStopped() bool {
+	return atomic.LoadInt32(&s.closed) == 1
+}
+
 // ListenUDP listens UDP on addr, then returns a UDP connection.
 func ListenUDP(addr string) (*net.UDPConn, error) {
 	_addr, err := net.ResolveUDPAddr("udp", addr)
@@ -0,0 +1,80 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestUDPServer(t *testing.T) {
+	s, err := NewUDPServerFromAddr("127.0.0.1:0", func(addr *net.UDPAddr, p []byte) ([]byte, error) {
+		return bytes.ToUpper(p), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Workers = 2
+	go s.Start()
+	defer s.Stop()
+
+	conn, err := DialUDPByAddr(s.Conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(time.Second))
+	if _, err = conn.Write([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 16)
+	if n, err := conn.Read(buf); err != nil {
+		t.Error(err)
+	} else if string(buf[:n]) != "ABC" {
+		t.Errorf("expect 'ABC', but got '%s'", string(buf[:n]))
+	}
+}
+
+func TestUDPServerStartError(t *testing.T) {
+	handler := func(*net.UDPAddr, []byte) ([]byte, error) { return nil, nil }
+	s, err := NewUDPServerFromAddr("127.0.0.1:0", handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- s.Start() }()
+	time.Sleep(time.Millisecond * 10)
+	s.Conn.Close() // Close it by others instead of Stop.
+	if err = <-errc; err == nil {
+		t.Error("expect an error")
+	} else if !s.IsStopped() {
+		t.Error("expect the server to be stopped")
+	}
+
+	if s, err = NewUDPServerFromAddr("127.0.0.1:0", handler); err != nil {
+		t.Fatal(err)
+	}
+	go func() { errc <- s.Start() }()
+	time.Sleep(time.Millisecond * 10)
+	s.Stop()
+	if err = <-errc; err != nil {
+		t.Errorf("expect nil, but got %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ErrInvalidPacket is returned when the packet is invalid.
+var ErrInvalidPacket = errors.New("invalid packet")
+
+// udpTxIDSize is the size of the transaction ID prefixed to the packet.
+const udpTxIDSize = 4
+
+// TxIDUDPHandler wraps the handler to handle the packet prefixed
+// by the 4-byte transaction ID in big endian, which is used by UDPClient.
+// The transaction ID is stripped before calling the handler, and prefixed
+// to the response.
+//
+// Notice: the handler may be called more than once for the same request
+// retransmitted by the client, so it should be idempotent.
+func TxIDUDPHandler(handler UDPHandler) UDPHandler {
+	return func(addr *net.UDPAddr, packet []byte) ([]byte, error) {
+		if len(packet) < udpTxIDSize {
+			return nil, ErrInvalidPacket
+		}
+
+		resp, err := handler(addr, packet[udpTxIDSize:])
+		if err != nil || len(resp) == 0 {
+			return nil, err
+		}
+
+		buf := make([]byte, udpTxIDSize+len(resp))
+		copy(buf, packet[:udpTxIDSize])
+		copy(buf[udpTxIDSize:], resp)
+		return buf, nil
+	}
+}
+
+// UDPClient is a request/response client over UDP, which retransmits
+// the request with the exponential backoff until receiving the response.
+//
+// Each request is prefixed by a 4-byte transaction ID in big endian,
+// and the response must carry the same one, so the server should handle
+// the packets by TxIDUDPHandler. The responses arriving late, such as
+// the duplicates for the retransmitted requests, are discarded.
+type UDPClient struct {
+	// Timeout is the timeout waiting for the response of the first attempt,
+	// which is doubled for each retransmission. The default is 500ms.
+	Timeout time.Duration
+
+	// MaxRetries is the maximum number of the retransmissions.
+	// The default is 3.
+	MaxRetries int
+
+	bufSize int
+	conn    *net.UDPConn
+	txid    uint32
+	lock    sync.Mutex
+	pending map[uint32]chan []byte
+	closed  chan struct{}
+	once    sync.Once
+}
+
+// NewUDPClient returns a new UDPClient connecting to the UDP address addr.
+//
+// bufferSize is the size of the buffer to read the response.
+// If it is equal to or less than 0, it is 65536 by default.
+func NewUDPClient(addr string, bufferSize int) (*UDPClient, error) {
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialUDP("udp", nil, raddr)
+	if err != nil {
+		return nil, err
+	}
+	return NewUDPClientFromConn(conn, bufferSize), nil
+}
+
+// NewUDPClientFromConn returns a new UDPClient with the connected conn.
+//
+// bufferSize is the same as NewUDPClient. If conn is closed by others,
+// the client is closed as well.
+func NewUDPClientFromConn(conn *net.UDPConn, bufferSize int) *UDPClient {
+	if bufferSize <= 0 {
+		bufferSize = 65536
+	}
+
+	c := &UDPClient{
+		bufSize: bufferSize,
+		conn:    conn,
+		pending: make(map[uint32]chan []byte),
+		closed:  make(chan struct{}),
+	}
+	go c.recvLoop()
+	return c
+}
+
+// Close closes the client.
+func (c *UDPClient) Close() (err error) {
+	c.once.Do(func() {
+		close(c.closed)
+		err = c.conn.Close()
+	})
+	return
+}
+
+func (c *UDPClient) recvLoop() {
+	buf := make([]byte, c.bufSize)
+	for {
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			select {
+			case <-c.closed:
+				return
+			default:
+			}
+
+			// Some errors of the UDP socket are per packet, such as ICMP
+			// port unreachable, so ignore them and let the request
+			// retransmit or timeout. But the others, such as the connection
+			// closed by others, are fatal.
+			if isUDPPacketError(err) {
+				continue
+			}
+			c.Close()
+			return
+		} else if n < udpTxIDSize {
+			continue
+		}
+
+		txid := binary.BigEndian.Uint32(buf)
+		c.lock.Lock()
+		ch, ok := c.pending[txid]
+		delete(c.pending, txid)
+		c.lock.Unlock()
+		if ok {
+			ch <- append([]byte(nil), buf[udpTxIDSize:n]...)
+		}
+	}
+}
+
+func isUDPPacketError(err error) bool {
+	if ne, ok := err.(net.Error); ok && (ne.Timeout() || ne.Temporary()) {
+		return true
+	}
+
+	if oe, ok := err.(*net.OpError); ok {
+		err = oe.Err
+		if se, ok := err.(*os.SyscallError); ok {
+			err = se.Err
+		}
+	}
+	return err == syscall.ECONNREFUSED
+}
+
+// Request is equal to RequestContext(context.Background(), data).
+func (c *UDPClient) Request(data []byte) ([]byte, error) {
+	return c.RequestContext(context.Background(), data)
+}
+
+// RequestContext sends the request and waits for the response,
+// which retransmits the request when timeout.
+func (c *UDPClient) RequestContext(ctx context.Context, data []byte) ([]byte, error) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = time.Millisecond * 500
+	}
+	maxRetries := c.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+
+	txid := atomic.AddUint32(&c.txid, 1)
+	packet := make([]byte, udpTxIDSize+len(data))
+	binary.BigEndian.PutUint32(packet, txid)
+	copy(packet[udpTxIDSize:], data)
+
+	ch := make(chan []byte, 1)
+	c.lock.Lock()
+	c.pending[txid] = ch
+	c.lock.Unlock()
+	defer func() {
+		c.lock.Lock()
+		delete(c.pending, txid)
+		c.lock.Unlock()
+	}()
+
+	for i := 0; i <= maxRetries; i++ {
+		if _, err := c.conn.Write(packet); err != nil {
+			return nil, err
+		}
+
+		timer := time.NewTimer(timeout)
+		select {
+		case resp := <-ch:
+			timer.Stop()
+			return resp, nil
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-c.closed:
+			timer.Stop()
+			return nil, ErrConnClosed
+		case <-timer.C:
+			timeout *= 2
+		}
+	}
+
+	return nil, timeoutError{}
+}
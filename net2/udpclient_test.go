@@ -0,0 +1,91 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net2
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUDPClient(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Drop the first packet to force the client to retransmit.
+	var lock sync.Mutex
+	var count int
+	s := NewUDPServer(conn, TxIDUDPHandler(func(addr *net.UDPAddr, packet []byte) ([]byte, error) {
+		lock.Lock()
+		count++
+		drop := count == 1
+		lock.Unlock()
+		if drop {
+			return nil, nil
+		}
+		return append([]byte("echo:"), packet...), nil
+	}))
+	go s.Start()
+	defer s.Stop()
+
+	client, err := NewUDPClient(conn.LocalAddr().String(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.Timeout = time.Millisecond * 50
+
+	resp, err := client.Request([]byte("abc"))
+	if err != nil {
+		t.Fatal(err)
+	} else if string(resp) != "echo:abc" {
+		t.Errorf("unexpected response '%s'", string(resp))
+	}
+
+	lock.Lock()
+	if count != 2 {
+		t.Errorf("expect 2 requests, but got %d", count)
+	}
+	lock.Unlock()
+}
+
+func TestUDPClientConnClosedByOthers(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	cconn, err := net.DialUDP("udp", nil, conn.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewUDPClientFromConn(cconn, 0)
+	cconn.Close()
+
+	select {
+	case <-client.closed:
+	case <-time.After(time.Second):
+		t.Fatal("the client is not closed")
+	}
+
+	if _, err = client.Request([]byte("abc")); err == nil {
+		t.Error("expect an error")
+	}
+}
@@ -128,3 +128,113 @@ func IPIsOnHost(ip string) bool {
 func IsIP(ip string) bool {
 	return net.ParseIP(ip) != nil
 }
+
+var privateIPNets, _ = parseIPNets([]string{
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"fc00::/7",
+})
+
+// IsPrivateIP returns true if ip is a private address by RFC 1918
+// for IPv4 or RFC 4193 for IPv6, or returns false.
+func IsPrivateIP(ip string) bool {
+	_ip := net.ParseIP(ip)
+	return _ip != nil && containsIP(privateIPNets, _ip)
+}
+
+// IsLoopbackIP returns true if ip is a loopback address, or returns false.
+func IsLoopbackIP(ip string) bool {
+	_ip := net.ParseIP(ip)
+	return _ip != nil && _ip.IsLoopback()
+}
+
+// IsLinkLocalIP returns true if ip is a link-local unicast or multicast
+// address, or returns false.
+func IsLinkLocalIP(ip string) bool {
+	_ip := net.ParseIP(ip)
+	return _ip != nil && (_ip.IsLinkLocalUnicast() || _ip.IsLinkLocalMulticast())
+}
+
+// GetInterfaceIPs returns the ips of all the network interfaces which are up,
+// the key of which is the name of the interface.
+func GetInterfaceIPs() (ips map[string][]string, err error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return
+	}
+
+	ips = make(map[string][]string, len(ifaces))
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+
+		var _ips []string
+		if _ips, err = getIPByName(iface.Name, false); err != nil {
+			return nil, err
+		}
+		ips[iface.Name] = _ips
+	}
+	return
+}
+
+func getFirstIP(ipv4 bool) (string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", err
+	}
+
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+
+		ips, err := getIPByName(iface.Name, false)
+		if err != nil {
+			return "", err
+		}
+
+		for _, ip := range ips {
+			_ip := net.ParseIP(ip)
+			if _ip == nil || _ip.IsLoopback() || _ip.IsLinkLocalUnicast() {
+				continue
+			} else if (_ip.To4() != nil) == ipv4 {
+				return ip, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("not found the non-loopback ip")
+}
+
+// GetFirstIPv4 returns the first non-loopback and non-link-local IPv4
+// on the network interfaces which are up.
+func GetFirstIPv4() (string, error) {
+	return getFirstIP(true)
+}
+
+// GetFirstIPv6 returns the first non-loopback and non-link-local IPv6
+// on the network interfaces which are up.
+func GetFirstIPv6() (string, error) {
+	return getFirstIP(false)
+}
+
+// GetOutboundIP returns the local ip used to access the outside,
+// which is the primary ip to advertise generally.
+//
+// It does not send any packet, but only lets the kernel choose the route
+// to the target, which is "8.8.8.8:80" by default.
+func GetOutboundIP(target ...string) (string, error) {
+	addr := "8.8.8.8:80"
+	if len(target) > 0 && target[0] != "" {
+		addr = target[0]
+	}
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
+}
@@ -57,3 +57,29 @@ func TestIsIP(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestIPKind(t *testing.T) {
+	if !IsPrivateIP("10.1.2.3") || !IsPrivateIP("192.168.0.1") || IsPrivateIP("8.8.8.8") {
+		t.Fail()
+	}
+
+	if !IsLoopbackIP("127.0.0.1") || !IsLoopbackIP("::1") || IsLoopbackIP("10.0.0.1") {
+		t.Fail()
+	}
+
+	if !IsLinkLocalIP("169.254.1.1") || !IsLinkLocalIP("fe80::1") || IsLinkLocalIP("10.0.0.1") {
+		t.Fail()
+	}
+}
+
+func TestGetInterfaceIPs(t *testing.T) {
+	if ips, err := GetInterfaceIPs(); err != nil || len(ips) == 0 {
+		t.Fail()
+	}
+}
+
+func TestGetOutboundIP(t *testing.T) {
+	if ip, err := GetOutboundIP("127.0.0.1:80"); err != nil || ip != "127.0.0.1" {
+		t.Errorf("ip=%s, err=%v", ip, err)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package option
+
+// Zip returns Some([]interface{}{a.Value(), b.Value()}) if both a and b
+// are Some. Or return None.
+func Zip(a, b Option) Option {
+	return Collect([]Option{a, b})
+}
+
+// Collect returns Some([]interface{}) with the values of all the options
+// in order if all of them are Some. Or return None.
+func Collect(opts []Option) Option {
+	values := make([]interface{}, len(opts))
+	for i, o := range opts {
+		if o == nil || o.IsNone() {
+			return None()
+		}
+		values[i] = o.Value()
+	}
+	return Some(values)
+}
+
+// FirstSome returns the first option which is Some. Or return None.
+//
+// It's used to express the fallback chain, for example,
+//
+//	FirstSome(FromFlag(nil, "addr"), FromEnv("ADDR"), Some(":80"))
+func FirstSome(opts ...Option) Option {
+	for _, o := range opts {
+		if o != nil && o.IsSome() {
+			return o
+		}
+	}
+	return None()
+}
@@ -0,0 +1,50 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package option
+
+import "testing"
+
+func TestZip(t *testing.T) {
+	if o := Zip(Some(1), Some("a")); o.IsNone() {
+		t.Fail()
+	} else if vs := o.Interfaces(); len(vs) != 2 || vs[0].(int) != 1 || vs[1].(string) != "a" {
+		t.Error(vs)
+	}
+
+	if Zip(Some(1), NONE).IsSome() || Zip(NONE, Some(1)).IsSome() {
+		t.Fail()
+	}
+}
+
+func TestCollect(t *testing.T) {
+	if vs := Collect([]Option{Some(1), Some(2), Some(3)}).Interfaces(); len(vs) != 3 || vs[2].(int) != 3 {
+		t.Error(vs)
+	}
+	if Collect([]Option{Some(1), nil}).IsSome() || Collect(nil).IsNone() {
+		t.Fail()
+	}
+}
+
+func TestFirstSome(t *testing.T) {
+	if o := FirstSome(NONE, nil, NamedSome("b", 2), Some(3)); o.Int() != 2 {
+		t.Error(o)
+	} else if no, ok := o.(NamedOption); !ok || no.Name() != "b" {
+		t.Error(o)
+	}
+
+	if FirstSome().IsSome() || FirstSome(NONE, NamedNone("a")).IsSome() {
+		t.Fail()
+	}
+}
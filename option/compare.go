@@ -0,0 +1,84 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package option
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Equal reports whether the two options are equal, which compares
+// the inner values deeply. Two None are equal.
+//
+// If both are NamedOption, their names must be equal, too.
+func Equal(a, b Option) bool {
+	if na, ok := a.(NamedOption); ok {
+		if nb, ok := b.(NamedOption); ok && na.Name() != nb.Name() {
+			return false
+		}
+	}
+
+	aNone, bNone := isNone(a), isNone(b)
+	if aNone || bNone {
+		return aNone == bNone
+	}
+	return reflect.DeepEqual(a.Value(), b.Value())
+}
+
+// Less reports whether a is less than b, which treats None as the smallest
+// and compares the inner values by less when both are Some.
+func Less(a, b Option, less func(first, second interface{}) bool) bool {
+	if isNone(b) {
+		return false
+	} else if isNone(a) {
+		return true
+	}
+	return less(a.Value(), b.Value())
+}
+
+// Sort sorts the options stably by less, and None is the smallest.
+func Sort(opts []Option, less func(first, second interface{}) bool) {
+	sort.SliceStable(opts, func(i, j int) bool { return Less(opts[i], opts[j], less) })
+}
+
+// SortNamed sorts the named options stably by the name.
+func SortNamed(opts []NamedOption) {
+	sort.SliceStable(opts, func(i, j int) bool { return opts[i].Name() < opts[j].Name() })
+}
+
+// Dedup removes the duplicate options by Equal, which keeps the first one
+// and the original order, then returns the deduplicated options
+// which share the underlying array with opts.
+func Dedup(opts []Option) []Option {
+	results := opts[:0]
+	for _, o := range opts {
+		var exist bool
+		for _, r := range results {
+			if Equal(o, r) {
+				exist = true
+				break
+			}
+		}
+
+		if !exist {
+			results = append(results, o)
+		}
+	}
+	return results
+}
+
+func isNone(o Option) bool {
+	return o == nil || o.IsNone()
+}
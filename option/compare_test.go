@@ -0,0 +1,44 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package option
+
+import "testing"
+
+func TestEqual(t *testing.T) {
+	if !Equal(NONE, nil) || !Equal(Some([]int{1}), Some([]int{1})) || Equal(Some(1), NONE) ||
+		Equal(Some(1), Some(2)) || Equal(NamedSome("a", 1), NamedSome("b", 1)) ||
+		!Equal(NamedSome("a", 1), Some(1)) {
+		t.Fail()
+	}
+}
+
+func TestSortAndDedup(t *testing.T) {
+	less := func(a, b interface{}) bool { return a.(int) < b.(int) }
+	if !Less(NONE, Some(1), less) || Less(Some(1), NONE, less) || Less(NONE, NONE, less) {
+		t.Fail()
+	}
+
+	opts := []Option{Some(3), NONE, Some(1), Some(3), NONE, Some(2)}
+	Sort(opts, less)
+	opts = Dedup(opts)
+	if len(opts) != 4 || opts[0].IsSome() || opts[1].Int() != 1 || opts[3].Int() != 3 {
+		t.Error(opts)
+	}
+
+	named := []NamedOption{NamedSome("c", 1), NamedSome("a", 2), NamedSome("b", 3)}
+	if SortNamed(named); named[0].Name() != "a" || named[2].Name() != "c" {
+		t.Error(named)
+	}
+}
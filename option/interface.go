@@ -14,6 +14,8 @@
 
 package option
 
+import "time"
+
 // Option is an interface which is used to denote the Option type.
 type Option interface {
 	IsSome() bool
@@ -25,6 +27,12 @@ type Option interface {
 	// Some returns the inner value, but panic if it's a None.
 	Some() interface{}
 
+	// Must is the alias of Some.
+	Must() interface{}
+
+	// Expect returns the inner value, but panic with msg if it's a None.
+	Expect(msg string) interface{}
+
 	// None check whether the inner value is None and panic if it's not a None.
 	None()
 
@@ -38,12 +46,31 @@ type Option interface {
 	// rewrite it, such as BoolOption, StringOption, IntOption, etc.
 	Scan(src interface{}) error
 
+	// ScanTo converts the inner value and assigns it to what dst points to.
+	ScanTo(dst interface{}) error
+
 	// ConvertTo converts the value by convert then assigns the result to the inner.
 	ConvertTo(value interface{}, convert func(interface{}) (interface{}, error)) error
 
 	// SomeOr returns the inner value if it's not None. Or return v.
 	SomeOr(v interface{}) interface{}
 
+	// GetOr is the alias of SomeOr.
+	GetOr(v interface{}) interface{}
+
+	// GetOrFunc returns the inner value if it's not None.
+	// Or return the result of f.
+	GetOrFunc(f func() interface{}) interface{}
+
+	// OrElse returns itself if it's not None. Or return other.
+	OrElse(other Option) Option
+
+	// Match calls some with the inner value if it's not None. Or call none.
+	Match(some func(v interface{}), none func())
+
+	// MatchV is the same as Match, but returns the result of some or none.
+	MatchV(some func(v interface{}) interface{}, none func() interface{}) interface{}
+
 	// String implements the interface fmt.Stringer.
 	String() string
 
@@ -65,6 +92,8 @@ type Option interface {
 	Uint64() uint64
 	Float32() float32
 	Float64() float64
+	Duration() time.Duration
+	Time() time.Time
 	Strs() []string
 	Interfaces() []interface{}
 	Map() map[string]interface{}
@@ -86,6 +115,8 @@ type Option interface {
 	IsUint64() bool
 	IsFloat32() bool
 	IsFloat64() bool
+	IsDuration() bool
+	IsTime() bool
 	IsSignedInteger() bool
 	IsUnsignedInteger() bool
 	IsInteger() bool
@@ -111,4 +142,16 @@ type Option interface {
 	ToUint64() (uint64, error)
 	ToFloat32() (float32, error)
 	ToFloat64() (float64, error)
+	ToDuration() (time.Duration, error)
+	ToTime(layout ...string) (time.Time, error)
+
+	// Convert the inner value to the specific type. Or panic if failed.
+	MustToString() string
+	MustToBool() bool
+	MustToInt() int
+	MustToInt64() int64
+	MustToUint64() uint64
+	MustToFloat64() float64
+	MustToDuration() time.Duration
+	MustToTime(layout ...string) time.Time
 }
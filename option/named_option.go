@@ -50,6 +50,25 @@ func (o NamedOption) Name() string {
 	return o.name
 }
 
+// Some returns the inner value, but panic with the name if it's a None.
+func (o NamedOption) Some() interface{} {
+	return o.Expect("the value is None")
+}
+
+// Must is the alias of Some.
+func (o NamedOption) Must() interface{} {
+	return o.Some()
+}
+
+// Expect returns the inner value, but panic with the name and msg
+// if it's a None.
+func (o NamedOption) Expect(msg string) interface{} {
+	if o.Option.IsNone() {
+		panic(fmt.Errorf("option '%s': %s", o.name, msg))
+	}
+	return o.Option.Value()
+}
+
 // String implements the interface fmt.Stringer.
 func (o NamedOption) String() string {
 	return fmt.Sprintf("Option(name='%s', value=%v)", o.name, o.Option.Value())
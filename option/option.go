@@ -16,9 +16,12 @@
 package option
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
+	"time"
 
 	"github.com/xgfone/go-tools/types"
 )
@@ -77,6 +80,23 @@ func (o *option) Scan(src interface{}) error {
 	return nil
 }
 
+// ScanTo converts the inner value and assigns it to what dst points to,
+// such as *int, *string, *time.Duration, *[]string, etc.
+//
+// If dst has implemented the interface sql.Scanner, it will call its Scan
+// method with the inner value. If it's a None, dst will be set to ZERO.
+func (o *option) ScanTo(dst interface{}) error {
+	if s, ok := dst.(sql.Scanner); ok {
+		return s.Scan(o.value)
+	}
+
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("the destination is not a non-nil pointer")
+	}
+	return assign(v.Elem(), o.value)
+}
+
 // ConvertTo converts the value by convert then assigns the result to the inner.
 func (o *option) ConvertTo(value interface{}, convert func(interface{}) (interface{}, error)) error {
 	v, err := convert(value)
@@ -99,6 +119,19 @@ func (o *option) Some() interface{} {
 	return o.value
 }
 
+// Must is the alias of Some.
+func (o *option) Must() interface{} {
+	return o.Some()
+}
+
+// Expect returns the inner value, but panic with msg if it's a None.
+func (o *option) Expect(msg string) interface{} {
+	if o.value == nil {
+		panic(errors.New(msg))
+	}
+	return o.value
+}
+
 // None check whether the inner value is None and panic if it's not a None.
 func (o *option) None() {
 	if o.value != nil {
@@ -114,6 +147,54 @@ func (o *option) SomeOr(v interface{}) interface{} {
 	return o.value
 }
 
+// GetOr is the alias of SomeOr.
+func (o *option) GetOr(v interface{}) interface{} {
+	return o.SomeOr(v)
+}
+
+// GetOrFunc returns the inner value if it's not None. Or return the result of f.
+func (o *option) GetOrFunc(f func() interface{}) interface{} {
+	if o.value == nil {
+		return f()
+	}
+	return o.value
+}
+
+// OrElse returns itself if it's not None. Or return other.
+func (o *option) OrElse(other Option) Option {
+	if o.value == nil {
+		return other
+	}
+	return o
+}
+
+// Match calls some with the inner value if it's not None. Or call none.
+//
+// some or none may be nil, which does nothing.
+func (o *option) Match(some func(v interface{}), none func()) {
+	if o.value == nil {
+		if none != nil {
+			none()
+		}
+	} else if some != nil {
+		some(o.value)
+	}
+}
+
+// MatchV is the same as Match, but returns the result of some or none.
+//
+// some or none may be nil, which returns nil.
+func (o *option) MatchV(some func(v interface{}) interface{}, none func() interface{}) interface{} {
+	if o.value == nil {
+		if none != nil {
+			return none()
+		}
+	} else if some != nil {
+		return some(o.value)
+	}
+	return nil
+}
+
 // String implements the interface fmt.Stringer.
 func (o *option) String() string {
 	return fmt.Sprintf("Option(%v)", o.value)
@@ -204,6 +285,16 @@ func (o *option) Float64() float64 {
 	return o.value.(float64)
 }
 
+// Duration returns the inner time.Duration value. Or panic.
+func (o *option) Duration() time.Duration {
+	return o.value.(time.Duration)
+}
+
+// Time returns the inner time.Time value. Or panic.
+func (o *option) Time() time.Time {
+	return o.value.(time.Time)
+}
+
 // Strs returns the inner []string value. Or panic.
 func (o *option) Strs() []string {
 	return o.value.([]string)
@@ -405,6 +496,18 @@ func (o *option) IsNumber() bool {
 	return false
 }
 
+// IsDuration reports whether the type of the value is time.Duration.
+func (o *option) IsDuration() bool {
+	_, ok := o.value.(time.Duration)
+	return ok
+}
+
+// IsTime reports whether the type of the value is time.Time.
+func (o *option) IsTime() bool {
+	_, ok := o.value.(time.Time)
+	return ok
+}
+
 // IsStrs reports whether the type of the value is []string.
 func (o *option) IsStrs() bool {
 	_, ok := o.value.([]string)
@@ -507,3 +610,58 @@ func (o *option) ToFloat32() (float32, error) {
 func (o *option) ToFloat64() (float64, error) {
 	return types.ToFloat64(o.value)
 }
+
+// ToDuration converts the inner value to time.Duration.
+//
+// The string is parsed by time.ParseDuration, and the integer is considered
+// as the number of the nanoseconds.
+func (o *option) ToDuration() (time.Duration, error) {
+	return types.ToDuration(o.value)
+}
+
+// ToTime converts the inner value to time.Time.
+//
+// Notice: the layout is time.RFC3339Nano by default.
+func (o *option) ToTime(layout ...string) (time.Time, error) {
+	return types.ToTime(o.value, layout...)
+}
+
+// MustToString is equal to ToString, but panic if there is an error.
+func (o *option) MustToString() string {
+	return types.MustToString(o.value)
+}
+
+// MustToBool is equal to ToBool, but panic if there is an error.
+func (o *option) MustToBool() bool {
+	return types.MustToBool(o.value)
+}
+
+// MustToInt is equal to ToInt, but panic if there is an error.
+func (o *option) MustToInt() int {
+	return int(types.MustToInt64(o.value))
+}
+
+// MustToInt64 is equal to ToInt64, but panic if there is an error.
+func (o *option) MustToInt64() int64 {
+	return types.MustToInt64(o.value)
+}
+
+// MustToUint64 is equal to ToUint64, but panic if there is an error.
+func (o *option) MustToUint64() uint64 {
+	return types.MustToUint64(o.value)
+}
+
+// MustToFloat64 is equal to ToFloat64, but panic if there is an error.
+func (o *option) MustToFloat64() float64 {
+	return types.MustToFloat64(o.value)
+}
+
+// MustToDuration is equal to ToDuration, but panic if there is an error.
+func (o *option) MustToDuration() time.Duration {
+	return types.MustToDuration(o.value)
+}
+
+// MustToTime is equal to ToTime, but panic if there is an error.
+func (o *option) MustToTime(layout ...string) time.Time {
+	return types.MustToTime(o.value, layout...)
+}
@@ -15,7 +15,9 @@
 package option
 
 import (
+	"database/sql"
 	"testing"
+	"time"
 )
 
 func TestOption(t *testing.T) {
@@ -37,3 +39,135 @@ func TestNamedOption(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestOptionGetOr(t *testing.T) {
+	if NONE.GetOr(123).(int) != 123 || Some(456).GetOr(123).(int) != 456 {
+		t.Fail()
+	}
+
+	f := func() interface{} { return 123 }
+	if NONE.GetOrFunc(f).(int) != 123 || Some(456).GetOrFunc(f).(int) != 456 {
+		t.Fail()
+	}
+
+	if NONE.OrElse(Some(123)).Int() != 123 || Some(456).OrElse(Some(123)).Int() != 456 {
+		t.Fail()
+	}
+}
+
+func TestOptionConvert(t *testing.T) {
+	if v, err := Some("1m").ToDuration(); err != nil || v != time.Minute {
+		t.Error(v, err)
+	}
+	if v, err := Some("2019-01-02 03:04:05").ToTime("2006-01-02 15:04:05"); err != nil {
+		t.Error(err)
+	} else if v.Year() != 2019 || v.Second() != 5 {
+		t.Error(v)
+	}
+
+	if Some("123").MustToInt() != 123 || Some("123").MustToInt64() != 123 ||
+		Some("123").MustToUint64() != 123 || Some("1.5").MustToFloat64() != 1.5 ||
+		!Some("on").MustToBool() || Some(123).MustToString() != "123" ||
+		Some("2s").MustToDuration() != 2*time.Second {
+		t.Fail()
+	}
+
+	if !Some(time.Second).IsDuration() || Some(time.Second).Duration() != time.Second {
+		t.Fail()
+	}
+	if now := time.Now(); !Some(now).IsTime() || !Some(now).Time().Equal(now) {
+		t.Fail()
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expect a panic")
+		}
+	}()
+	Some("abc").MustToInt()
+}
+
+func TestOptionScanTo(t *testing.T) {
+	var i int
+	var d time.Duration
+	var ss []string
+	var ns sql.NullString
+
+	if err := Some("123").ScanTo(&i); err != nil || i != 123 {
+		t.Error(i, err)
+	}
+	if err := Some("1s").ScanTo(&d); err != nil || d != time.Second {
+		t.Error(d, err)
+	}
+	if err := Some("a,b").ScanTo(&ss); err != nil || len(ss) != 2 || ss[1] != "b" {
+		t.Error(ss, err)
+	}
+	if err := Some("abc").ScanTo(&ns); err != nil || !ns.Valid || ns.String != "abc" {
+		t.Error(ns, err)
+	}
+	if err := NONE.ScanTo(&ns); err != nil || ns.Valid {
+		t.Error(ns, err)
+	}
+	if err := NONE.ScanTo(&i); err != nil || i != 0 {
+		t.Error(i, err)
+	}
+
+	if err := Some("abc").ScanTo(&i); err == nil {
+		t.Error("expect an error")
+	}
+	if err := Some(1).ScanTo(i); err == nil {
+		t.Error("expect an error")
+	}
+}
+
+func TestOptionExpect(t *testing.T) {
+	if Some(1).Must().(int) != 1 || Some(1).Expect("missing").(int) != 1 ||
+		NamedSome("a", 1).Expect("missing").(int) != 1 {
+		t.Fail()
+	}
+
+	expect := func(f func(), msg string) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("expect the panic '%s'", msg)
+			} else if s := r.(error).Error(); s != msg {
+				t.Errorf("expect the panic '%s', got '%s'", msg, s)
+			}
+		}()
+		f()
+	}
+
+	expect(func() { NONE.Must() }, "the value is None")
+	expect(func() { NONE.Expect("missing") }, "missing")
+	expect(func() { NamedNone("a").Must() }, "option 'a': the value is None")
+	expect(func() { NamedNone("a").Expect("missing") }, "option 'a': missing")
+}
+
+func TestOptionMatch(t *testing.T) {
+	var result int
+	some := func(v interface{}) { result = v.(int) }
+	none := func() { result = -1 }
+
+	if Some(1).Match(some, none); result != 1 {
+		t.Error(result)
+	}
+	if NONE.Match(some, none); result != -1 {
+		t.Error(result)
+	}
+	NONE.Match(some, nil)
+
+	double := func(v interface{}) interface{} { return v.(int) * 2 }
+	zero := func() interface{} { return 0 }
+	if v := Some(2).MatchV(double, zero).(int); v != 4 {
+		t.Error(v)
+	}
+	if v := NONE.MatchV(double, zero).(int); v != 0 {
+		t.Error(v)
+	}
+	if v := NONE.MatchV(double, nil); v != nil {
+		t.Error(v)
+	}
+	if v := Some(2).MatchV(nil, zero); v != nil {
+		t.Error(v)
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package option
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/xgfone/go-tools/types"
+)
+
+// Options is a collection of NamedOptions, which keeps the order
+// of the options added.
+type Options struct {
+	opts  []NamedOption
+	index map[string]int
+}
+
+// NewOptions returns a new Options with the named options.
+func NewOptions(opts ...NamedOption) *Options {
+	o := &Options{index: make(map[string]int, len(opts))}
+	o.Add(opts...)
+	return o
+}
+
+// Add adds the named options, which will replace the old one with the same name.
+func (o *Options) Add(opts ...NamedOption) {
+	for _, opt := range opts {
+		if i, ok := o.index[opt.Name()]; ok {
+			o.opts[i] = opt
+		} else {
+			o.index[opt.Name()] = len(o.opts)
+			o.opts = append(o.opts, opt)
+		}
+	}
+}
+
+// Set is equal to o.Add(NamedSome(name, value)).
+func (o *Options) Set(name string, value interface{}) {
+	o.Add(NamedSome(name, value))
+}
+
+// Del deletes the option named name.
+func (o *Options) Del(name string) {
+	if i, ok := o.index[name]; ok {
+		copy(o.opts[i:], o.opts[i+1:])
+		o.opts = o.opts[:len(o.opts)-1]
+		delete(o.index, name)
+		for ; i < len(o.opts); i++ {
+			o.index[o.opts[i].Name()] = i
+		}
+	}
+}
+
+// Has reports whether the option named name exists.
+func (o *Options) Has(name string) bool {
+	_, ok := o.index[name]
+	return ok
+}
+
+// Lookup returns the option named name and true. Or return false.
+func (o *Options) Lookup(name string) (NamedOption, bool) {
+	if i, ok := o.index[name]; ok {
+		return o.opts[i], true
+	}
+	return NamedOption{}, false
+}
+
+// Get returns the option named name. Return a None if it does not exist.
+func (o *Options) Get(name string) NamedOption {
+	if opt, ok := o.Lookup(name); ok {
+		return opt
+	}
+	return NamedNone(name)
+}
+
+// GetOr returns the value of the option named name if it exists
+// and is not None. Or return v.
+func (o *Options) GetOr(name string, v interface{}) interface{} {
+	return o.Get(name).SomeOr(v)
+}
+
+// Len returns the number of the options.
+func (o *Options) Len() int {
+	return len(o.opts)
+}
+
+// Names returns the names of all the options.
+func (o *Options) Names() []string {
+	names := make([]string, len(o.opts))
+	for i, opt := range o.opts {
+		names[i] = opt.Name()
+	}
+	return names
+}
+
+// Options returns all the options.
+func (o *Options) Options() []NamedOption {
+	return append([]NamedOption(nil), o.opts...)
+}
+
+// ScanStruct assigns the values of the options to the fields of the struct
+// which dst points to, and converts the value to the type of the field.
+//
+// The field is matched by the name in the tag "option", or the field name.
+// If not found, it will try to match the name case-insensitively.
+// The field is ignored if the tag is "-", or the option is missing or None.
+//
+// The supported field types are bool, string, the integers, the floats,
+// time.Duration, time.Time, the slice of them, and their pointers.
+// The string is split by the comma when the field is a slice.
+func (o *Options) ScanStruct(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return errors.New("the destination is not a pointer to struct")
+	}
+
+	v = v.Elem()
+	t := v.Type()
+	for i, n := 0, t.NumField(); i < n; i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" { // Unexported
+			continue
+		}
+
+		name := field.Tag.Get("option")
+		if name == "-" {
+			continue
+		} else if name == "" {
+			name = field.Name
+		}
+
+		opt, ok := o.lookupFold(name)
+		if !ok || opt.IsNone() {
+			continue
+		}
+
+		if err := assign(v.Field(i), opt.Value()); err != nil {
+			return fmt.Errorf("option '%s': %s", opt.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+func (o *Options) lookupFold(name string) (NamedOption, bool) {
+	if opt, ok := o.Lookup(name); ok {
+		return opt, true
+	}
+	for _, opt := range o.opts {
+		if strings.EqualFold(opt.Name(), name) {
+			return opt, true
+		}
+	}
+	return NamedOption{}, false
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// assign converts src to the type of dst and assigns it to dst.
+func assign(dst reflect.Value, src interface{}) (err error) {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dst.Type()) {
+		dst.Set(sv)
+		return nil
+	}
+
+	switch dst.Type() {
+	case durationType:
+		var d time.Duration
+		if d, err = types.ToDuration(src); err == nil {
+			dst.SetInt(int64(d))
+		}
+		return
+	case timeType:
+		var t time.Time
+		if t, err = types.ToTime(src); err == nil {
+			dst.Set(reflect.ValueOf(t))
+		}
+		return
+	}
+
+	switch dst.Kind() {
+	case reflect.Bool:
+		var b bool
+		if b, err = types.ToBool(src); err == nil {
+			dst.SetBool(b)
+		}
+	case reflect.String:
+		var s string
+		if s, err = types.ToString(src); err == nil {
+			dst.SetString(s)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = types.ToInt64(src); err == nil {
+			if dst.OverflowInt(i) {
+				return fmt.Errorf("the value '%d' overflows %s", i, dst.Type())
+			}
+			dst.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = types.ToUint64(src); err == nil {
+			if dst.OverflowUint(u) {
+				return fmt.Errorf("the value '%d' overflows %s", u, dst.Type())
+			}
+			dst.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = types.ToFloat64(src); err == nil {
+			if dst.OverflowFloat(f) {
+				return fmt.Errorf("the value '%v' overflows %s", f, dst.Type())
+			}
+			dst.SetFloat(f)
+		}
+	case reflect.Ptr:
+		v := reflect.New(dst.Type().Elem())
+		if err = assign(v.Elem(), src); err == nil {
+			dst.Set(v)
+		}
+	case reflect.Slice:
+		var vs []interface{}
+		switch s := src.(type) {
+		case string:
+			if s != "" {
+				for _, e := range strings.Split(s, ",") {
+					vs = append(vs, strings.TrimSpace(e))
+				}
+			}
+		default:
+			if vs, err = types.ToSlice(src); err != nil {
+				return fmt.Errorf("cannot convert %T to %s", src, dst.Type())
+			}
+		}
+
+		slice := reflect.MakeSlice(dst.Type(), len(vs), len(vs))
+		for i, e := range vs {
+			if err = assign(slice.Index(i), e); err != nil {
+				return
+			}
+		}
+		dst.Set(slice)
+	default:
+		if !sv.Type().ConvertibleTo(dst.Type()) {
+			return fmt.Errorf("cannot convert %T to %s", src, dst.Type())
+		}
+		dst.Set(sv.Convert(dst.Type()))
+	}
+
+	return
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package option
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOptions(t *testing.T) {
+	opts := NewOptions(NamedSome("a", 1), NamedSome("b", 2), NamedNone("c"))
+	opts.Set("a", 3)
+	if opts.Len() != 3 || opts.Get("a").Int() != 3 || opts.GetOr("c", 4).(int) != 4 ||
+		opts.GetOr("d", 5).(int) != 5 || opts.Get("d").Name() != "d" {
+		t.Fail()
+	}
+
+	opts.Del("a")
+	if names := opts.Names(); len(names) != 2 || names[0] != "b" || names[1] != "c" {
+		t.Error(names)
+	} else if opts.Has("a") || opts.Get("c").Name() != "c" {
+		t.Fail()
+	}
+}
+
+func TestOptionsScanStruct(t *testing.T) {
+	var config struct {
+		Addr    string
+		Port    uint16        `option:"port"`
+		Debug   bool          `option:"debug"`
+		Rate    float64       `option:"rate"`
+		Timeout time.Duration `option:"timeout"`
+		Tags    []string      `option:"tags"`
+		Ports   []int         `option:"ports"`
+		Max     *int          `option:"max"`
+		Ignore  string        `option:"-"`
+		Missing string
+	}
+	config.Missing = "default"
+
+	opts := NewOptions(
+		NamedSome("addr", "127.0.0.1"),
+		NamedSome("port", "8080"),
+		NamedSome("debug", "true"),
+		NamedSome("rate", "0.5"),
+		NamedSome("timeout", "3s"),
+		NamedSome("tags", "a, b,c"),
+		NamedSome("ports", []string{"1", "2"}),
+		NamedSome("max", "10"),
+		NamedSome("Ignore", "ignore"),
+	)
+
+	if err := opts.ScanStruct(&config); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Addr != "127.0.0.1" || config.Port != 8080 || !config.Debug ||
+		config.Rate != 0.5 || config.Timeout != 3*time.Second ||
+		len(config.Tags) != 3 || config.Tags[1] != "b" ||
+		len(config.Ports) != 2 || config.Ports[1] != 2 ||
+		config.Max == nil || *config.Max != 10 ||
+		config.Ignore != "" || config.Missing != "default" {
+		t.Errorf("%+v", config)
+	}
+
+	if err := NewOptions(NamedSome("port", "65536")).ScanStruct(&config); err == nil {
+		t.Error("expect an overflow error")
+	}
+	if err := opts.ScanStruct(config); err == nil {
+		t.Error("expect an error for non-pointer")
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package option
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ValidationErrors is a set of the violations returned by Registry.Validate.
+type ValidationErrors []error
+
+func (es ValidationErrors) Error() string {
+	ss := make([]string, len(es))
+	for i, e := range es {
+		ss[i] = e.Error()
+	}
+	return strings.Join(ss, "; ")
+}
+
+// Declaration is the declaration of an option in the registry.
+type Declaration struct {
+	Name        string
+	Default     interface{}
+	Description string
+
+	// Validator validates the value of the option, which may be nil.
+	Validator func(value interface{}) error
+}
+
+type registryEntry struct {
+	Declaration
+	value Option
+}
+
+// Registry is a registry of the declared options, which may be considered
+// as the centralized tunables of the program. It is thread-safe.
+type Registry struct {
+	lock    sync.RWMutex
+	names   []string
+	entries map[string]*registryEntry
+}
+
+// NewRegistry returns a new Registry.
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]*registryEntry)}
+}
+
+// Declare declares an option, which returns an error if it has been declared.
+func (r *Registry) Declare(name string, _default interface{},
+	validator func(interface{}) error, description string) error {
+	return r.DeclareOption(Declaration{
+		Name:        name,
+		Default:     _default,
+		Validator:   validator,
+		Description: description,
+	})
+}
+
+// DeclareOption is the same as Declare, but uses the Declaration.
+func (r *Registry) DeclareOption(d Declaration) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.entries[d.Name]; ok {
+		return fmt.Errorf("option '%s' has been declared", d.Name)
+	}
+
+	r.entries[d.Name] = &registryEntry{Declaration: d, value: None()}
+	r.names = append(r.names, d.Name)
+	return nil
+}
+
+// Declarations returns all the declarations in the declared order.
+func (r *Registry) Declarations() []Declaration {
+	r.lock.RLock()
+	ds := make([]Declaration, len(r.names))
+	for i, name := range r.names {
+		ds[i] = r.entries[name].Declaration
+	}
+	r.lock.RUnlock()
+	return ds
+}
+
+// Lookup returns the declaration of the option named name.
+func (r *Registry) Lookup(name string) (d Declaration, ok bool) {
+	r.lock.RLock()
+	e, ok := r.entries[name]
+	if ok {
+		d = e.Declaration
+	}
+	r.lock.RUnlock()
+	return
+}
+
+// Set sets the value of the option named name.
+//
+// If the default value is not nil, value will be converted to the type
+// of the default value. It returns an error if the option is not declared,
+// or it fails to convert or validate the value, and the value is not set.
+func (r *Registry) Set(name string, value interface{}) (err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	e, ok := r.entries[name]
+	if !ok {
+		return fmt.Errorf("option '%s' is not declared", name)
+	}
+
+	if value != nil && e.Default != nil {
+		v := reflect.New(reflect.TypeOf(e.Default)).Elem()
+		if err = assign(v, value); err != nil {
+			return fmt.Errorf("option '%s': %s", name, err)
+		}
+		value = v.Interface()
+	}
+
+	if e.Validator != nil {
+		if err = e.Validator(value); err != nil {
+			return fmt.Errorf("option '%s': %s", name, err)
+		}
+	}
+
+	e.value = Some(value)
+	return nil
+}
+
+// Load sets the values of the options and returns all the errors
+// as ValidationErrors.
+func (r *Registry) Load(opts *Options) error {
+	var errs ValidationErrors
+	for _, opt := range opts.Options() {
+		if opt.IsSome() {
+			if err := r.Set(opt.Name(), opt.Value()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Unset resets the option named name to the default value.
+func (r *Registry) Unset(name string) {
+	r.lock.Lock()
+	if e, ok := r.entries[name]; ok {
+		e.value = None()
+	}
+	r.lock.Unlock()
+}
+
+// Get returns the value of the option named name, which is the default value
+// if not set. If the option is not declared, it returns a None.
+func (r *Registry) Get(name string) NamedOption {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if e, ok := r.entries[name]; ok {
+		return NamedSome(name, e.get())
+	}
+	return NamedNone(name)
+}
+
+// IsSet reports whether the option named name has been set.
+func (r *Registry) IsSet(name string) bool {
+	r.lock.RLock()
+	e, ok := r.entries[name]
+	set := ok && e.value.IsSome()
+	r.lock.RUnlock()
+	return set
+}
+
+// Options returns the current values of all the declared options.
+func (r *Registry) Options() *Options {
+	r.lock.RLock()
+	opts := NewOptions()
+	for _, name := range r.names {
+		opts.Set(name, r.entries[name].get())
+	}
+	r.lock.RUnlock()
+	return opts
+}
+
+// Validate validates the current values of all the options including
+// the default values, and returns all the violations as ValidationErrors.
+func (r *Registry) Validate() error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var errs ValidationErrors
+	for _, name := range r.names {
+		if e := r.entries[name]; e.Validator != nil {
+			if err := e.Validator(e.get()); err != nil {
+				errs = append(errs, fmt.Errorf("option '%s': %s", name, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (e *registryEntry) get() interface{} {
+	return e.value.SomeOr(e.Default)
+}
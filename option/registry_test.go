@@ -0,0 +1,88 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package option
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	positive := func(v interface{}) error {
+		if v.(int) <= 0 {
+			return errors.New("must be positive")
+		}
+		return nil
+	}
+	required := func(v interface{}) error {
+		if v == nil || v.(string) == "" {
+			return errors.New("required")
+		}
+		return nil
+	}
+
+	r := NewRegistry()
+	r.Declare("workers", 4, positive, "the number of the workers")
+	r.Declare("timeout", time.Second, nil, "the timeout")
+	r.Declare("addr", nil, required, "the listen address")
+	if err := r.Declare("workers", 1, nil, ""); err == nil {
+		t.Error("expect a duplicate error")
+	}
+
+	if v := r.Get("workers").Int(); v != 4 {
+		t.Error(v)
+	} else if r.IsSet("workers") || r.Get("none").IsSome() {
+		t.Fail()
+	}
+
+	if err := r.Validate(); err == nil {
+		t.Error("expect a validation error")
+	} else if errs := err.(ValidationErrors); len(errs) != 1 || errs[0].Error() != "option 'addr': required" {
+		t.Error(err)
+	}
+
+	if err := r.Set("workers", "8"); err != nil {
+		t.Error(err)
+	} else if v := r.Get("workers").Int(); v != 8 {
+		t.Error(v)
+	}
+	if err := r.Set("workers", 0); err == nil || r.Get("workers").Int() != 8 {
+		t.Error("expect a validation error")
+	}
+	if err := r.Set("none", 1); err == nil {
+		t.Error("expect an undeclared error")
+	}
+
+	err := r.Load(NewOptions(NamedSome("timeout", "3s"), NamedSome("addr", ":80"), NamedSome("workers", "abc")))
+	if errs, ok := err.(ValidationErrors); !ok || len(errs) != 1 {
+		t.Error(err)
+	}
+	if v := r.Get("timeout").Duration(); v != 3*time.Second {
+		t.Error(v)
+	}
+	if err := r.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	r.Unset("workers")
+	if v := r.Options().Get("workers").Int(); v != 4 {
+		t.Error(v)
+	}
+
+	if ds := r.Declarations(); len(ds) != 3 || ds[2].Name != "addr" || ds[2].Description != "the listen address" {
+		t.Error(ds)
+	}
+}
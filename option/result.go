@@ -0,0 +1,176 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package option
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNone is the error of the failed Result created without an error,
+// such as Err(nil) or FromOption(None(), nil).
+var ErrNone = errors.New("no value")
+
+// Result represents either a value or the error why there is no value,
+// which is like Option but carries the reason of the absence.
+type Result struct {
+	value interface{}
+	err   error
+}
+
+// Ok returns a successful Result with the value v.
+func Ok(v interface{}) Result {
+	return Result{value: v}
+}
+
+// Err returns a failed Result with the error err.
+//
+// If err is nil, it is Err(ErrNone), so that it is never an Ok.
+func Err(err error) Result {
+	if err == nil {
+		err = ErrNone
+	}
+	return Result{err: err}
+}
+
+// FromPair converts the pair (v, err) returned by most functions to Result.
+//
+// If err is not nil, it is Err(err). Or it is Ok(v).
+func FromPair(v interface{}, err error) Result {
+	if err != nil {
+		return Err(err)
+	}
+	return Ok(v)
+}
+
+// FromOption converts the Option to Result, which is Err(err) if it's None.
+//
+// If err is nil, it is Err(ErrNone) for None.
+func FromOption(o Option, err error) Result {
+	if o == nil || o.IsNone() {
+		return Err(err)
+	}
+	return Ok(o.Value())
+}
+
+// IsOk reports whether the result is successful.
+func (r Result) IsOk() bool {
+	return r.err == nil
+}
+
+// IsErr reports whether the result is failed.
+func (r Result) IsErr() bool {
+	return r.err != nil
+}
+
+// Value returns the inner value. Return nil if it's an Err.
+func (r Result) Value() interface{} {
+	return r.value
+}
+
+// Err returns the inner error. Return nil if it's an Ok.
+func (r Result) Err() error {
+	return r.err
+}
+
+// Unpack converts the result to the pair (value, error).
+func (r Result) Unpack() (interface{}, error) {
+	return r.value, r.err
+}
+
+// Unwrap returns the inner value, but panic with the error if it's an Err.
+func (r Result) Unwrap() interface{} {
+	if r.err != nil {
+		panic(r.err)
+	}
+	return r.value
+}
+
+// UnwrapErr returns the inner error, but panic if it's an Ok.
+func (r Result) UnwrapErr() error {
+	if r.err == nil {
+		panic(errors.New("the result is Ok"))
+	}
+	return r.err
+}
+
+// UnwrapOr returns the inner value if it's an Ok. Or return v.
+func (r Result) UnwrapOr(v interface{}) interface{} {
+	if r.err != nil {
+		return v
+	}
+	return r.value
+}
+
+// UnwrapOrFunc returns the inner value if it's an Ok.
+// Or return the result of f with the inner error.
+func (r Result) UnwrapOrFunc(f func(error) interface{}) interface{} {
+	if r.err != nil {
+		return f(r.err)
+	}
+	return r.value
+}
+
+// Map returns Ok(f(value)) if it's an Ok. Or return itself.
+func (r Result) Map(f func(interface{}) interface{}) Result {
+	if r.err != nil {
+		return r
+	}
+	return Ok(f(r.value))
+}
+
+// MapErr returns Err(f(err)) if it's an Err. Or return itself.
+//
+// If f returns nil, it is Err(ErrNone).
+func (r Result) MapErr(f func(error) error) Result {
+	if r.err == nil {
+		return r
+	}
+	return Err(f(r.err))
+}
+
+// AndThen returns f(value) if it's an Ok. Or return itself.
+//
+// It's used to chain the operations which may fail.
+func (r Result) AndThen(f func(interface{}) Result) Result {
+	if r.err != nil {
+		return r
+	}
+	return f(r.value)
+}
+
+// OrElse returns itself if it's an Ok. Or return f(err).
+func (r Result) OrElse(f func(error) Result) Result {
+	if r.err == nil {
+		return r
+	}
+	return f(r.err)
+}
+
+// Option converts the result to Option, which is None if it's an Err.
+func (r Result) Option() Option {
+	if r.err != nil {
+		return None()
+	}
+	return Some(r.value)
+}
+
+// String implements the interface fmt.Stringer.
+func (r Result) String() string {
+	if r.err != nil {
+		return fmt.Sprintf("Err(%s)", r.err)
+	}
+	return fmt.Sprintf("Ok(%v)", r.value)
+}
@@ -0,0 +1,80 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package option
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestResult(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	if r := Ok(123); !r.IsOk() || r.IsErr() || r.Unwrap().(int) != 123 {
+		t.Fail()
+	}
+	if r := Err(errFailed); r.IsOk() || r.UnwrapErr() != errFailed || r.UnwrapOr(456).(int) != 456 {
+		t.Fail()
+	}
+
+	if v, err := FromPair(strconv.Atoi("123")).Unpack(); err != nil || v.(int) != 123 {
+		t.Error(v, err)
+	}
+	if r := FromPair(strconv.Atoi("abc")); r.IsOk() || r.Value() != nil {
+		t.Error(r)
+	}
+
+	double := func(v interface{}) interface{} { return v.(int) * 2 }
+	if v := Ok(1).Map(double).Unwrap().(int); v != 2 {
+		t.Error(v)
+	}
+	if r := Err(errFailed).Map(double); r.Err() != errFailed {
+		t.Error(r)
+	}
+
+	wrap := func(err error) error { return errors.New("wrap: " + err.Error()) }
+	if r := Err(errFailed).MapErr(wrap); r.Err().Error() != "wrap: failed" {
+		t.Error(r)
+	}
+
+	atoi := func(v interface{}) Result { return FromPair(strconv.Atoi(v.(string))) }
+	if r := Ok("12").AndThen(atoi); r.Unwrap().(int) != 12 {
+		t.Error(r)
+	}
+	if r := Ok("x").AndThen(atoi).Map(double); r.IsOk() {
+		t.Error(r)
+	}
+
+	if Err(errFailed).Option().IsSome() || Ok(1).Option().Int() != 1 {
+		t.Fail()
+	}
+	if FromOption(NONE, errFailed).Err() != errFailed || FromOption(Some(1), errFailed).IsErr() {
+		t.Fail()
+	}
+	if r := FromOption(NONE, nil); r.IsOk() || r.Err() != ErrNone {
+		t.Error(r)
+	}
+	if r := Err(nil); r.IsOk() || r.Err() != ErrNone {
+		t.Error(r)
+	}
+
+	if s := Ok(1).String(); s != "Ok(1)" {
+		t.Error(s)
+	}
+	if s := Err(errFailed).String(); s != "Err(failed)" {
+		t.Error(s)
+	}
+}
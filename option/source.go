@@ -0,0 +1,61 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package option
+
+import (
+	"flag"
+	"os"
+)
+
+// FromEnv returns a NamedOption named name with the value of the environment
+// variable name, which is None if the environment variable is unset.
+//
+// Notice: the value is a string, even if it is empty.
+func FromEnv(name string) NamedOption {
+	if v, ok := os.LookupEnv(name); ok {
+		return NamedSome(name, v)
+	}
+	return NamedNone(name)
+}
+
+// FromFlag returns a NamedOption named name with the value of the flag name
+// in fs, which is None if the flag does not exist or is not set explicitly.
+//
+// If fs is nil, it is flag.CommandLine by default. If the flag value has
+// implemented the interface flag.Getter, the value is the result of Get().
+// Or it's the result of String().
+//
+// You can express the layered configuration by OrElse, for example,
+//
+//	FromFlag(nil, "addr").OrElse(FromEnv("ADDR")).OrElse(Some(":80"))
+func FromFlag(fs *flag.FlagSet, name string) NamedOption {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+
+	var f *flag.Flag
+	fs.Visit(func(_f *flag.Flag) {
+		if _f.Name == name {
+			f = _f
+		}
+	})
+
+	if f == nil {
+		return NamedNone(name)
+	} else if g, ok := f.Value.(flag.Getter); ok {
+		return NamedSome(name, g.Get())
+	}
+	return NamedSome(name, f.Value.String())
+}
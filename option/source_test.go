@@ -0,0 +1,56 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package option
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+func TestFromEnv(t *testing.T) {
+	os.Setenv("OPTION_TEST_ENV", "abc")
+	defer os.Unsetenv("OPTION_TEST_ENV")
+
+	if o := FromEnv("OPTION_TEST_ENV"); o.IsNone() || o.Str() != "abc" || o.Name() != "OPTION_TEST_ENV" {
+		t.Error(o)
+	}
+	if o := FromEnv("OPTION_TEST_ENV_NONE"); o.IsSome() {
+		t.Error(o)
+	}
+}
+
+func TestFromFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Int("port", 80, "")
+	fs.String("addr", "", "")
+	if err := fs.Parse([]string{"-port", "8080"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if o := FromFlag(fs, "port"); o.IsNone() || o.Int() != 8080 {
+		t.Error(o)
+	}
+	if o := FromFlag(fs, "addr"); o.IsSome() {
+		t.Error(o)
+	}
+	if o := FromFlag(fs, "none"); o.IsSome() {
+		t.Error(o)
+	}
+
+	if v := FromFlag(fs, "addr").OrElse(Some(":80")).Str(); v != ":80" {
+		t.Error(v)
+	}
+}
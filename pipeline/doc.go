@@ -0,0 +1,24 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pipeline supplies a builder to chain the stages to process
+// the items concurrently, which are connected by the bounded channels.
+//
+// Example
+//
+//	p := pipeline.New().
+//		Stage("parse", parse, pipeline.WithConcurrency(4)).
+//		Stage("store", store, pipeline.WithBuffer(100))
+//	out, errs := p.Run(ctx, channels.FromSlice(lines))
+package pipeline
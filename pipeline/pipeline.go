@@ -0,0 +1,237 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/xgfone/go-tools/errors2"
+	"github.com/xgfone/go-tools/sync2"
+)
+
+// ErrSkip is returned by the stage function to drop the item
+// without reporting the error, such as filtering.
+var ErrSkip = errors.New("pipeline: skip the item")
+
+// StageFunc is the function of the stage, which processes the input item
+// and returns the output item passed to the next stage.
+type StageFunc func(ctx context.Context, in interface{}) (out interface{}, err error)
+
+// StageError is the error returned by the stage function.
+type StageError struct {
+	Stage string
+	Item  interface{}
+	Err   error
+}
+
+func (e StageError) Error() string {
+	return fmt.Sprintf("pipeline stage '%s': %s", e.Stage, e.Err)
+}
+
+// StageOption is used to configure the stage.
+type StageOption func(*stage)
+
+// WithConcurrency returns a stage option to set the number of the workers
+// running the stage concurrently. The default is 1.
+//
+// Notice: the order of the items is not kept if concurrency is more than 1.
+func WithConcurrency(concurrency int) StageOption {
+	return func(s *stage) { s.concurrency = concurrency }
+}
+
+// WithBuffer returns a stage option to set the buffer size of the output
+// channel of the stage. The default is 0.
+func WithBuffer(size int) StageOption {
+	return func(s *stage) { s.buffer = size }
+}
+
+type stage struct {
+	name        string
+	handle      StageFunc
+	concurrency int
+	buffer      int
+}
+
+// Pipeline is a chain of the stages.
+type Pipeline struct {
+	// StopOnError stops the whole pipeline when a stage returns an error.
+	//
+	// The default is false, that's, the item is dropped and the error
+	// is reported, then the pipeline continues to process the next.
+	StopOnError bool
+
+	stages []stage
+}
+
+// New returns a new Pipeline.
+func New() *Pipeline {
+	return &Pipeline{}
+}
+
+// Stage appends the stage named name with the function handle.
+func (p *Pipeline) Stage(name string, handle StageFunc, opts ...StageOption) *Pipeline {
+	s := stage{name: name, handle: handle, concurrency: 1}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	if s.concurrency < 1 {
+		s.concurrency = 1
+	}
+	if s.buffer < 0 {
+		s.buffer = 0
+	}
+
+	p.stages = append(p.stages, s)
+	return p
+}
+
+// Run starts the pipeline to process the items from source in the new
+// goroutines, and returns the channel of the output items of the last stage
+// and the channel of the errors as StageError, which are closed after source
+// is closed and all the items are processed, or ctx is done.
+//
+// The caller must consume both channels until they are closed, or cancel ctx.
+func (p *Pipeline) Run(ctx context.Context, source <-chan interface{}) (<-chan interface{}, <-chan error) {
+	ctx, cancel := context.WithCancel(ctx)
+	errs := make(chan error)
+
+	stages := p.stages
+	if len(stages) == 0 {
+		stages = []stage{{handle: identity, concurrency: 1}}
+	}
+
+	var wg sync.WaitGroup
+	in := source
+	for _, s := range stages {
+		out := make(chan interface{}, s.buffer)
+
+		var swg sync.WaitGroup
+		swg.Add(s.concurrency)
+		wg.Add(s.concurrency)
+		for i := 0; i < s.concurrency; i++ {
+			go func(s stage, in <-chan interface{}) {
+				defer wg.Done()
+				defer swg.Done()
+				p.work(ctx, cancel, s, in, out, errs)
+			}(s, in)
+		}
+
+		go func(out chan interface{}) {
+			swg.Wait()
+			close(out)
+		}(out)
+		in = out
+	}
+
+	go func() {
+		wg.Wait()
+		cancel()
+		close(errs)
+	}()
+
+	return in, errs
+}
+
+func (p *Pipeline) work(ctx context.Context, cancel func(), s stage,
+	in <-chan interface{}, out chan<- interface{}, errs chan<- error) {
+	for {
+		var item interface{}
+		select {
+		case <-ctx.Done():
+			return
+		case v, ok := <-in:
+			if !ok {
+				return
+			}
+			item = v
+		}
+
+		v, err := call(ctx, s.handle, item)
+		switch {
+		case err == ErrSkip:
+			continue
+		case err != nil:
+			select {
+			case errs <- StageError{Stage: s.name, Item: item, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if p.StopOnError {
+				cancel()
+				return
+			}
+			continue
+		}
+
+		select {
+		case out <- v:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func identity(ctx context.Context, in interface{}) (interface{}, error) {
+	return in, nil
+}
+
+func call(ctx context.Context, handle StageFunc, in interface{}) (out interface{}, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			buf := make([]byte, 4096)
+			err = sync2.PanicError{Value: v, Stack: buf[:runtime.Stack(buf, false)]}
+		}
+	}()
+	return handle(ctx, in)
+}
+
+// Collect runs the pipeline, and returns all the output items and
+// the errors as *errors2.Multi, or nil if no errors.
+//
+// Notice: if ctx is done, ctx.Err() is appended to the errors.
+func (p *Pipeline) Collect(ctx context.Context, source <-chan interface{}) ([]interface{}, error) {
+	out, errc := p.Run(ctx, source)
+
+	var items []interface{}
+	var errs []error
+	for out != nil || errc != nil {
+		select {
+		case v, ok := <-out:
+			if !ok {
+				out = nil
+				continue
+			}
+			items = append(items, v)
+		case err, ok := <-errc:
+			if !ok {
+				errc = nil
+				continue
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return items, &errors2.Multi{Errors: errs}
+	}
+	return items, nil
+}
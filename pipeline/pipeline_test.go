@@ -0,0 +1,137 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-tools/channels"
+	"github.com/xgfone/go-tools/errors2"
+	"github.com/xgfone/go-tools/sync2"
+)
+
+func parseInt(ctx context.Context, in interface{}) (interface{}, error) {
+	return strconv.Atoi(in.(string))
+}
+
+func TestPipeline(t *testing.T) {
+	p := New().
+		Stage("parse", parseInt, WithConcurrency(3), WithBuffer(2)).
+		Stage("filter", func(ctx context.Context, in interface{}) (interface{}, error) {
+			if in.(int)%2 == 0 {
+				return nil, ErrSkip
+			}
+			return in, nil
+		}).
+		Stage("square", func(ctx context.Context, in interface{}) (interface{}, error) {
+			if in.(int) == 7 {
+				panic("seven")
+			}
+			return in.(int) * in.(int), nil
+		}, WithConcurrency(2))
+
+	source := channels.FromSlice([]interface{}{"1", "2", "3", "x", "5", "6", "7"})
+	items, err := p.Collect(context.Background(), source)
+
+	ints := make([]int, len(items))
+	for i, v := range items {
+		ints[i] = v.(int)
+	}
+	sort.Ints(ints)
+	if len(ints) != 3 || ints[0] != 1 || ints[1] != 9 || ints[2] != 25 {
+		t.Errorf("unexpected items: %v", ints)
+	}
+
+	m, ok := err.(*errors2.Multi)
+	errs := m.Unwrap()
+	if !ok || len(errs) != 2 {
+		t.Fatalf("unexpected errors: %v", err)
+	}
+
+	stages := make([]string, 0, 2)
+	for _, e := range errs {
+		se := e.(StageError)
+		stages = append(stages, se.Stage)
+		if se.Stage == "square" {
+			if pe, ok := se.Err.(sync2.PanicError); !ok || pe.Value != "seven" {
+				t.Errorf("unexpected panic: %v", se.Err)
+			}
+		} else if se.Item != "x" {
+			t.Errorf("unexpected item: %v", se.Item)
+		}
+	}
+	sort.Strings(stages)
+	if stages[0] != "parse" || stages[1] != "square" {
+		t.Errorf("unexpected stages: %v", stages)
+	}
+}
+
+func TestPipelineStopOnError(t *testing.T) {
+	p := New().Stage("fail", func(ctx context.Context, in interface{}) (interface{}, error) {
+		if in.(int) == 2 {
+			return nil, errors.New("error")
+		}
+		return in, nil
+	})
+	p.StopOnError = true
+
+	source := make(chan interface{})
+	go func() {
+		defer close(source)
+		for i := 1; i < 100; i++ {
+			select {
+			case source <- i:
+			case <-time.After(time.Millisecond * 100):
+				return
+			}
+		}
+	}()
+
+	items, err := p.Collect(context.Background(), source)
+	if len(items) != 1 || items[0] != 1 {
+		t.Errorf("unexpected items: %v", items)
+	}
+	if m, ok := err.(*errors2.Multi); !ok || m.Len() != 1 || m.Errors[0].Error() != "pipeline stage 'fail': error" {
+		t.Errorf("unexpected errors: %v", err)
+	}
+}
+
+func TestPipelineCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := New().Stage("slow", func(ctx context.Context, in interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return in, ctx.Err()
+	})
+
+	source := make(chan interface{}, 1)
+	source <- 1
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		cancel()
+	}()
+
+	if _, err := p.Collect(ctx, source); err == nil {
+		t.Error("expect an error")
+	}
+
+	if items, err := New().Collect(context.Background(), channels.FromSlice([]interface{}{1, 2})); err != nil || len(items) != 2 {
+		t.Errorf("items=%v, err=%v", items, err)
+	}
+}
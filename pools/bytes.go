@@ -0,0 +1,145 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pools
+
+import (
+	"math/bits"
+	"sync"
+
+	"github.com/xgfone/go-tools/sync2"
+)
+
+// DefaultSizedBytesPool is the default global size-classed bytes pool,
+// whose size classes are from 64B to 1MB.
+var DefaultSizedBytesPool = NewSizedBytesPool(64, 1024*1024)
+
+// GetBytes is equal to DefaultSizedBytesPool.Get(size).
+func GetBytes(size int) []byte { return DefaultSizedBytesPool.Get(size) }
+
+// PutBytes is equal to DefaultSizedBytesPool.Put(b).
+func PutBytes(b []byte) { DefaultSizedBytesPool.Put(b) }
+
+// SizedBytesPoolStats is the statistics of SizedBytesPool.
+type SizedBytesPoolStats struct {
+	Gets   int64 // The number of the calls of Get.
+	Hits   int64 // The number of the []byte reused from the pool.
+	Misses int64 // The number of the []byte allocated newly.
+	Puts   int64 // The number of the []byte put back to the pool.
+	Drops  int64 // The number of the []byte not belonging to any size class.
+}
+
+// SizedBytesPool is a pool of []byte with the size classes of the power of 2.
+type SizedBytesPool struct {
+	// The 64-bit atomic counters must stay first so that they are 8-byte
+	// aligned on the 32-bit platforms.
+	gets   sync2.AtomicInt64
+	hits   sync2.AtomicInt64
+	misses sync2.AtomicInt64
+	puts   sync2.AtomicInt64
+	drops  sync2.AtomicInt64
+
+	minBits uint
+	maxBits uint
+	pools   []sync.Pool
+}
+
+// NewSizedBytesPool returns a new SizedBytesPool, whose size classes are
+// the power of 2 between minSize and maxSize, which will be rounded up
+// to the power of 2.
+func NewSizedBytesPool(minSize, maxSize int) *SizedBytesPool {
+	if minSize < 1 {
+		minSize = 1
+	}
+	if maxSize < minSize {
+		maxSize = minSize
+	}
+
+	minBits, maxBits := sizeBits(minSize), sizeBits(maxSize)
+	return &SizedBytesPool{
+		minBits: minBits,
+		maxBits: maxBits,
+		pools:   make([]sync.Pool, maxBits-minBits+1),
+	}
+}
+
+// sizeBits returns the bits n so that 1<<n is the minimum power of 2
+// not less than size.
+func sizeBits(size int) uint {
+	if size <= 1 {
+		return 0
+	}
+	return uint(bits.Len(uint(size - 1)))
+}
+
+// Get returns a []byte whose length is size, and the capacity is the size
+// class. If size is larger than the maximum size class, it is allocated
+// and not pooled.
+func (p *SizedBytesPool) Get(size int) []byte {
+	p.gets.Add(1)
+
+	n := sizeBits(size)
+	if n < p.minBits {
+		n = p.minBits
+	} else if n > p.maxBits {
+		p.misses.Add(1)
+		return make([]byte, size)
+	}
+
+	if x := p.pools[n-p.minBits].Get(); x != nil {
+		p.hits.Add(1)
+		return x.([]byte)[:size]
+	}
+
+	p.misses.Add(1)
+	return make([]byte, size, 1<<n)
+}
+
+// Put places the []byte back to the pool, which will be dropped
+// if its capacity is not a size class.
+func (p *SizedBytesPool) Put(b []byte) {
+	c := cap(b)
+	if c == 0 || c&(c-1) != 0 {
+		p.drops.Add(1)
+		return
+	}
+
+	n := sizeBits(c)
+	if n < p.minBits || n > p.maxBits {
+		p.drops.Add(1)
+		return
+	}
+
+	p.puts.Add(1)
+	p.pools[n-p.minBits].Put(b[:c])
+}
+
+// Stats returns the statistics of the pool.
+func (p *SizedBytesPool) Stats() SizedBytesPoolStats {
+	return SizedBytesPoolStats{
+		Gets:   p.gets.Get(),
+		Hits:   p.hits.Get(),
+		Misses: p.misses.Get(),
+		Puts:   p.puts.Get(),
+		Drops:  p.drops.Get(),
+	}
+}
+
+// HitRate returns the rate of the []byte reused from the pool by Get.
+func (s SizedBytesPoolStats) HitRate() float64 {
+	if s.Gets == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Gets)
+}
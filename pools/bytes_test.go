@@ -0,0 +1,53 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pools
+
+import "testing"
+
+func TestSizedBytesPool(t *testing.T) {
+	p := NewSizedBytesPool(64, 1024)
+
+	for _, c := range []struct{ size, cap int }{
+		{0, 64}, {1, 64}, {64, 64}, {65, 128}, {1000, 1024}, {1024, 1024}, {1025, 1025},
+	} {
+		if b := p.Get(c.size); len(b) != c.size || cap(b) != c.cap {
+			t.Errorf("%d: expect len=%d and cap=%d, got len=%d and cap=%d",
+				c.size, c.size, c.cap, len(b), cap(b))
+		}
+	}
+
+	p.Put(make([]byte, 10, 100))
+	p.Put(make([]byte, 2048))
+	p.Put(make([]byte, 10, 128))
+	if b := p.Get(100); len(b) != 100 || cap(b) != 128 {
+		t.Errorf("len=%d, cap=%d", len(b), cap(b))
+	}
+
+	s := p.Stats()
+	if s.Gets != 8 || s.Puts != 1 || s.Drops != 2 || s.Hits+s.Misses != s.Gets {
+		t.Errorf("%+v", s)
+	}
+	if r := s.HitRate(); r < 0 || r > 1 {
+		t.Error(r)
+	}
+}
+
+func BenchmarkSizedBytesPool(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			PutBytes(GetBytes(4096))
+		}
+	})
+}
@@ -13,5 +13,5 @@
 // limitations under the License.
 
 // Package pools supplies some simple convenient pools, such as `BufferPool`,
-// `ResourcePool`, etc.
+// `SizedBytesPool`, `ResourcePool`, `WorkerPool`, etc.
 package pools
@@ -0,0 +1,53 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pools
+
+import "sync"
+
+// ObjectPool is the wrapper of sync.Pool, which resets the object
+// when putting it back so that the object got from the pool is always clean.
+type ObjectPool struct {
+	pool  sync.Pool
+	reset func(interface{})
+}
+
+// NewObjectPool returns a new ObjectPool.
+//
+// newObject is used to create a new object, and reset is used to reset the object
+// before putting it back, which may be nil.
+func NewObjectPool(newObject func() interface{}, reset func(interface{})) *ObjectPool {
+	if newObject == nil {
+		panic("the new function of the object pool must not be nil")
+	}
+
+	p := &ObjectPool{reset: reset}
+	p.pool.New = newObject
+	return p
+}
+
+// Get returns an object from the pool, which is created by newObject if no object.
+func (p *ObjectPool) Get() interface{} {
+	return p.pool.Get()
+}
+
+// Put resets the object and places it back to the pool.
+func (p *ObjectPool) Put(x interface{}) {
+	if x != nil {
+		if p.reset != nil {
+			p.reset(x)
+		}
+		p.pool.Put(x)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestObjectPool(t *testing.T) {
+	p := NewObjectPool(func() interface{} { return new(strings.Builder) },
+		func(x interface{}) { x.(*strings.Builder).Reset() })
+
+	b := p.Get().(*strings.Builder)
+	b.WriteString("abc")
+	p.Put(b)
+	p.Put(nil)
+
+	if b = p.Get().(*strings.Builder); b.Len() != 0 {
+		t.Errorf("the object is not reset: %s", b.String())
+	}
+}
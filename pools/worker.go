@@ -0,0 +1,305 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-tools/sync2"
+)
+
+var (
+	// ErrWorkerPoolStopped is returned when submitting a task
+	// to the stopped worker pool.
+	ErrWorkerPoolStopped = errors.New("worker pool is stopped")
+
+	// ErrQueueFull is returned when the task queue of the worker pool is full.
+	ErrQueueFull = errors.New("worker pool queue is full")
+)
+
+type workerTask struct {
+	ctx  context.Context
+	task func(context.Context)
+}
+
+// WorkerPoolStats is the statistics of the worker pool.
+type WorkerPoolStats struct {
+	Workers   int64 // The number of the current workers.
+	Running   int64 // The number of the running tasks.
+	Queued    int64 // The number of the tasks in the queue.
+	Completed int64 // The total number of the completed tasks without panic.
+	Panics    int64 // The total number of the panicked tasks.
+	Canceled  int64 // The total number of the tasks skipped as their contexts are done.
+}
+
+// WorkerPool is a pool of the goroutines to run the tasks with a bounded queue.
+//
+// It has MinWorkers workers at least, and starts the new worker when all the
+// workers are busy until MaxWorkers. The extra workers exit after being idle
+// for IdleTimeout, but one worker is always kept once started so that
+// the queued tasks are always handled. So it's fixed if MinWorkers is equal
+// to MaxWorkers.
+type WorkerPool struct {
+	// IdleTimeout is the timeout that the extra idle worker exits.
+	// The default is one minute.
+	IdleTimeout time.Duration
+
+	// PanicHandler is called when a task panics. If nil, ignore it.
+	PanicHandler func(panic interface{}, stack []byte)
+
+	min   int64
+	max   int64
+	keep  int64
+	queue chan workerTask
+
+	lock    sync.RWMutex
+	stopped bool
+	wg      sync.WaitGroup
+
+	pendLock sync.Mutex
+	pendCond *sync.Cond
+	pending  int
+
+	idle      sync2.AtomicInt64
+	workers   sync2.AtomicInt64
+	running   sync2.AtomicInt64
+	completed sync2.AtomicInt64
+	panics    sync2.AtomicInt64
+	canceled  sync2.AtomicInt64
+}
+
+// NewWorkerPool returns a new WorkerPool, which starts minWorkers workers.
+//
+// If queueSize is equal to or less than 0, the queue is unbuffered.
+func NewWorkerPool(minWorkers, maxWorkers, queueSize int) *WorkerPool {
+	if minWorkers < 0 || maxWorkers <= 0 || minWorkers > maxWorkers {
+		panic(fmt.Errorf("invalid workers: min=%d, max=%d", minWorkers, maxWorkers))
+	} else if queueSize < 0 {
+		queueSize = 0
+	}
+
+	p := &WorkerPool{
+		IdleTimeout: time.Minute,
+
+		min:   int64(minWorkers),
+		max:   int64(maxWorkers),
+		keep:  int64(minWorkers),
+		queue: make(chan workerTask, queueSize),
+	}
+	p.pendCond = sync.NewCond(&p.pendLock)
+	if p.keep < 1 {
+		p.keep = 1
+	}
+
+	for i := 0; i < minWorkers; i++ {
+		p.workers.Add(1)
+		p.startWorker()
+	}
+	return p
+}
+
+// Stats returns the statistics of the worker pool.
+func (p *WorkerPool) Stats() WorkerPoolStats {
+	return WorkerPoolStats{
+		Workers:   p.workers.Get(),
+		Running:   p.running.Get(),
+		Queued:    int64(len(p.queue)),
+		Completed: p.completed.Get(),
+		Panics:    p.panics.Get(),
+		Canceled:  p.canceled.Get(),
+	}
+}
+
+// QueueLen returns the number of the tasks in the queue.
+func (p *WorkerPool) QueueLen() int {
+	return len(p.queue)
+}
+
+// Submit submits the task and waits until it's queued.
+func (p *WorkerPool) Submit(task func()) error {
+	return p.SubmitContext(context.Background(), func(context.Context) { task() })
+}
+
+// TrySubmit submits the task without blocking, which returns ErrQueueFull
+// if the queue is full.
+func (p *WorkerPool) TrySubmit(task func()) error {
+	return p.submit(nil, func(context.Context) { task() })
+}
+
+// SubmitContext submits the task and waits until it's queued or ctx is done.
+//
+// ctx will be passed to the task, and the task will be skipped
+// if ctx has been done before running it.
+func (p *WorkerPool) SubmitContext(ctx context.Context, task func(context.Context)) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return p.submit(ctx, task)
+}
+
+func (p *WorkerPool) submit(ctx context.Context, task func(context.Context)) error {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	if p.stopped {
+		return ErrWorkerPoolStopped
+	}
+
+	p.addPending(1)
+	if p.idle.Get() <= 0 {
+		p.tryStartWorker()
+	}
+
+	t := workerTask{ctx: ctx, task: task}
+	if ctx == nil {
+		t.ctx = context.Background()
+		select {
+		case p.queue <- t:
+			return nil
+		default:
+			p.addPending(-1)
+			return ErrQueueFull
+		}
+	}
+
+	select {
+	case p.queue <- t:
+		return nil
+	case <-ctx.Done():
+		p.addPending(-1)
+		return ctx.Err()
+	}
+}
+
+func (p *WorkerPool) addPending(delta int) {
+	p.pendLock.Lock()
+	p.pending += delta
+	if p.pending == 0 {
+		p.pendCond.Broadcast()
+	}
+	p.pendLock.Unlock()
+}
+
+// Drain waits until all the submitted tasks have finished,
+// but the pool is still available.
+func (p *WorkerPool) Drain() {
+	p.pendLock.Lock()
+	for p.pending > 0 {
+		p.pendCond.Wait()
+	}
+	p.pendLock.Unlock()
+}
+
+// Stop stops the pool gracefully, which does not accept the new tasks
+// any more and waits until all the queued tasks have finished.
+func (p *WorkerPool) Stop() {
+	p.lock.Lock()
+	if p.stopped {
+		p.lock.Unlock()
+		return
+	}
+	p.stopped = true
+	close(p.queue)
+	p.lock.Unlock()
+	p.wg.Wait()
+}
+
+func (p *WorkerPool) tryStartWorker() {
+	for {
+		n := p.workers.Get()
+		if n >= p.max {
+			return
+		} else if p.workers.CompareAndSwap(n, n+1) {
+			p.startWorker()
+			return
+		}
+	}
+}
+
+func (p *WorkerPool) startWorker() {
+	p.wg.Add(1)
+	go p.work()
+}
+
+func (p *WorkerPool) work() {
+	defer p.wg.Done()
+
+	var timer *time.Timer
+	var timeout <-chan time.Time
+	for {
+		if p.workers.Get() > p.keep {
+			if timer == nil {
+				timer = time.NewTimer(p.IdleTimeout)
+				defer timer.Stop()
+			} else {
+				timer.Reset(p.IdleTimeout)
+			}
+			timeout = timer.C
+		} else {
+			timeout = nil
+		}
+
+		p.idle.Add(1)
+		select {
+		case t, ok := <-p.queue:
+			p.idle.Add(-1)
+			if timer != nil && !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+
+			if !ok {
+				p.workers.Add(-1)
+				return
+			}
+			p.run(t)
+		case <-timeout:
+			p.idle.Add(-1)
+			if n := p.workers.Get(); n > p.keep && p.workers.CompareAndSwap(n, n-1) {
+				return
+			}
+		}
+	}
+}
+
+func (p *WorkerPool) run(t workerTask) {
+	defer p.addPending(-1)
+	if t.ctx.Err() != nil {
+		p.canceled.Add(1)
+		return
+	}
+
+	p.running.Add(1)
+	defer func() {
+		p.running.Add(-1)
+		if r := recover(); r != nil {
+			p.panics.Add(1)
+			if p.PanicHandler != nil {
+				buf := make([]byte, 4096)
+				p.PanicHandler(r, buf[:runtime.Stack(buf, false)])
+			}
+		} else {
+			p.completed.Add(1)
+		}
+	}()
+
+	t.task(t.ctx)
+}
@@ -0,0 +1,134 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pools
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool(t *testing.T) {
+	p := NewWorkerPool(1, 4, 10)
+
+	var count int64
+	for i := 0; i < 20; i++ {
+		if err := p.Submit(func() {
+			time.Sleep(time.Millisecond * 10)
+			atomic.AddInt64(&count, 1)
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := p.Stats().Workers; n < 2 || n > 4 {
+		t.Errorf("expect 2~4 workers, got %d", n)
+	}
+
+	p.Drain()
+	if n := atomic.LoadInt64(&count); n != 20 {
+		t.Errorf("expect 20 tasks, got %d", n)
+	}
+
+	var panicked int64
+	p.PanicHandler = func(interface{}, []byte) { atomic.AddInt64(&panicked, 1) }
+	p.Submit(func() { panic("test") })
+	p.Drain()
+	if s := p.Stats(); s.Panics != 1 || s.Completed != 20 || atomic.LoadInt64(&panicked) != 1 {
+		t.Errorf("%+v", s)
+	}
+
+	p.Stop()
+	if err := p.Submit(func() {}); err != ErrWorkerPoolStopped {
+		t.Error(err)
+	}
+	if n := p.Stats().Workers; n != 0 {
+		t.Errorf("expect no workers, got %d", n)
+	}
+}
+
+func TestWorkerPoolIdle(t *testing.T) {
+	p := NewWorkerPool(0, 3, 0)
+	p.IdleTimeout = time.Millisecond * 10
+	defer p.Stop()
+
+	block := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		p.Submit(func() { <-block })
+	}
+	if n := p.Stats().Workers; n != 3 {
+		t.Errorf("expect 3 workers, got %d", n)
+	}
+	close(block)
+
+	time.Sleep(time.Millisecond * 100)
+	if n := p.Stats().Workers; n != 1 {
+		t.Errorf("expect the idle workers to exit, got %d", n)
+	}
+
+	if err := p.Submit(func() {}); err != nil {
+		t.Error(err)
+	}
+	p.Drain()
+}
+
+func TestWorkerPoolQueueFull(t *testing.T) {
+	p := NewWorkerPool(1, 1, 1)
+	defer p.Stop()
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	p.Submit(func() { close(started); <-block })
+	<-started
+
+	if err := p.TrySubmit(func() {}); err != nil {
+		t.Error(err)
+	}
+	if err := p.TrySubmit(func() {}); err != ErrQueueFull {
+		t.Errorf("expect ErrQueueFull, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := p.SubmitContext(ctx, func(context.Context) {}); err != context.DeadlineExceeded {
+		t.Errorf("expect the deadline error, got %v", err)
+	}
+
+	close(block)
+	p.Drain()
+}
+
+func TestWorkerPoolCanceled(t *testing.T) {
+	p := NewWorkerPool(1, 1, 1)
+	defer p.Stop()
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	p.Submit(func() { close(started); <-block })
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := p.SubmitContext(ctx, func(context.Context) {}); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	close(block)
+	p.Drain()
+	if s := p.Stats(); s.Completed != 1 || s.Canceled != 1 || s.Panics != 0 {
+		t.Errorf("%+v", s)
+	}
+}
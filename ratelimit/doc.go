@@ -0,0 +1,20 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit supplies some rate limiters, such as the token-bucket
+// Limiter, the per-key KeyedLimiter, and the leaky-bucket Pacer.
+//
+// The limiters implement the interface io2.RateLimiter, so they can be used
+// to throttle the bandwidth of the readers, the writers and the connections.
+package ratelimit
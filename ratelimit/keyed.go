@@ -0,0 +1,154 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// KeyedLimiter is a set of the token-bucket limiters per key, such as
+// the client IP, which creates the limiter for the key on demand.
+//
+// The limiter of the key is removed after its bucket has been full
+// for the idle timeout, which is the same as the new one, unless it is
+// still acquired by Acquire.
+type KeyedLimiter struct {
+	rate  float64
+	burst int
+	idle  time.Duration
+
+	lock     sync.Mutex
+	limiters map[string]*Limiter
+	refs     map[*Limiter]int
+	lastScan time.Time
+}
+
+// NewKeyedLimiter returns a new KeyedLimiter, which creates the limiter
+// for each key by NewLimiter(rate, burst).
+//
+// If idle is 0, it is one minute by default.
+func NewKeyedLimiter(rate float64, burst int, idle time.Duration) *KeyedLimiter {
+	if idle <= 0 {
+		idle = time.Minute
+	}
+	return &KeyedLimiter{
+		rate:     rate,
+		burst:    burst,
+		idle:     idle,
+		limiters: make(map[string]*Limiter),
+		refs:     make(map[*Limiter]int),
+		lastScan: time.Now(),
+	}
+}
+
+// Get returns the limiter of the key, which is created if not exist.
+//
+// It also removes the idle limiters if the idle timeout has elapsed
+// since last scanning.
+func (k *KeyedLimiter) Get(key string) *Limiter {
+	now := time.Now()
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	return k.get(now, key)
+}
+
+// Acquire is the same as Get, but the returned limiter won't be removed
+// as idle until release is called, which is used by the long-lived users,
+// such as the connections.
+//
+// release may be called more than once, but only the first is valid.
+func (k *KeyedLimiter) Acquire(key string) (l *Limiter, release func()) {
+	now := time.Now()
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	l = k.get(now, key)
+	k.refs[l]++
+
+	var once sync.Once
+	return l, func() { once.Do(func() { k.release(l) }) }
+}
+
+func (k *KeyedLimiter) release(l *Limiter) {
+	k.lock.Lock()
+	if k.refs[l]--; k.refs[l] <= 0 {
+		delete(k.refs, l)
+	}
+	k.lock.Unlock()
+}
+
+func (k *KeyedLimiter) get(now time.Time, key string) *Limiter {
+	if now.Sub(k.lastScan) >= k.idle {
+		k.cleanup(now)
+	}
+
+	l, ok := k.limiters[key]
+	if !ok {
+		l = NewLimiter(k.rate, k.burst)
+		k.limiters[key] = l
+	}
+	return l
+}
+
+// Allow is equal to k.Get(key).Allow().
+func (k *KeyedLimiter) Allow(key string) bool {
+	return k.Get(key).Allow()
+}
+
+// AllowN is equal to k.Get(key).AllowN(n).
+func (k *KeyedLimiter) AllowN(key string, n int) bool {
+	return k.Get(key).AllowN(n)
+}
+
+// Wait is equal to k.Get(key).Wait(ctx).
+func (k *KeyedLimiter) Wait(ctx context.Context, key string) error {
+	return k.Get(key).Wait(ctx)
+}
+
+// Remove removes the limiter of the key.
+func (k *KeyedLimiter) Remove(key string) {
+	k.lock.Lock()
+	delete(k.limiters, key)
+	k.lock.Unlock()
+}
+
+// Len returns the number of the limiters.
+func (k *KeyedLimiter) Len() int {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	return len(k.limiters)
+}
+
+// Cleanup removes the idle limiters, and returns the number of them.
+func (k *KeyedLimiter) Cleanup() int {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	return k.cleanup(time.Now())
+}
+
+func (k *KeyedLimiter) cleanup(now time.Time) (n int) {
+	k.lastScan = now
+	for key, l := range k.limiters {
+		if k.refs[l] > 0 {
+			continue
+		} else if since, full := l.idleSince(now); full && now.Sub(since) >= k.idle {
+			delete(k.limiters, key)
+			n++
+		}
+	}
+	return
+}
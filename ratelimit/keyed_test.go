@@ -0,0 +1,71 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyedLimiter(t *testing.T) {
+	k := NewKeyedLimiter(100, 1, time.Millisecond*50)
+	if !k.Allow("a") || k.Allow("a") {
+		t.Error("expect to allow only one event of the key 'a'")
+	}
+	if !k.Allow("b") {
+		t.Error("expect to allow the event of the key 'b'")
+	}
+	if k.Get("a") != k.Get("a") {
+		t.Error("expect the same limiter of the same key")
+	}
+	if n := k.Len(); n != 2 {
+		t.Errorf("expect 2 limiters, but got %d", n)
+	}
+
+	if n := k.Cleanup(); n != 0 {
+		t.Errorf("unexpected the idle limiters: %d", n)
+	}
+
+	time.Sleep(time.Millisecond * 100)
+	k.Get("c")
+	if n := k.Len(); n != 1 {
+		t.Errorf("expect 1 limiter after removing the idle ones, but got %d", n)
+	}
+
+	k.Remove("c")
+	if n := k.Len(); n != 0 {
+		t.Errorf("expect no limiters, but got %d", n)
+	}
+}
+
+func TestKeyedLimiterAcquire(t *testing.T) {
+	k := NewKeyedLimiter(100, 1, time.Millisecond*50)
+	l, release := k.Acquire("a")
+	if l != k.Get("a") {
+		t.Error("expect the same limiter of the same key")
+	}
+	l.Allow()
+
+	time.Sleep(time.Millisecond * 100)
+	if n := k.Cleanup(); n != 0 {
+		t.Errorf("expect the acquired limiter not to be removed, but removed %d", n)
+	}
+
+	release()
+	release()
+	if n := k.Cleanup(); n != 1 {
+		t.Errorf("expect 1 idle limiter to be removed, but got %d", n)
+	}
+}
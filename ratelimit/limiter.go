@@ -0,0 +1,281 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// Inf is the infinite rate, which allows all the events.
+const Inf = math.MaxFloat64
+
+// ErrExceedBurst is returned when waiting for more tokens than the burst.
+var ErrExceedBurst = errors.New("ratelimit: the tokens exceed the burst")
+
+// ErrTooLongWait is returned when the context would be done
+// before the tokens are available.
+var ErrTooLongWait = errors.New("ratelimit: the wait exceeds the context deadline")
+
+// Limiter is a token-bucket rate limiter, which is refilled
+// at rate tokens per second up to burst tokens.
+type Limiter struct {
+	lock   sync.Mutex
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a new Limiter, which allows rate events per second
+// with the burst size. The bucket is full initially.
+//
+// If burst is less than 1, it is the integral part of rate and at least 1.
+func NewLimiter(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		if burst = int(rate); burst < 1 || rate == Inf {
+			burst = 1
+		}
+	}
+	return &Limiter{rate: rate, burst: burst, tokens: float64(burst)}
+}
+
+// Rate returns the rate of the tokens per second.
+func (l *Limiter) Rate() float64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.rate
+}
+
+// Burst returns the burst size.
+func (l *Limiter) Burst() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.burst
+}
+
+// SetRate resets the rate of the tokens per second.
+func (l *Limiter) SetRate(rate float64) {
+	l.lock.Lock()
+	l.advance(time.Now())
+	l.rate = rate
+	l.lock.Unlock()
+}
+
+// Tokens returns the number of the available tokens now,
+// which is negative if the tokens have been reserved in advance.
+func (l *Limiter) Tokens() float64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.advance(time.Now())
+	return l.tokens
+}
+
+// idleSince returns the time since which the bucket has been full.
+// It returns false if the bucket is not full.
+func (l *Limiter) idleSince(now time.Time) (time.Time, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.advance(now)
+	return l.last, l.tokens >= float64(l.burst)
+}
+
+func (l *Limiter) advance(now time.Time) {
+	if l.last.IsZero() {
+		l.last = now
+		return
+	} else if !now.After(l.last) {
+		return
+	}
+
+	if l.rate > 0 && l.tokens < float64(l.burst) {
+		tokens := l.tokens + now.Sub(l.last).Seconds()*l.rate
+		if full := float64(l.burst); tokens >= full {
+			// Record the time when the bucket became full,
+			// which is used to detect the idle limiter.
+			l.last = now.Add(-time.Duration((tokens - full) / l.rate * float64(time.Second)))
+			l.tokens = full
+			return
+		}
+		l.tokens = tokens
+	}
+
+	if l.tokens < float64(l.burst) {
+		l.last = now
+	}
+}
+
+// reserve takes n tokens and returns the duration to wait for them.
+//
+// If strict is true, it fails when n exceeds the burst or the wait exceeds
+// maxWait, which is ignored if negative.
+func (l *Limiter) reserve(now time.Time, n int, maxWait time.Duration, strict bool) (time.Duration, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.rate == Inf {
+		return 0, nil
+	} else if strict && n > l.burst {
+		return 0, ErrExceedBurst
+	}
+
+	l.advance(now)
+	tokens := l.tokens - float64(n)
+
+	var wait time.Duration
+	if tokens < 0 {
+		if l.rate <= 0 {
+			return 0, ErrTooLongWait
+		}
+		wait = time.Duration(-tokens / l.rate * float64(time.Second))
+	}
+
+	if strict && maxWait >= 0 && wait > maxWait {
+		return 0, ErrTooLongWait
+	}
+
+	l.tokens = tokens
+	l.last = now
+	return wait, nil
+}
+
+// Allow is equal to l.AllowN(1).
+func (l *Limiter) Allow() bool {
+	return l.AllowN(1)
+}
+
+// AllowN reports whether n events may happen now, which takes n tokens
+// only if they are available.
+func (l *Limiter) AllowN(n int) bool {
+	_, err := l.reserve(time.Now(), n, 0, true)
+	return err == nil
+}
+
+// Reserve is equal to l.ReserveN(1).
+func (l *Limiter) Reserve() *Reservation {
+	return l.ReserveN(1)
+}
+
+// ReserveN takes n tokens in advance and returns a Reservation,
+// which tells how long to wait before n events happen.
+//
+// If n exceeds the burst, or the rate is not positive and the tokens
+// are not enough, the reservation is not OK and takes no tokens.
+func (l *Limiter) ReserveN(n int) *Reservation {
+	now := time.Now()
+	wait, err := l.reserve(now, n, -1, true)
+	if err != nil {
+		return &Reservation{}
+	}
+	return &Reservation{ok: true, limiter: l, tokens: n, at: now.Add(wait)}
+}
+
+// Wait is equal to l.WaitContext(ctx, 1).
+func (l *Limiter) Wait(ctx context.Context) error {
+	return l.WaitContext(ctx, 1)
+}
+
+// WaitContext blocks until n events are allowed or ctx is done.
+//
+// It returns an error without waiting if n exceeds the burst, or ctx would
+// be done before the tokens are available. If ctx is done while waiting,
+// it returns ctx.Err() and gives back the tokens.
+func (l *Limiter) WaitContext(ctx context.Context, n int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	now := time.Now()
+	maxWait := time.Duration(-1)
+	if deadline, ok := ctx.Deadline(); ok {
+		if maxWait = deadline.Sub(now); maxWait < 0 {
+			maxWait = 0
+		}
+	}
+
+	wait, err := l.reserve(now, n, maxWait, true)
+	if err != nil || wait <= 0 {
+		return err
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		r := Reservation{ok: true, limiter: l, tokens: n, at: now.Add(wait)}
+		r.Cancel()
+		return ctx.Err()
+	}
+}
+
+// WaitN blocks until n events are allowed, which implements the interface
+// io2.RateLimiter.
+//
+// Different from WaitContext, n may be larger than the burst, and it will
+// wait until the debt is paid off.
+func (l *Limiter) WaitN(n int) {
+	if wait, err := l.reserve(time.Now(), n, -1, false); err == nil && wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// Reservation is the tokens reserved by the limiter in advance.
+type Reservation struct {
+	ok      bool
+	limiter *Limiter
+	tokens  int
+	at      time.Time
+}
+
+// OK reports whether the tokens are reserved successfully.
+func (r *Reservation) OK() bool {
+	return r.ok
+}
+
+// Delay returns the duration to wait before the reserved events happen.
+//
+// It returns 0 if the reservation is not OK.
+func (r *Reservation) Delay() time.Duration {
+	if !r.ok {
+		return 0
+	} else if d := time.Until(r.at); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Cancel gives back the reserved tokens to the limiter if the reserved
+// events have not happened, which may be called more than once.
+func (r *Reservation) Cancel() {
+	if !r.ok || r.tokens == 0 || !r.at.After(time.Now()) {
+		return
+	}
+
+	l := r.limiter
+	l.lock.Lock()
+	l.advance(time.Now())
+	if l.tokens += float64(r.tokens); l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.lock.Unlock()
+	r.tokens = 0
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	l := NewLimiter(10, 3)
+	for i := 0; i < 3; i++ {
+		if !l.Allow() {
+			t.Errorf("expect to allow the event %d", i)
+		}
+	}
+	if l.Allow() {
+		t.Error("expect to deny the event beyond the burst")
+	}
+	if l.AllowN(4) {
+		t.Error("expect to deny the tokens exceeding the burst")
+	}
+
+	time.Sleep(time.Millisecond * 120)
+	if !l.Allow() {
+		t.Error("expect to allow the event after refilling")
+	}
+
+	if inf := NewLimiter(Inf, 0); !inf.AllowN(100) {
+		t.Error("expect to allow all the events by Inf")
+	}
+}
+
+func TestLimiterReserve(t *testing.T) {
+	l := NewLimiter(100, 1)
+	if r := l.Reserve(); !r.OK() || r.Delay() != 0 {
+		t.Errorf("unexpected reservation: %v, %s", r.OK(), r.Delay())
+	}
+
+	r := l.Reserve()
+	if !r.OK() || r.Delay() <= 0 || r.Delay() > time.Millisecond*10 {
+		t.Errorf("unexpected reservation: %v, %s", r.OK(), r.Delay())
+	}
+	if tokens := l.Tokens(); tokens >= 0 {
+		t.Errorf("expect the negative tokens, but got %f", tokens)
+	}
+
+	r.Cancel()
+	if tokens := l.Tokens(); tokens < 0 {
+		t.Errorf("expect to give back the tokens, but got %f", tokens)
+	}
+
+	if r := l.ReserveN(2); r.OK() {
+		t.Error("expect the reservation to fail")
+	}
+}
+
+func TestLimiterWait(t *testing.T) {
+	l := NewLimiter(100, 1)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Error(err)
+		}
+	}
+	if cost := time.Since(start); cost < time.Millisecond*15 {
+		t.Errorf("wait too short: %s", cost)
+	}
+
+	if err := l.WaitContext(ctx, 2); err != ErrExceedBurst {
+		t.Errorf("expect ErrExceedBurst, but got %v", err)
+	}
+
+	l = NewLimiter(1, 1)
+	l.Allow()
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond*10)
+	defer cancel()
+	if err := l.Wait(ctx); err != ErrTooLongWait {
+		t.Errorf("expect ErrTooLongWait, but got %v", err)
+	}
+}
+
+func TestLimiterWaitN(t *testing.T) {
+	l := NewLimiter(1000, 100)
+	start := time.Now()
+	l.WaitN(300) // Exceed the burst.
+	if cost := time.Since(start); cost < time.Millisecond*150 {
+		t.Errorf("wait too short: %s", cost)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"net"
+
+	"github.com/xgfone/go-tools/io2"
+	"github.com/xgfone/go-tools/net2"
+)
+
+// RemoteIP returns the ip of the remote address of the connection,
+// which is used as the key of the per-IP limiters.
+func RemoteIP(conn net.Conn) string {
+	addr := conn.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// PerConnLimiters returns a function used as net2.ConnOptions.NewLimiters,
+// which throttles the reading and writing bandwidth of each connection
+// to rate bytes per second with the burst size respectively.
+//
+// The limiter is disabled if its rate is not positive.
+func PerConnLimiters(readRate, writeRate float64, burst int) func(net.Conn) (read, write io2.RateLimiter) {
+	return func(net.Conn) (read, write io2.RateLimiter) {
+		if readRate > 0 {
+			read = NewLimiter(readRate, burst)
+		}
+		if writeRate > 0 {
+			write = NewLimiter(writeRate, burst)
+		}
+		return
+	}
+}
+
+// PerIPLimiters returns a function used as net2.ConnOptions.NewLimiters,
+// which throttles the total reading and writing bandwidth of all
+// the connections from the same IP.
+//
+// The limiters are acquired by the connection until it is closed,
+// so they won't be removed as idle while the connection is alive.
+//
+// Either of read and write may be nil, which is disabled.
+func PerIPLimiters(read, write *KeyedLimiter) func(net.Conn) (read, write io2.RateLimiter) {
+	return func(conn net.Conn) (r, w io2.RateLimiter) {
+		ip := RemoteIP(conn)
+		if read != nil {
+			r = acquireLimiter(read, ip)
+		}
+		if write != nil {
+			w = acquireLimiter(write, ip)
+		}
+		return
+	}
+}
+
+// connLimiter is the limiter acquired by a connection, which is released
+// when the connection is closed.
+type connLimiter struct {
+	*Limiter
+	release func()
+}
+
+func acquireLimiter(k *KeyedLimiter, key string) connLimiter {
+	l, release := k.Acquire(key)
+	return connLimiter{Limiter: l, release: release}
+}
+
+func (l connLimiter) Close() error {
+	l.release()
+	return nil
+}
+
+// LimitConnsPerIP returns a server middleware to limit the rate
+// of the new connections from each IP by the keyed limiter.
+//
+// If the rate is exceeded, onLimited is called if not nil, then
+// the connection is closed without calling the handler.
+func LimitConnsPerIP(limiter *KeyedLimiter, onLimited func(net.Conn)) net2.Middleware {
+	return func(next net2.Handler) net2.Handler {
+		return net2.HandlerFunc(func(ctx context.Context, conn net.Conn) {
+			if !limiter.Allow(RemoteIP(conn)) {
+				if onLimited != nil {
+					onLimited(conn)
+				}
+				return
+			}
+			next.Handle(ctx, conn)
+		})
+	}
+}
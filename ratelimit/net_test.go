@@ -0,0 +1,96 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-tools/net2"
+)
+
+func TestLimitConnsPerIP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limited := make(chan string, 1)
+	onLimited := func(conn net.Conn) { limited <- RemoteIP(conn) }
+	handler := net2.HandlerFunc(func(ctx context.Context, conn net.Conn) { io.Copy(conn, conn) })
+
+	s := net2.NewServer(handler,
+		net2.WithMiddlewares(LimitConnsPerIP(NewKeyedLimiter(1, 1, 0), onLimited)),
+		net2.WithConnOptions(net2.ConnOptions{NewLimiters: PerConnLimiters(0, 1000, 100)}))
+	go s.Serve(ln)
+	defer s.Stop()
+
+	conn1, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn1.Close()
+
+	start := time.Now()
+	buf := make([]byte, 300)
+	conn1.Write(buf)
+	conn1.SetReadDeadline(time.Now().Add(time.Second * 2))
+	if _, err = io.ReadFull(conn1, buf); err != nil {
+		t.Error(err)
+	} else if cost := time.Since(start); cost < time.Millisecond*150 {
+		t.Errorf("the server writes too fast: %s", cost)
+	}
+
+	conn2, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn2.Close()
+
+	select {
+	case ip := <-limited:
+		if ip != "127.0.0.1" {
+			t.Errorf("unexpected limited ip '%s'", ip)
+		}
+	case <-time.After(time.Second):
+		t.Error("expect the second connection to be limited")
+	}
+}
+
+func TestPerIPLimiters(t *testing.T) {
+	keyed := NewKeyedLimiter(1000, 100, time.Millisecond*50)
+	opts := net2.ConnOptions{NewLimiters: PerIPLimiters(nil, keyed)}
+
+	c1, c2 := net.Pipe()
+	defer c2.Close()
+	conn, err := opts.Apply(c1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyed.Allow(RemoteIP(c1))
+
+	time.Sleep(time.Millisecond * 100)
+	if n := keyed.Cleanup(); n != 0 {
+		t.Errorf("expect the limiter of the alive connection not to be removed, but removed %d", n)
+	}
+
+	conn.Close()
+	if n := keyed.Cleanup(); n != 1 {
+		t.Errorf("expect the limiter to be removed after closing the connection, but got %d", n)
+	}
+}
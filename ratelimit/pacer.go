@@ -0,0 +1,87 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Pacer is a leaky-bucket rate limiter, which spaces the operations evenly
+// at the fixed interval instead of allowing them in bursts, so it is used
+// to smooth the traffic to the upstream with the strict rate cap.
+type Pacer struct {
+	lock     sync.Mutex
+	interval time.Duration
+	slack    time.Duration
+	next     time.Time
+}
+
+// NewPacer returns a new Pacer, which allows rate operations per second.
+//
+// slack is the maximum duration of the unused time slots that may be
+// caught up after being idle, which allows the small bursts. The default
+// is 0, that's, the operations are always spaced by the interval.
+func NewPacer(rate float64, slack time.Duration) *Pacer {
+	if rate <= 0 {
+		panic("ratelimit: the pacer rate must be positive")
+	}
+	if slack < 0 {
+		slack = 0
+	}
+	return &Pacer{interval: time.Duration(float64(time.Second) / rate), slack: slack}
+}
+
+// Interval returns the interval duration between two operations.
+func (p *Pacer) Interval() time.Duration {
+	return p.interval
+}
+
+// Take is equal to p.TakeN(1).
+func (p *Pacer) Take() time.Duration {
+	return p.TakeN(1)
+}
+
+// TakeN takes the time slots of n operations, and returns the duration
+// that the caller should sleep before doing them.
+func (p *Pacer) TakeN(n int) time.Duration {
+	now := time.Now()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if earliest := now.Add(-p.slack); p.next.Before(earliest) {
+		p.next = earliest
+	}
+
+	wait := p.next.Sub(now)
+	p.next = p.next.Add(p.interval * time.Duration(n))
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// Wait takes a time slot and sleeps until it's time to do the operation.
+func (p *Pacer) Wait() {
+	p.WaitN(1)
+}
+
+// WaitN takes the time slots of n operations and sleeps until it's time
+// to do them, which implements the interface io2.RateLimiter.
+func (p *Pacer) WaitN(n int) {
+	if wait := p.TakeN(n); wait > 0 {
+		time.Sleep(wait)
+	}
+}
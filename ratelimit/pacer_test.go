@@ -0,0 +1,92 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-tools/net2"
+)
+
+func TestPacer(t *testing.T) {
+	p := NewPacer(100, 0)
+	if interval := p.Interval(); interval != time.Millisecond*10 {
+		t.Errorf("expect the interval 10ms, but got %s", interval)
+	}
+
+	if wait := p.Take(); wait != 0 {
+		t.Errorf("expect the first operation to start immediately, but got %s", wait)
+	}
+	if wait := p.Take(); wait <= time.Millisecond*5 || wait > time.Millisecond*10 {
+		t.Errorf("unexpected the wait duration: %s", wait)
+	}
+	if wait := p.TakeN(2); wait <= time.Millisecond*15 || wait > time.Millisecond*20 {
+		t.Errorf("unexpected the wait duration: %s", wait)
+	}
+
+	start := time.Now()
+	p.Wait()
+	if cost := time.Since(start); cost < time.Millisecond*30 {
+		t.Errorf("wait too short: %s", cost)
+	}
+}
+
+func TestPacerSlack(t *testing.T) {
+	p := NewPacer(100, time.Millisecond*30)
+	p.Take()
+	time.Sleep(time.Millisecond * 50)
+
+	// Catch up with the unused time slots within the slack.
+	var n int
+	for p.Take() == 0 {
+		n++
+	}
+	if n < 3 || n > 4 {
+		t.Errorf("expect 3 or 4 operations without waiting, but got %d", n)
+	}
+}
+
+func TestPacerReconnectingConn(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := net2.NewServer(net2.HandlerFunc(func(ctx context.Context, conn net.Conn) {
+		io.Copy(ioutil.Discard, conn)
+	}))
+	go s.Serve(ln)
+	defer s.Stop()
+
+	conn := net2.NewReconnectingConn(ln.Addr().String())
+	conn.WriteLimiter = NewPacer(1000, 0)
+	defer conn.Close()
+
+	start := time.Now()
+	buf := make([]byte, 10)
+	for i := 0; i < 4; i++ {
+		if _, err = conn.Write(buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cost := time.Since(start); cost < time.Millisecond*30 {
+		t.Errorf("the client writes too fast: %s", cost)
+	}
+}
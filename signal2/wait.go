@@ -0,0 +1,98 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signal2
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ExitSignals is the default signals to exit the program used by WaitExit.
+var ExitSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+
+// ForceExitCode is the exit code when receiving the exit signal again.
+var ForceExitCode = 1
+
+var exit = os.Exit
+
+// WaitExit blocks until receiving one of the exit signals or ctx is done,
+// and returns the signal, or ctx.Err() if ctx is done. ctx may be nil.
+//
+// After returning the signal, if receiving the exit signal again,
+// for example, pressing Ctrl+C twice, the program exits immediately
+// with ForceExitCode even if the graceful shutdown has not finished.
+//
+// If the signals are empty, it is ExitSignals by default.
+func WaitExit(ctx context.Context, signals ...os.Signal) (os.Signal, error) {
+	if len(signals) == 0 {
+		signals = ExitSignals
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	select {
+	case sig := <-ch:
+		go func() {
+			<-ch
+			exit(ForceExitCode)
+		}()
+		return sig, nil
+	case <-ctx.Done():
+		signal.Stop(ch)
+		return nil, ctx.Err()
+	}
+}
+
+// OnSignal calls the callback f each time receiving one of the signals,
+// such as reloading the configuration by SIGHUP.
+//
+// f is called serially in a background goroutine. The signals received
+// while f is running are merged into one, so f should return quickly.
+//
+// The returned function is used to stop handling the signals.
+func OnSignal(f func(os.Signal), sig os.Signal, signals ...os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	sigs := make([]os.Signal, 0, len(signals)+1)
+	sigs = append(sigs, sig)
+	sigs = append(sigs, signals...)
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				f(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var stopped bool
+	return func() {
+		if !stopped {
+			stopped = true
+			signal.Stop(ch)
+			close(done)
+		}
+	}
+}
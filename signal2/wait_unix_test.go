@@ -0,0 +1,96 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package signal2
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func sendSignal(t *testing.T, sig os.Signal) {
+	if err := syscall.Kill(os.Getpid(), sig.(syscall.Signal)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitExit(t *testing.T) {
+	codes := make(chan int, 1)
+	exit = func(code int) { codes <- code }
+	defer func() { exit = os.Exit }()
+
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		sendSignal(t, syscall.SIGUSR1)
+	}()
+
+	sig, err := WaitExit(context.Background(), syscall.SIGUSR1)
+	if err != nil || sig != syscall.SIGUSR1 {
+		t.Fatalf("sig=%v, err=%v", sig, err)
+	}
+
+	// Force to exit when receiving the signal again.
+	sendSignal(t, syscall.SIGUSR1)
+	select {
+	case code := <-codes:
+		if code != ForceExitCode {
+			t.Errorf("unexpected exit code %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Error("expect to exit forcibly")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if _, err := WaitExit(ctx, syscall.SIGUSR2); err != context.DeadlineExceeded {
+		t.Errorf("expect context.DeadlineExceeded, but got %v", err)
+	}
+}
+
+func TestOnSignal(t *testing.T) {
+	signals := make(chan os.Signal, 2)
+	stop := OnSignal(func(sig os.Signal) { signals <- sig }, syscall.SIGHUP)
+
+	for i := 0; i < 2; i++ {
+		sendSignal(t, syscall.SIGHUP)
+		select {
+		case sig := <-signals:
+			if sig != syscall.SIGHUP {
+				t.Errorf("unexpected signal %v", sig)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expect the signal SIGHUP")
+		}
+	}
+
+	stop()
+	stop()
+}
+
+func TestOnSignalNotModifyArgs(t *testing.T) {
+	signals := make([]os.Signal, 1, 2)
+	signals[0] = syscall.SIGUSR1
+	stop := OnSignal(func(os.Signal) {}, syscall.SIGHUP, signals...)
+	defer stop()
+
+	if sig := signals[:2][1]; sig != nil {
+		t.Errorf("the backing array of the signals is modified: %v", sig)
+	}
+}
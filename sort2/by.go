@@ -0,0 +1,180 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xgfone/go-tools/function"
+)
+
+type orderKey struct {
+	key  func(interface{}) interface{}
+	desc bool
+}
+
+// Comparator is a builder to compare the values by multiple keys in order.
+//
+// For example,
+//
+//	less := By(func(v interface{}) interface{} { return v.(User).Age }).Desc().
+//		ThenBy(func(v interface{}) interface{} { return v.(User).Name }).Less
+//	Interfaces(users, less)
+type Comparator struct {
+	keys []orderKey
+}
+
+// By returns a new Comparator which compares the values by the key
+// in ascending order.
+//
+// The key must be one of bool, string, the integers, the floats, time.Time
+// or function.Comparer, and the keys of all the values must have the same type.
+func By(key func(v interface{}) interface{}) *Comparator {
+	return new(Comparator).ThenBy(key)
+}
+
+// ThenBy appends the key to compare the values in ascending order
+// if they are equal by the prior keys.
+func (c *Comparator) ThenBy(key func(v interface{}) interface{}) *Comparator {
+	c.keys = append(c.keys, orderKey{key: key})
+	return c
+}
+
+// Asc sets the last key in ascending order.
+func (c *Comparator) Asc() *Comparator {
+	if len(c.keys) > 0 {
+		c.keys[len(c.keys)-1].desc = false
+	}
+	return c
+}
+
+// Desc sets the last key in descending order.
+func (c *Comparator) Desc() *Comparator {
+	if len(c.keys) > 0 {
+		c.keys[len(c.keys)-1].desc = true
+	}
+	return c
+}
+
+// Compare compares the two values by the keys in order, and returns
+// a negative integer, 0 or a positive integer.
+func (c *Comparator) Compare(first, second interface{}) int {
+	for _, k := range c.keys {
+		if r := compareKey(k.key(first), k.key(second)); r != 0 {
+			if k.desc {
+				return -r
+			}
+			return r
+		}
+	}
+	return 0
+}
+
+// Less reports whether first is less than second, which may be used
+// as the less function of Interfaces.
+func (c *Comparator) Less(first, second interface{}) bool {
+	return c.Compare(first, second) < 0
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUint64(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareKey(first, second interface{}) int {
+	switch v1 := first.(type) {
+	case bool:
+		if v2 := second.(bool); v1 == v2 {
+			return 0
+		} else if v2 {
+			return -1
+		}
+		return 1
+	case string:
+		v2 := second.(string)
+		switch {
+		case v1 < v2:
+			return -1
+		case v1 > v2:
+			return 1
+		}
+		return 0
+	case int:
+		return compareInt64(int64(v1), int64(second.(int)))
+	case int8:
+		return compareInt64(int64(v1), int64(second.(int8)))
+	case int16:
+		return compareInt64(int64(v1), int64(second.(int16)))
+	case int32:
+		return compareInt64(int64(v1), int64(second.(int32)))
+	case int64:
+		return compareInt64(v1, second.(int64))
+	case time.Duration:
+		return compareInt64(int64(v1), int64(second.(time.Duration)))
+	case uint:
+		return compareUint64(uint64(v1), uint64(second.(uint)))
+	case uint8:
+		return compareUint64(uint64(v1), uint64(second.(uint8)))
+	case uint16:
+		return compareUint64(uint64(v1), uint64(second.(uint16)))
+	case uint32:
+		return compareUint64(uint64(v1), uint64(second.(uint32)))
+	case uint64:
+		return compareUint64(v1, second.(uint64))
+	case float32:
+		return compareFloat64(float64(v1), float64(second.(float32)))
+	case float64:
+		return compareFloat64(v1, second.(float64))
+	case time.Time:
+		v2 := second.(time.Time)
+		switch {
+		case v1.Before(v2):
+			return -1
+		case v1.After(v2):
+			return 1
+		}
+		return 0
+	case function.Comparer:
+		return v1.Compare(second)
+	default:
+		panic(fmt.Errorf("unsupported key type '%T'", first))
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import "fmt"
+
+func ExampleBy() {
+	type user struct {
+		Name string
+		Age  int
+	}
+
+	users := []interface{}{
+		user{"c", 20},
+		user{"a", 30},
+		user{"b", 20},
+		user{"d", 30},
+	}
+
+	age := func(v interface{}) interface{} { return v.(user).Age }
+	name := func(v interface{}) interface{} { return v.(user).Name }
+	Interfaces(users, By(age).Desc().ThenBy(name).Asc().Less)
+	fmt.Println(users)
+
+	// Output:
+	// [{a 30} {d 30} {b 20} {c 20}]
+}
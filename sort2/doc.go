@@ -13,4 +13,10 @@
 // limitations under the License.
 
 // Package sort2 is the supplement of the standard library of `sort`.
+//
+// Notice: the module supports Go 1.12, which has no generics, so there is
+// no generic Slice[T]. For the typed slice, use sort.Slice or sort.SliceStable
+// of the standard library instead of boxing it into []interface{}, such as
+//
+//	sort.SliceStable(users, func(i, j int) bool { return users[i].Age < users[j].Age })
 package sort2
@@ -0,0 +1,386 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import (
+	"bufio"
+	"container/heap"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// RecordCodec is used to encode the records into the temporary run files,
+// and decode them from there.
+type RecordCodec interface {
+	Encode(w io.Writer, record interface{}) error
+
+	// Decode decodes a record, and returns io.EOF when there is no record.
+	Decode(r *bufio.Reader) (interface{}, error)
+}
+
+// LineCodec is a RecordCodec for the string records without the newline,
+// such as the lines of the logs.
+type LineCodec struct{}
+
+// Encode implements the interface RecordCodec.
+func (LineCodec) Encode(w io.Writer, record interface{}) (err error) {
+	if _, err = io.WriteString(w, record.(string)); err == nil {
+		_, err = io.WriteString(w, "\n")
+	}
+	return
+}
+
+// Decode implements the interface RecordCodec.
+func (LineCodec) Decode(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	} else if err != nil {
+		return nil, err
+	}
+	return strings.TrimSuffix(line, "\n"), nil
+}
+
+// ExternalSorter sorts the records which may be larger than the memory,
+// which sorts every MaxRecords records in memory and writes them into
+// a temporary run file, then merges all the run files, MaxFanIn files
+// at a time.
+type ExternalSorter struct {
+	Codec RecordCodec
+	Less  func(first, second interface{}) bool
+
+	// MaxRecords is the maximum number of the records in memory.
+	// The default is 100000.
+	MaxRecords int
+
+	// MaxFanIn is the maximum number of the run files opened at a time
+	// to be merged. If there are more run files, they will be merged into
+	// the fewer ones in several passes. The default is 64.
+	MaxFanIn int
+
+	// TempDir is the directory of the temporary run files.
+	// The default is os.TempDir().
+	TempDir string
+
+	runs    []string
+	records []interface{}
+}
+
+// NewExternalSorter returns a new ExternalSorter.
+func NewExternalSorter(codec RecordCodec, less func(first, second interface{}) bool,
+	maxRecords int) *ExternalSorter {
+	return &ExternalSorter{Codec: codec, Less: less, MaxRecords: maxRecords}
+}
+
+// Add adds a record to be sorted.
+func (s *ExternalSorter) Add(record interface{}) error {
+	max := s.MaxRecords
+	if max <= 0 {
+		max = 100000
+	}
+
+	s.records = append(s.records, record)
+	if len(s.records) >= max {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *ExternalSorter) flush() error {
+	if len(s.records) == 0 {
+		return nil
+	}
+
+	sort.Stable(interfaceSlice{data: s.records, less: s.Less})
+	run, err := s.writeRun(func(w io.Writer) (err error) {
+		for _, r := range s.records {
+			if err = s.Codec.Encode(w, r); err != nil {
+				return
+			}
+		}
+		return
+	})
+	if err != nil {
+		return err // Keep the records to retry.
+	}
+
+	s.runs = append(s.runs, run)
+	s.records = s.records[:0]
+	return nil
+}
+
+// writeRun writes the records into a new temporary run file, which will be
+// removed if failing to write.
+func (s *ExternalSorter) writeRun(write func(io.Writer) error) (string, error) {
+	file, err := ioutil.TempFile(s.TempDir, "sort2-run-")
+	if err != nil {
+		return "", err
+	}
+
+	w := bufio.NewWriter(file)
+	if err = write(w); err == nil {
+		err = w.Flush()
+	}
+	if e := file.Close(); err == nil {
+		err = e
+	}
+
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// merge merges the run files in passes until their number is no more
+// than fanIn. The adjacent runs are merged together to keep it stable.
+func (s *ExternalSorter) merge(fanIn int) error {
+	for len(s.runs) > fanIn {
+		runs := make([]string, 0, (len(s.runs)+fanIn-1)/fanIn)
+		for i := 0; i < len(s.runs); i += fanIn {
+			end := i + fanIn
+			if end > len(s.runs) {
+				end = len(s.runs)
+			}
+
+			if end-i == 1 {
+				runs = append(runs, s.runs[i])
+				continue
+			}
+
+			run, err := s.mergeRuns(s.runs[i:end])
+			if err != nil {
+				for _, run := range runs {
+					os.Remove(run)
+				}
+				return err
+			}
+			runs = append(runs, run)
+		}
+		s.runs = runs
+	}
+	return nil
+}
+
+// mergeRuns merges the run files into a new one, and removes them.
+func (s *ExternalSorter) mergeRuns(runs []string) (string, error) {
+	iter, err := s.open(runs)
+	if err != nil {
+		return "", err
+	}
+
+	run, err := s.writeRun(func(w io.Writer) error {
+		for iter.Next() {
+			if err := s.Codec.Encode(w, iter.Record()); err != nil {
+				return err
+			}
+		}
+		return iter.Err()
+	})
+	iter.closeFiles()
+
+	if err == nil {
+		for _, run := range runs {
+			os.Remove(run)
+		}
+	}
+	return run, err
+}
+
+// open returns an iterator merging the run files.
+func (s *ExternalSorter) open(runs []string) (*ExternalIterator, error) {
+	iter := &ExternalIterator{sorter: s, heap: &runHeap{less: s.Less}}
+	for i, run := range runs {
+		file, err := os.Open(run)
+		if err != nil {
+			iter.closeFiles()
+			return nil, err
+		}
+
+		r := &runReader{index: i, file: file, reader: bufio.NewReader(file)}
+		iter.files = append(iter.files, file)
+		if err = r.next(s.Codec); err == io.EOF {
+			continue
+		} else if err != nil {
+			iter.closeFiles()
+			return nil, err
+		}
+		iter.heap.runs = append(iter.heap.runs, r)
+	}
+	heap.Init(iter.heap)
+	return iter, nil
+}
+
+// Clean removes all the temporary run files.
+func (s *ExternalSorter) Clean() {
+	for _, run := range s.runs {
+		os.Remove(run)
+	}
+	s.runs = nil
+	s.records = nil
+}
+
+// Sort sorts all the records added and returns an iterator over them.
+//
+// The sorter should not be used any more, and you must close the iterator
+// to remove the temporary run files.
+func (s *ExternalSorter) Sort() (*ExternalIterator, error) {
+	if len(s.runs) == 0 {
+		sort.Stable(interfaceSlice{data: s.records, less: s.Less})
+		return &ExternalIterator{sorter: s, records: s.records}, nil
+	}
+
+	fanIn := s.MaxFanIn
+	if fanIn < 2 {
+		fanIn = 64
+	}
+
+	if err := s.flush(); err != nil {
+		s.Clean()
+		return nil, err
+	}
+
+	if err := s.merge(fanIn); err != nil {
+		s.Clean()
+		return nil, err
+	}
+
+	iter, err := s.open(s.runs)
+	if err != nil {
+		s.Clean()
+		return nil, err
+	}
+	return iter, nil
+}
+
+// ExternalIterator is an iterator over the records sorted by ExternalSorter.
+//
+// For example,
+//
+//	iter, err := sorter.Sort()
+//	if err != nil {
+//		return err
+//	}
+//	defer iter.Close()
+//
+//	for iter.Next() {
+//		process(iter.Record())
+//	}
+//	return iter.Err()
+type ExternalIterator struct {
+	sorter *ExternalSorter
+	files  []*os.File
+	heap   *runHeap
+	record interface{}
+	err    error
+
+	// For the records in memory.
+	records []interface{}
+}
+
+// Next moves to the next record, and reports whether there is a record.
+func (it *ExternalIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if it.heap == nil {
+		if len(it.records) == 0 {
+			return false
+		}
+		it.record = it.records[0]
+		it.records = it.records[1:]
+		return true
+	}
+
+	if it.heap.Len() == 0 {
+		return false
+	}
+
+	r := it.heap.runs[0]
+	it.record = r.record
+	if err := r.next(it.sorter.Codec); err == io.EOF {
+		heap.Pop(it.heap)
+	} else if err != nil {
+		it.err = err
+		return false
+	} else {
+		heap.Fix(it.heap, 0)
+	}
+	return true
+}
+
+// Record returns the current record.
+func (it *ExternalIterator) Record() interface{} {
+	return it.record
+}
+
+// Err returns the error occurred during iterating.
+func (it *ExternalIterator) Err() error {
+	return it.err
+}
+
+// Close closes the iterator and removes the temporary run files.
+func (it *ExternalIterator) Close() error {
+	it.closeFiles()
+	it.sorter.Clean()
+	return nil
+}
+
+func (it *ExternalIterator) closeFiles() {
+	for _, file := range it.files {
+		file.Close()
+	}
+	it.files = nil
+}
+
+type runReader struct {
+	index  int
+	file   *os.File
+	reader *bufio.Reader
+	record interface{}
+}
+
+func (r *runReader) next(codec RecordCodec) (err error) {
+	r.record, err = codec.Decode(r.reader)
+	return
+}
+
+type runHeap struct {
+	runs []*runReader
+	less func(first, second interface{}) bool
+}
+
+func (h *runHeap) Len() int      { return len(h.runs) }
+func (h *runHeap) Swap(i, j int) { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+func (h *runHeap) Less(i, j int) bool {
+	ri, rj := h.runs[i], h.runs[j]
+	if h.less(ri.record, rj.record) {
+		return true
+	} else if h.less(rj.record, ri.record) {
+		return false
+	}
+	return ri.index < rj.index // Keep it stable.
+}
+
+func (h *runHeap) Push(x interface{}) { h.runs = append(h.runs, x.(*runReader)) }
+func (h *runHeap) Pop() interface{} {
+	r := h.runs[len(h.runs)-1]
+	h.runs = h.runs[:len(h.runs)-1]
+	return r
+}
@@ -0,0 +1,134 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func testExternalSorter(t *testing.T, n, maxRecords, maxFanIn int) {
+	dir, err := ioutil.TempDir("", "sort2-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	less := func(v1, v2 interface{}) bool { return v1.(string) < v2.(string) }
+	sorter := NewExternalSorter(LineCodec{}, less, maxRecords)
+	sorter.TempDir = dir
+	sorter.MaxFanIn = maxFanIn
+
+	expects := make([]string, n)
+	for i := range expects {
+		expects[i] = strconv.Itoa(rand.Intn(n))
+		if err := sorter.Add(expects[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sort.Strings(expects)
+
+	iter, err := sorter.Sort()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var results []string
+	for iter.Next() {
+		results = append(results, iter.Record().(string))
+	}
+	if err := iter.Err(); err != nil {
+		t.Error(err)
+	}
+	iter.Close()
+
+	if len(results) != len(expects) {
+		t.Fatalf("expect %d records, got %d", len(expects), len(results))
+	}
+	for i := range results {
+		if results[i] != expects[i] {
+			t.Fatalf("%d: expect '%s', got '%s'", i, expects[i], results[i])
+		}
+	}
+
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("the temporary run files are not removed: %d", len(files))
+	}
+}
+
+func TestExternalSorter(t *testing.T) {
+	testExternalSorter(t, 0, 10, 0)
+	testExternalSorter(t, 100, 1000, 0)
+	testExternalSorter(t, 1000, 64, 0)
+	testExternalSorter(t, 1000, 100, 0)
+
+	// Merge the runs in several passes.
+	testExternalSorter(t, 1000, 10, 2)
+	testExternalSorter(t, 1000, 10, 3)
+}
+
+type failCodec struct {
+	LineCodec
+	fail bool
+}
+
+func (c *failCodec) Encode(w io.Writer, record interface{}) error {
+	if c.fail {
+		return errors.New("encode error")
+	}
+	return c.LineCodec.Encode(w, record)
+}
+
+func TestExternalSorterFlushError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sort2-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	codec := &failCodec{fail: true}
+	less := func(v1, v2 interface{}) bool { return v1.(string) < v2.(string) }
+	sorter := NewExternalSorter(codec, less, 2)
+	sorter.TempDir = dir
+
+	sorter.Add("b")
+	if err := sorter.Add("a"); err == nil {
+		t.Fatal("expect an error")
+	} else if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("the broken run file is not removed: %d", len(files))
+	}
+
+	codec.fail = false
+	sorter.Add("c")
+	iter, err := sorter.Sort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+
+	var results []string
+	for iter.Next() {
+		results = append(results, iter.Record().(string))
+	}
+	if len(results) != 3 || results[0] != "a" || results[1] != "b" || results[2] != "c" {
+		t.Errorf("unexpected records: %v", results)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import "sort"
+
+// Group is a group of the elements with the same key.
+type Group struct {
+	Key   interface{}
+	Items []interface{}
+}
+
+// GroupSort partitions the elements by the key, which must be comparable,
+// and sorts the elements stably by less within each group.
+//
+// The groups are returned in the order that their keys are encountered.
+// If less is nil, the elements in the group keep the original order.
+func GroupSort(data []interface{}, key func(interface{}) interface{},
+	less func(first, second interface{}) bool) []Group {
+	var groups []Group
+	indexes := make(map[interface{}]int)
+	for _, v := range data {
+		k := key(v)
+		if i, ok := indexes[k]; ok {
+			groups[i].Items = append(groups[i].Items, v)
+		} else {
+			indexes[k] = len(groups)
+			groups = append(groups, Group{Key: k, Items: []interface{}{v}})
+		}
+	}
+
+	if less != nil {
+		for _, g := range groups {
+			sort.Stable(interfaceSlice{data: g.Items, less: less})
+		}
+	}
+	return groups
+}
+
+// GroupSortFlat is the same as GroupSort, but flattens the groups in order.
+func GroupSortFlat(data []interface{}, key func(interface{}) interface{},
+	less func(first, second interface{}) bool) []interface{} {
+	results := make([]interface{}, 0, len(data))
+	for _, g := range GroupSort(data, key, less) {
+		results = append(results, g.Items...)
+	}
+	return results
+}
+
+// GroupSortMap is the same as GroupSort, but returns a map from the key
+// to the sorted elements.
+func GroupSortMap(data []interface{}, key func(interface{}) interface{},
+	less func(first, second interface{}) bool) map[interface{}][]interface{} {
+	groups := GroupSort(data, key, less)
+	results := make(map[interface{}][]interface{}, len(groups))
+	for _, g := range groups {
+		results[g.Key] = g.Items
+	}
+	return results
+}
@@ -0,0 +1,44 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import "fmt"
+
+func ExampleGroupSort() {
+	type log struct {
+		Host string
+		Time int
+	}
+
+	logs := []interface{}{
+		log{"b", 3}, log{"a", 2}, log{"b", 1}, log{"a", 1}, log{"c", 5},
+	}
+
+	host := func(v interface{}) interface{} { return v.(log).Host }
+	less := func(v1, v2 interface{}) bool { return v1.(log).Time < v2.(log).Time }
+
+	for _, g := range GroupSort(logs, host, less) {
+		fmt.Println(g.Key, g.Items)
+	}
+	fmt.Println(GroupSortFlat(logs, host, less))
+	fmt.Println(GroupSortMap(logs, host, less)["a"])
+
+	// Output:
+	// b [{b 1} {b 3}]
+	// a [{a 1} {a 2}]
+	// c [{c 5}]
+	// [{b 1} {b 3} {a 1} {a 2} {c 5}]
+	// [{a 1} {a 2}]
+}
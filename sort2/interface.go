@@ -43,9 +43,30 @@ func (s interfaceSlice) Swap(i, j int) {
 // less is a function to compare the two elements of the slice data,
 // which returns true if the first is less than the second, or returns false.
 //
-// If giving the second argument and it's true, sort the data in reverse.
-func Interfaces(data []interface{}, less func(first, second interface{}) bool) {
+// If giving the third argument and it's true, sort the data in reverse.
+func Interfaces(data []interface{}, less func(first, second interface{}) bool, reverse ...bool) {
 	if len(data) > 1 {
-		sort.Sort(interfaceSlice{data: data, less: less})
+		sort.Sort(newInterfaceSlice(data, less, reverse))
 	}
 }
+
+// InterfacesStable is the same as Interfaces, but keeps the original order
+// of the equal elements.
+func InterfacesStable(data []interface{}, less func(first, second interface{}) bool, reverse ...bool) {
+	if len(data) > 1 {
+		sort.Stable(newInterfaceSlice(data, less, reverse))
+	}
+}
+
+// Reverse returns a new less function which inverts the order of less.
+func Reverse(less func(first, second interface{}) bool) func(first, second interface{}) bool {
+	return func(first, second interface{}) bool { return less(second, first) }
+}
+
+func newInterfaceSlice(data []interface{}, less func(interface{}, interface{}) bool,
+	reverse []bool) interfaceSlice {
+	if len(reverse) > 0 && reverse[0] {
+		less = Reverse(less)
+	}
+	return interfaceSlice{data: data, less: less}
+}
@@ -26,3 +26,22 @@ func ExampleInterfaces() {
 	// Output:
 	// [1 2 3 4 5]
 }
+
+func ExampleInterfacesStable() {
+	type pair struct{ k, v int }
+	data := []interface{}{pair{2, 1}, pair{1, 2}, pair{2, 3}, pair{1, 4}}
+	InterfacesStable(data, func(v1, v2 interface{}) bool { return v1.(pair).k < v2.(pair).k }, true)
+	fmt.Println(data)
+
+	// Output:
+	// [{2 1} {2 3} {1 2} {1 4}]
+}
+
+func ExampleReverse() {
+	data := []interface{}{3, 2, 4, 1, 5}
+	Interfaces(data, Reverse(func(v1, v2 interface{}) bool { return v1.(int) < v2.(int) }))
+	fmt.Println(data)
+
+	// Output:
+	// [5 4 3 2 1]
+}
@@ -0,0 +1,112 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// KeyValue is a key-value pair of the map.
+type KeyValue struct {
+	Key   string
+	Value interface{}
+}
+
+func mapValue(m interface{}) reflect.Value {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		panic(fmt.Errorf("the value is not a map with the string key, but '%T'", m))
+	}
+	return v
+}
+
+// SortedKeys returns the keys of the map m in increasing order.
+//
+// m must be a map whose key is a string type, or panic.
+func SortedKeys(m interface{}) []string {
+	var keys []string
+	switch _m := m.(type) {
+	case map[string]string:
+		keys = make([]string, 0, len(_m))
+		for k := range _m {
+			keys = append(keys, k)
+		}
+	case map[string]interface{}:
+		keys = make([]string, 0, len(_m))
+		for k := range _m {
+			keys = append(keys, k)
+		}
+	default:
+		v := mapValue(m)
+		keys = make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// EachSorted calls f with each key-value pair of the map m in the key order.
+//
+// m must be a map whose key is a string type, or panic.
+func EachSorted(m interface{}, f func(key string, value interface{})) {
+	v := mapValue(m)
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, k := range keys {
+		f(k.String(), v.MapIndex(k).Interface())
+	}
+}
+
+// SortMapByValue returns the key-value pairs of the map m ordered by the value
+// with less. The pairs with the equal values are ordered by the key.
+//
+// m must be a map whose key is a string type, or panic.
+func SortMapByValue(m interface{}, less func(first, second interface{}) bool) []KeyValue {
+	v := mapValue(m)
+	kvs := make([]KeyValue, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		kvs = append(kvs, KeyValue{Key: k.String(), Value: v.MapIndex(k).Interface()})
+	}
+
+	sort.Slice(kvs, func(i, j int) bool {
+		if less(kvs[i].Value, kvs[j].Value) {
+			return true
+		} else if less(kvs[j].Value, kvs[i].Value) {
+			return false
+		}
+		return kvs[i].Key < kvs[j].Key
+	})
+	return kvs
+}
+
+// SortedStringMap is a map[string]string which is iterated in the key order.
+type SortedStringMap map[string]string
+
+// Keys returns the keys in increasing order.
+func (m SortedStringMap) Keys() []string {
+	return SortedKeys(map[string]string(m))
+}
+
+// Each calls f with each key-value pair in the key order.
+func (m SortedStringMap) Each(f func(key, value string)) {
+	for _, k := range m.Keys() {
+		f(k, m[k])
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import "fmt"
+
+func ExampleSortedKeys() {
+	fmt.Println(SortedKeys(map[string]int{"c": 1, "a": 2, "b": 3}))
+
+	// Output:
+	// [a b c]
+}
+
+func ExampleEachSorted() {
+	EachSorted(map[string]int{"c": 1, "a": 2, "b": 3}, func(k string, v interface{}) {
+		fmt.Println(k, v)
+	})
+
+	// Output:
+	// a 2
+	// b 3
+	// c 1
+}
+
+func ExampleSortMapByValue() {
+	m := map[string]int{"c": 1, "a": 2, "b": 1, "d": 3}
+	kvs := SortMapByValue(m, func(v1, v2 interface{}) bool { return v1.(int) < v2.(int) })
+	fmt.Println(kvs)
+
+	// Output:
+	// [{b 1} {c 1} {a 2} {d 3}]
+}
+
+func ExampleSortedStringMap() {
+	SortedStringMap{"c": "1", "a": "2", "b": "3"}.Each(func(k, v string) {
+		fmt.Println(k, v)
+	})
+
+	// Output:
+	// a 2
+	// b 3
+	// c 1
+}
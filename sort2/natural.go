@@ -0,0 +1,37 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import (
+	"sort"
+
+	"github.com/xgfone/go-tools/strings2"
+)
+
+// NaturalStringSlice attaches the methods of Interface to []string,
+// sorting in the natural order, such as "file2" < "file10".
+type NaturalStringSlice []string
+
+func (p NaturalStringSlice) Len() int           { return len(p) }
+func (p NaturalStringSlice) Less(i, j int) bool { return strings2.NaturalLess(p[i], p[j]) }
+func (p NaturalStringSlice) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// Sort is a convenience method.
+func (p NaturalStringSlice) Sort() { sort.Sort(p) }
+
+// NaturalStrings sorts a slice of []string in the natural order.
+//
+// See strings2.NaturalCompare.
+func NaturalStrings(a []string) { sort.Sort(NaturalStringSlice(a)) }
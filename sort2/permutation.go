@@ -0,0 +1,65 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// SortIndex returns the permutation indices which sort the n elements
+// stably by less, that's, the i-th element after sorting is the perm[i]-th
+// element before sorting. less compares the i-th and j-th original elements.
+//
+// It does not modify any data, so you can apply the permutation to many
+// parallel slices by ApplyPermutation.
+func SortIndex(n int, less func(i, j int) bool) (perm []int) {
+	perm = make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	sort.SliceStable(perm, func(i, j int) bool { return less(perm[i], perm[j]) })
+	return
+}
+
+// SortWithIndex sorts the interface slice stably by less, and returns
+// the permutation applied. See SortIndex.
+func SortWithIndex(data []interface{}, less func(first, second interface{}) bool) (perm []int) {
+	perm = SortIndex(len(data), func(i, j int) bool { return less(data[i], data[j]) })
+	ApplyPermutation(data, perm)
+	return
+}
+
+// ApplyPermutation reorders the slice by the permutation returned
+// by SortIndex or SortWithIndex, which the new i-th element is
+// the old perm[i]-th element.
+//
+// slice must be a slice with the same length as perm, or panic.
+func ApplyPermutation(slice interface{}, perm []int) {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Slice {
+		panic(fmt.Errorf("the value is not a slice, but '%T'", slice))
+	} else if v.Len() != len(perm) {
+		panic(fmt.Errorf("the length of the slice is %d, but the permutation is %d",
+			v.Len(), len(perm)))
+	}
+
+	old := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	reflect.Copy(old, v)
+	for i, j := range perm {
+		v.Index(i).Set(old.Index(j))
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import "fmt"
+
+func ExampleSortIndex() {
+	values := []int{30, 10, 20}
+	labels := []string{"c", "a", "b"}
+
+	perm := SortIndex(len(values), func(i, j int) bool { return values[i] < values[j] })
+	ApplyPermutation(values, perm)
+	ApplyPermutation(labels, perm)
+	fmt.Println(perm, values, labels)
+
+	// Output:
+	// [1 2 0] [10 20 30] [a b c]
+}
+
+func ExampleSortWithIndex() {
+	data := []interface{}{3, 1, 2}
+	timestamps := []int64{300, 100, 200}
+
+	perm := SortWithIndex(data, func(v1, v2 interface{}) bool { return v1.(int) < v2.(int) })
+	ApplyPermutation(timestamps, perm)
+	fmt.Println(data, timestamps)
+
+	// Output:
+	// [1 2 3] [100 200 300]
+}
@@ -0,0 +1,158 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import "sort"
+
+// radixThreshold is the minimum length of the slice to use the radix sort,
+// and the comparison sort is used for the shorter slice.
+const radixThreshold = 256
+
+// radixUint64s sorts the slice by the LSD radix sort with the base 256,
+// which compares the elements XORed with flip.
+func radixUint64s(a []uint64, flip uint64) {
+	buf := make([]uint64, len(a))
+	src, dst := a, buf
+	for shift := uint(0); shift < 64; shift += 8 {
+		var counts [256]int
+		for _, v := range src {
+			counts[byte((v^flip)>>shift)]++
+		}
+
+		// Skip the pass if all the elements have the same byte.
+		if counts[byte((src[0]^flip)>>shift)] == len(src) {
+			continue
+		}
+
+		offset := 0
+		for i, c := range counts {
+			counts[i] = offset
+			offset += c
+		}
+
+		for _, v := range src {
+			b := byte((v ^ flip) >> shift)
+			dst[counts[b]] = v
+			counts[b]++
+		}
+		src, dst = dst, src
+	}
+
+	if &src[0] != &a[0] {
+		copy(a, src)
+	}
+}
+
+// Uint64sFast sorts a slice of []uint64 in increasing order by the radix sort,
+// which is faster than Uint64s for the large slice but allocates a buffer
+// with the same length.
+func Uint64sFast(a []uint64) {
+	if len(a) < radixThreshold {
+		Uint64s(a)
+		return
+	}
+	radixUint64s(a, 0)
+}
+
+// Int64sFast is the same as Uint64sFast, but for []int64.
+func Int64sFast(a []int64) {
+	if len(a) < radixThreshold {
+		Int64s(a)
+		return
+	}
+
+	// Flip the sign bit so that the negative is less than the positive,
+	// and reinterpret []int64 as []uint64 by copying.
+	us := make([]uint64, len(a))
+	for i, v := range a {
+		us[i] = uint64(v)
+	}
+	radixUint64s(us, 1<<63)
+	for i, v := range us {
+		a[i] = int64(v)
+	}
+}
+
+// IntsFast is the same as Uint64sFast, but for []int.
+func IntsFast(a []int) {
+	if len(a) < radixThreshold {
+		sort.Ints(a)
+		return
+	}
+
+	us := make([]uint64, len(a))
+	for i, v := range a {
+		us[i] = uint64(v)
+	}
+	radixUint64s(us, 1<<63)
+	for i, v := range us {
+		a[i] = int(v)
+	}
+}
+
+// StringsFast sorts a slice of []string in increasing order by the MSD radix
+// sort, which is faster than sort.Strings for the large slice.
+func StringsFast(a []string) {
+	if len(a) < radixThreshold {
+		sort.Strings(a)
+		return
+	}
+	msdStrings(a, make([]string, len(a)), 0)
+}
+
+func msdStrings(a, buf []string, depth int) {
+	if len(a) < 32 {
+		sort.Strings(a)
+		return
+	}
+
+	// The bucket 0 is for the strings ending at depth, which are the smallest.
+	var counts [257]int
+	for _, s := range a {
+		counts[charAt(s, depth)]++
+	}
+
+	if counts[0] == len(a) {
+		return
+	}
+
+	var offsets [257]int
+	for i, offset := 0, 0; i < len(counts); i++ {
+		offsets[i] = offset
+		offset += counts[i]
+	}
+
+	pos := offsets
+	for _, s := range a {
+		c := charAt(s, depth)
+		buf[pos[c]] = s
+		pos[c]++
+	}
+	copy(a, buf[:len(a)])
+
+	for i := 1; i < len(counts); i++ {
+		if counts[i] > 1 {
+			start, end := offsets[i], offsets[i]+counts[i]
+			msdStrings(a[start:end], buf[start:end], depth+1)
+		}
+	}
+}
+
+func charAt(s string, i int) int {
+	if i < len(s) {
+		return int(s[i]) + 1
+	}
+	return 0
+}
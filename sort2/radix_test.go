@@ -0,0 +1,80 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestRadixSort(t *testing.T) {
+	for _, n := range []int{0, 10, 1000, 10000} {
+		ints := make([]int, n)
+		int64s := make([]int64, n)
+		uint64s := make([]uint64, n)
+		strs := make([]string, n)
+		for i := 0; i < n; i++ {
+			int64s[i] = rand.Int63() - rand.Int63()
+			ints[i] = int(int64s[i])
+			uint64s[i] = rand.Uint64()
+			strs[i] = strconv.FormatInt(rand.Int63n(int64(n)+1), 36)
+		}
+
+		IntsFast(ints)
+		Int64sFast(int64s)
+		Uint64sFast(uint64s)
+		StringsFast(strs)
+
+		if !sort.IntsAreSorted(ints) || !Int64sAreSorted(int64s) ||
+			!Uint64sAreSorted(uint64s) || !sort.StringsAreSorted(strs) {
+			t.Errorf("%d: not sorted", n)
+		}
+	}
+}
+
+func benchmarkInt64s(b *testing.B, sort func([]int64)) {
+	data := make([]int64, 100000)
+	for i := range data {
+		data[i] = rand.Int63()
+	}
+
+	a := make([]int64, len(data))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(a, data)
+		sort(a)
+	}
+}
+
+func benchmarkStrings(b *testing.B, sort func([]string)) {
+	data := make([]string, 100000)
+	for i := range data {
+		data[i] = strconv.FormatInt(rand.Int63(), 36)
+	}
+
+	a := make([]string, len(data))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(a, data)
+		sort(a)
+	}
+}
+
+func BenchmarkInt64s(b *testing.B)      { benchmarkInt64s(b, Int64s) }
+func BenchmarkInt64sFast(b *testing.B)  { benchmarkInt64s(b, Int64sFast) }
+func BenchmarkStrings(b *testing.B)     { benchmarkStrings(b, sort.Strings) }
+func BenchmarkStringsFast(b *testing.B) { benchmarkStrings(b, StringsFast) }
@@ -0,0 +1,99 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+)
+
+func randIntn(r *rand.Rand, n int) int {
+	if r == nil {
+		return rand.Intn(n)
+	}
+	return r.Intn(n)
+}
+
+func randFloat64(r *rand.Rand) float64 {
+	if r == nil {
+		return rand.Float64()
+	}
+	return r.Float64()
+}
+
+// Shuffle shuffles the elements of the slice by the random source r.
+//
+// If r is nil, use the default source of the package math/rand.
+// slice must be a slice, or panic.
+func Shuffle(slice interface{}, r *rand.Rand) {
+	ShuffleN(slice, -1, r)
+}
+
+// ShuffleN is the same as Shuffle, but only shuffles the first n elements
+// by the partial Fisher–Yates, that's, the first n elements is a random
+// sample of all the elements. If n is negative or larger than the length
+// of the slice, it shuffles all the elements.
+func ShuffleN(slice interface{}, n int, r *rand.Rand) {
+	length := reflect.ValueOf(slice).Len()
+	if n < 0 || n > length {
+		n = length
+	}
+
+	swap := reflect.Swapper(slice)
+	for i := 0; i < n && i < length-1; i++ {
+		swap(i, i+randIntn(r, length-i))
+	}
+}
+
+// WeightedIndex returns the index selected randomly by the weights,
+// which returns -1 if there are no positive weights.
+//
+// The negative weight is considered as 0. If r is nil, use the default
+// source of the package math/rand.
+func WeightedIndex(weights []float64, r *rand.Rand) int {
+	cumulative := make([]float64, len(weights))
+	var total float64
+	for i, w := range weights {
+		if w > 0 {
+			total += w
+		}
+		cumulative[i] = total
+	}
+
+	if total <= 0 {
+		return -1
+	}
+
+	x := randFloat64(r) * total
+	return sort.Search(len(cumulative), func(i int) bool { return cumulative[i] > x })
+}
+
+// WeightedChoice returns an item selected randomly by the weights,
+// which returns nil if there are no positive weights.
+//
+// items and weights must have the same length, or panic. See WeightedIndex.
+func WeightedChoice(items []interface{}, weights []float64, r *rand.Rand) interface{} {
+	if len(items) != len(weights) {
+		panic(fmt.Errorf("the number of the items is %d, but the weights is %d",
+			len(items), len(weights)))
+	}
+
+	if i := WeightedIndex(weights, r); i >= 0 {
+		return items[i]
+	}
+	return nil
+}
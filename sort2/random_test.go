@@ -0,0 +1,66 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestShuffle(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	ints := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	Shuffle(ints, r)
+	if sort.IntsAreSorted(ints) {
+		t.Error(ints)
+	}
+	sort.Ints(ints)
+	for i, v := range ints {
+		if i != v {
+			t.Fatal(ints)
+		}
+	}
+
+	ShuffleN(ints, 3, r)
+	seen := make(map[int]bool)
+	for _, v := range ints {
+		seen[v] = true
+	}
+	if len(seen) != len(ints) {
+		t.Error(ints)
+	}
+
+	Shuffle([]int{}, nil)
+	ShuffleN([]string{"a"}, 5, nil)
+}
+
+func TestWeightedChoice(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	if WeightedIndex(nil, r) != -1 || WeightedIndex([]float64{0, -1}, r) != -1 {
+		t.Fail()
+	}
+
+	items := []interface{}{"a", "b", "c"}
+	weights := []float64{1, 0, 3}
+	counts := make(map[interface{}]int)
+	for i := 0; i < 4000; i++ {
+		counts[WeightedChoice(items, weights, r)]++
+	}
+
+	if counts["b"] != 0 || counts["a"] < 800 || counts["a"] > 1200 || counts["c"] < 2800 {
+		t.Error(counts)
+	}
+}
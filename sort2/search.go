@@ -0,0 +1,79 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import "sort"
+
+// SearchInts searches x in the sorted slice a in increasing order,
+// and returns the index and true if found. Or return the index to insert x
+// and false.
+func SearchInts(a []int, x int) (index int, found bool) {
+	index = sort.SearchInts(a, x)
+	return index, index < len(a) && a[index] == x
+}
+
+// SearchInt64s is the same as SearchInts, but for []int64.
+func SearchInt64s(a []int64, x int64) (index int, found bool) {
+	index = sort.Search(len(a), func(i int) bool { return a[i] >= x })
+	return index, index < len(a) && a[index] == x
+}
+
+// SearchUint64s is the same as SearchInts, but for []uint64.
+func SearchUint64s(a []uint64, x uint64) (index int, found bool) {
+	index = sort.Search(len(a), func(i int) bool { return a[i] >= x })
+	return index, index < len(a) && a[index] == x
+}
+
+// SearchStrings is the same as SearchInts, but for []string.
+func SearchStrings(a []string, x string) (index int, found bool) {
+	index = sort.SearchStrings(a, x)
+	return index, index < len(a) && a[index] == x
+}
+
+// SearchSlice is the same as SearchInts, but for the interface slice
+// sorted by less.
+func SearchSlice(data []interface{}, x interface{}, less func(first, second interface{}) bool) (index int, found bool) {
+	index = sort.Search(len(data), func(i int) bool { return !less(data[i], x) })
+	return index, index < len(data) && !less(x, data[index])
+}
+
+// InsertInts inserts x into the sorted slice a and keeps it sorted,
+// then returns the new slice.
+func InsertInts(a []int, x int) []int {
+	i, _ := SearchInts(a, x)
+	a = append(a, 0)
+	copy(a[i+1:], a[i:])
+	a[i] = x
+	return a
+}
+
+// InsertStrings is the same as InsertInts, but for []string.
+func InsertStrings(a []string, x string) []string {
+	i, _ := SearchStrings(a, x)
+	a = append(a, "")
+	copy(a[i+1:], a[i:])
+	a[i] = x
+	return a
+}
+
+// InsertSlice is the same as InsertInts, but for the interface slice
+// sorted by less.
+func InsertSlice(data []interface{}, x interface{}, less func(first, second interface{}) bool) []interface{} {
+	i, _ := SearchSlice(data, x, less)
+	data = append(data, nil)
+	copy(data[i+1:], data[i:])
+	data[i] = x
+	return data
+}
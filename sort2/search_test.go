@@ -0,0 +1,62 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import (
+	"fmt"
+	"testing"
+)
+
+func ExampleSearchInts() {
+	a := []int{1, 3, 5}
+	fmt.Println(SearchInts(a, 3))
+	fmt.Println(SearchInts(a, 4))
+	fmt.Println(InsertInts(a, 4))
+
+	// Output:
+	// 1 true
+	// 2 false
+	// [1 3 4 5]
+}
+
+func TestSearch(t *testing.T) {
+	if i, ok := SearchStrings([]string{"a", "c"}, "d"); ok || i != 2 {
+		t.Error(i, ok)
+	}
+	if i, ok := SearchInt64s([]int64{1, 2}, 2); !ok || i != 1 {
+		t.Error(i, ok)
+	}
+	if i, ok := SearchUint64s(nil, 2); ok || i != 0 {
+		t.Error(i, ok)
+	}
+	if ss := InsertStrings([]string{"a", "c"}, "b"); len(ss) != 3 || ss[1] != "b" {
+		t.Error(ss)
+	}
+
+	less := func(v1, v2 interface{}) bool { return v1.(int) < v2.(int) }
+	data := []interface{}{}
+	for _, v := range []int{3, 1, 2, 2} {
+		data = InsertSlice(data, v, less)
+	}
+	if fmt.Sprint(data) != "[1 2 2 3]" {
+		t.Error(data)
+	}
+	if i, ok := SearchSlice(data, 2, less); !ok || i != 1 {
+		t.Error(i, ok)
+	}
+	if i, ok := SearchSlice(data, 0, less); ok || i != 0 {
+		t.Error(i, ok)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import "sort"
+
+// Int64sAreSorted reports whether the slice a is sorted in increasing order.
+func Int64sAreSorted(a []int64) bool { return sort.IsSorted(Int64Slice(a)) }
+
+// Uint64sAreSorted reports whether the slice a is sorted in increasing order.
+func Uint64sAreSorted(a []uint64) bool { return sort.IsSorted(Uint64Slice(a)) }
+
+// UintsAreSorted reports whether the slice a is sorted in increasing order.
+func UintsAreSorted(a []uint) bool { return sort.IsSorted(UintSlice(a)) }
+
+// InterfacesAreSorted reports whether the interface slice is sorted by less.
+func InterfacesAreSorted(data []interface{}, less func(first, second interface{}) bool) bool {
+	return sort.IsSorted(interfaceSlice{data: data, less: less})
+}
+
+// EnsureSorted sorts data only if it is not sorted, which checks it in O(n)
+// first, and reports whether it has sorted data.
+func EnsureSorted(data sort.Interface) (sorted bool) {
+	if sort.IsSorted(data) {
+		return false
+	}
+	sort.Sort(data)
+	return true
+}
+
+// EnsureInterfacesSorted is the same as EnsureSorted, but for the interface
+// slice sorted by less.
+func EnsureInterfacesSorted(data []interface{}, less func(first, second interface{}) bool) (sorted bool) {
+	return EnsureSorted(interfaceSlice{data: data, less: less})
+}
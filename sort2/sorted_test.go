@@ -0,0 +1,50 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestIsSorted(t *testing.T) {
+	if !Int64sAreSorted([]int64{1, 2, 2}) || Int64sAreSorted([]int64{2, 1}) ||
+		!Uint64sAreSorted(nil) || UintsAreSorted([]uint{3, 1}) {
+		t.Fail()
+	}
+
+	less := func(v1, v2 interface{}) bool { return v1.(int) < v2.(int) }
+	if !InterfacesAreSorted([]interface{}{1, 2}, less) || InterfacesAreSorted([]interface{}{2, 1}, less) {
+		t.Fail()
+	}
+}
+
+func TestEnsureSorted(t *testing.T) {
+	ints := []int{1, 2, 3}
+	if EnsureSorted(sort.IntSlice(ints)) {
+		t.Error("expect not to sort")
+	}
+
+	ints = []int{3, 1, 2}
+	if !EnsureSorted(sort.IntSlice(ints)) || !sort.IntsAreSorted(ints) {
+		t.Error(ints)
+	}
+
+	less := func(v1, v2 interface{}) bool { return v1.(int) < v2.(int) }
+	data := []interface{}{2, 1}
+	if !EnsureInterfacesSorted(data, less) || data[0].(int) != 1 {
+		t.Error(data)
+	}
+}
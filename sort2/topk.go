@@ -0,0 +1,58 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// boundedHeap is a max-heap by less, whose top is the largest.
+type boundedHeap interfaceSlice
+
+func (h boundedHeap) Len() int            { return len(h.data) }
+func (h boundedHeap) Less(i, j int) bool  { return h.less(h.data[j], h.data[i]) }
+func (h boundedHeap) Swap(i, j int)       { h.data[i], h.data[j] = h.data[j], h.data[i] }
+func (h *boundedHeap) Push(x interface{}) { h.data = append(h.data, x) }
+func (h *boundedHeap) Pop() interface{} {
+	x := h.data[len(h.data)-1]
+	h.data = h.data[:len(h.data)-1]
+	return x
+}
+
+// TopK returns the k smallest elements of data by less in increasing order,
+// which uses a bounded heap in O(n*log(k)) and does not modify data.
+//
+// For the k largest elements, use Reverse(less) instead.
+func TopK(data []interface{}, k int, less func(first, second interface{}) bool) []interface{} {
+	if k <= 0 {
+		return []interface{}{}
+	} else if k > len(data) {
+		k = len(data)
+	}
+
+	h := &boundedHeap{data: make([]interface{}, 0, k), less: less}
+	for _, v := range data {
+		if len(h.data) < k {
+			heap.Push(h, v)
+		} else if less(v, h.data[0]) {
+			h.data[0] = v
+			heap.Fix(h, 0)
+		}
+	}
+
+	sort.Sort(interfaceSlice(*h))
+	return h.data
+}
@@ -0,0 +1,54 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort2
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func ExampleTopK() {
+	less := func(v1, v2 interface{}) bool { return v1.(int) < v2.(int) }
+	data := []interface{}{5, 1, 9, 3, 7, 2, 8}
+	fmt.Println(TopK(data, 3, less))
+	fmt.Println(TopK(data, 3, Reverse(less)))
+
+	// Output:
+	// [1 2 3]
+	// [9 8 7]
+}
+
+func TestTopK(t *testing.T) {
+	less := func(v1, v2 interface{}) bool { return v1.(int) < v2.(int) }
+	data := make([]interface{}, 1000)
+	for i, v := range rand.Perm(len(data)) {
+		data[i] = v
+	}
+
+	if vs := TopK(data, 0, less); len(vs) != 0 {
+		t.Error(vs)
+	}
+	if vs := TopK(data[:3], 10, less); len(vs) != 3 {
+		t.Error(vs)
+	}
+
+	vs := TopK(data, 10, less)
+	for i, v := range vs {
+		if v.(int) != i {
+			t.Errorf("%d: expect %d, got %v", i, i, v)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strings2
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// NaturalCompare compares the two strings in the natural order, which compares
+// the runs of the digits by their numeric values, such as "file2" < "file10".
+//
+// It returns a negative integer, 0 or a positive integer. If two digit runs
+// have the same numeric value, the one with less leading zeros is smaller.
+func NaturalCompare(a, b string) int {
+	var tie int
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		ca, cb := a[i], b[j]
+		if !isDigit(ca) || !isDigit(cb) {
+			if ca != cb {
+				if ca < cb {
+					return -1
+				}
+				return 1
+			}
+			i++
+			j++
+			continue
+		}
+
+		// Skip the leading zeros.
+		zi, zj := i, j
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		zi, zj = i-zi, j-zj
+
+		// Extract the digit runs.
+		si, sj := i, j
+		for i < len(a) && isDigit(a[i]) {
+			i++
+		}
+		for j < len(b) && isDigit(b[j]) {
+			j++
+		}
+
+		na, nb := a[si:i], b[sj:j]
+		if len(na) != len(nb) {
+			if len(na) < len(nb) {
+				return -1
+			}
+			return 1
+		} else if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		} else if tie == 0 && zi != zj {
+			if zi < zj {
+				tie = -1
+			} else {
+				tie = 1
+			}
+		}
+	}
+
+	switch {
+	case len(a)-i < len(b)-j:
+		return -1
+	case len(a)-i > len(b)-j:
+		return 1
+	}
+	return tie
+}
+
+// NaturalLess reports whether a is less than b in the natural order.
+//
+// See NaturalCompare.
+func NaturalLess(a, b string) bool {
+	return NaturalCompare(a, b) < 0
+}
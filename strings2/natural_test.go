@@ -0,0 +1,44 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strings2
+
+import "testing"
+
+func TestNaturalCompare(t *testing.T) {
+	for _, c := range []struct {
+		a, b string
+		r    int
+	}{
+		{"", "", 0},
+		{"a", "", 1},
+		{"file2", "file10", -1},
+		{"file10", "file2", 1},
+		{"file10", "file10", 0},
+		{"file02", "file2", 1},
+		{"file002", "file02a", -1},
+		{"v1.2.10", "v1.2.9", 1},
+		{"a1b2", "a1b10", -1},
+		{"abc", "abd", -1},
+		{"x9", "xa", -1},
+	} {
+		if r := NaturalCompare(c.a, c.b); r != c.r {
+			t.Errorf("'%s' vs '%s': expect %d, got %d", c.a, c.b, c.r, r)
+		}
+	}
+
+	if !NaturalLess("img9.png", "img10.png") || NaturalLess("img10.png", "img9.png") {
+		t.Fail()
+	}
+}
@@ -54,6 +54,11 @@ func (i *AtomicInt32) CompareAndSwap(oldval, newval int32) (swapped bool) {
 	return atomic.CompareAndSwapInt32(&i.int32, oldval, newval)
 }
 
+// Swap atomically sets n as new value and returns the old value.
+func (i *AtomicInt32) Swap(n int32) int32 {
+	return atomic.SwapInt32(&i.int32, n)
+}
+
 // AtomicInt64 is a wrapper with a simpler interface around atomic.(Add|Store|Load|CompareAndSwap)Int64 functions.
 type AtomicInt64 struct {
 	int64
@@ -84,6 +89,11 @@ func (i *AtomicInt64) CompareAndSwap(oldval, newval int64) (swapped bool) {
 	return atomic.CompareAndSwapInt64(&i.int64, oldval, newval)
 }
 
+// Swap atomically sets n as new value and returns the old value.
+func (i *AtomicInt64) Swap(n int64) int64 {
+	return atomic.SwapInt64(&i.int64, n)
+}
+
 // AtomicDuration is a wrapper with a simpler interface around atomic.(Add|Store|Load|CompareAndSwap)Int64 functions.
 type AtomicDuration struct {
 	int64
@@ -114,6 +124,11 @@ func (d *AtomicDuration) CompareAndSwap(oldval, newval time.Duration) (swapped b
 	return atomic.CompareAndSwapInt64(&d.int64, int64(oldval), int64(newval))
 }
 
+// Swap atomically sets duration as new value and returns the old value.
+func (d *AtomicDuration) Swap(duration time.Duration) time.Duration {
+	return time.Duration(atomic.SwapInt64(&d.int64, int64(duration)))
+}
+
 // AtomicBool gives an atomic boolean variable.
 type AtomicBool struct {
 	int32
@@ -141,6 +156,23 @@ func (i *AtomicBool) Get() bool {
 	return atomic.LoadInt32(&i.int32) != 0
 }
 
+// Swap atomically sets n as new value and returns the old value.
+func (i *AtomicBool) Swap(n bool) bool {
+	return atomic.SwapInt32(&i.int32, bool2int32(n)) != 0
+}
+
+// CompareAndSwap atomatically swaps the old with the new value.
+func (i *AtomicBool) CompareAndSwap(oldval, newval bool) (swapped bool) {
+	return atomic.CompareAndSwapInt32(&i.int32, bool2int32(oldval), bool2int32(newval))
+}
+
+func bool2int32(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 // AtomicString gives you atomic-style APIs for string, but
 // it's only a convenience wrapper that uses a mutex. So, it's
 // not as efficient as the rest of the atomic types.
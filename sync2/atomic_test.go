@@ -20,6 +20,7 @@ package sync2
 
 import (
 	"testing"
+	"time"
 )
 
 func TestAtomicString(t *testing.T) {
@@ -59,3 +60,20 @@ func TestAtomicBool(t *testing.T) {
 		t.Error("b.Get: false, want true")
 	}
 }
+
+func TestAtomicSwap(t *testing.T) {
+	i32 := NewAtomicInt32(1)
+	i64 := NewAtomicInt64(1)
+	d := NewAtomicDuration(time.Second)
+	b := NewAtomicBool(false)
+
+	if i32.Swap(2) != 1 || i32.Get() != 2 || i64.Swap(2) != 1 || i64.Get() != 2 {
+		t.Fail()
+	}
+	if d.Swap(time.Minute) != time.Second || d.Get() != time.Minute {
+		t.Fail()
+	}
+	if b.Swap(true) || !b.Get() || b.CompareAndSwap(false, true) || !b.CompareAndSwap(true, false) || b.Get() {
+		t.Fail()
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+type valueBox struct {
+	value interface{}
+}
+
+// AtomicValue is an atomic container of any value, which is similar to
+// atomic.Value, but supports Swap and CompareAndSwap and the values
+// with the different types.
+//
+// The zero value is a nil value.
+type AtomicValue struct {
+	p unsafe.Pointer // *valueBox
+}
+
+// NewAtomicValue returns a new AtomicValue with the value v.
+func NewAtomicValue(v interface{}) *AtomicValue {
+	return &AtomicValue{p: unsafe.Pointer(&valueBox{value: v})}
+}
+
+// Get atomically returns the current value.
+func (v *AtomicValue) Get() interface{} {
+	if p := atomic.LoadPointer(&v.p); p != nil {
+		return (*valueBox)(p).value
+	}
+	return nil
+}
+
+// Set atomically sets value as new value.
+func (v *AtomicValue) Set(value interface{}) {
+	atomic.StorePointer(&v.p, unsafe.Pointer(&valueBox{value: value}))
+}
+
+// Swap atomically sets value as new value and returns the old value.
+func (v *AtomicValue) Swap(value interface{}) (old interface{}) {
+	if p := atomic.SwapPointer(&v.p, unsafe.Pointer(&valueBox{value: value})); p != nil {
+		old = (*valueBox)(p).value
+	}
+	return
+}
+
+// CompareAndSwap atomatically swaps the old with the new value if the current
+// value is equal to the old by ==. So the values must be comparable.
+func (v *AtomicValue) CompareAndSwap(oldval, newval interface{}) (swapped bool) {
+	box := unsafe.Pointer(&valueBox{value: newval})
+	for {
+		p := atomic.LoadPointer(&v.p)
+
+		var cur interface{}
+		if p != nil {
+			cur = (*valueBox)(p).value
+		}
+
+		if cur != oldval {
+			return false
+		} else if atomic.CompareAndSwapPointer(&v.p, p, box) {
+			return true
+		}
+	}
+}
+
+// AtomicError is an atomic container of error.
+type AtomicError struct {
+	v AtomicValue
+}
+
+// Get atomically returns the current error.
+func (e *AtomicError) Get() error {
+	if err := e.v.Get(); err != nil {
+		return err.(error)
+	}
+	return nil
+}
+
+// Set atomically sets err as new error.
+func (e *AtomicError) Set(err error) {
+	e.v.Set(err)
+}
+
+// Swap atomically sets err as new error and returns the old error.
+func (e *AtomicError) Swap(err error) error {
+	if old := e.v.Swap(err); old != nil {
+		return old.(error)
+	}
+	return nil
+}
+
+// CompareAndSwap atomatically swaps the old with the new error.
+//
+// It's usually used to set the first error, such as CompareAndSwap(nil, err).
+func (e *AtomicError) CompareAndSwap(oldval, newval error) (swapped bool) {
+	var o, n interface{}
+	if oldval != nil {
+		o = oldval
+	}
+	if newval != nil {
+		n = newval
+	}
+	return e.v.CompareAndSwap(o, n)
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestAtomicValue(t *testing.T) {
+	var v AtomicValue
+	if v.Get() != nil || !v.CompareAndSwap(nil, 1) || v.Get().(int) != 1 {
+		t.Fail()
+	}
+	if v.CompareAndSwap(2, 3) || v.Swap("a").(int) != 1 || v.Get().(string) != "a" {
+		t.Fail()
+	}
+
+	v = *NewAtomicValue(0)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				for {
+					n := v.Get().(int)
+					if v.CompareAndSwap(n, n+1) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if n := v.Get().(int); n != 1000 {
+		t.Error(n)
+	}
+}
+
+func TestAtomicError(t *testing.T) {
+	var e AtomicError
+	err1, err2 := errors.New("1"), errors.New("2")
+	if e.Get() != nil || !e.CompareAndSwap(nil, err1) || e.CompareAndSwap(nil, err2) {
+		t.Fail()
+	}
+	if e.Get() != err1 || e.Swap(err2) != err1 || e.Swap(nil) != err2 || e.Get() != nil {
+		t.Fail()
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"context"
+	"sync"
+)
+
+// Broadcast is an edge-triggered signal, which wakes up all the goroutines
+// waiting for it when notifying, like sync.Cond.Broadcast, but the wait
+// can be cancelled by the context.
+//
+// The zero value is ready to use.
+type Broadcast struct {
+	lock sync.Mutex
+	ch   chan struct{}
+}
+
+// C returns a channel that's closed by the next Notify, which is used
+// to wait for the signal in select with other channels.
+//
+// Notice: get the channel before checking the condition, or the signal
+// between checking and waiting may be lost.
+func (b *Broadcast) C() <-chan struct{} {
+	b.lock.Lock()
+	if b.ch == nil {
+		b.ch = make(chan struct{})
+	}
+	ch := b.ch
+	b.lock.Unlock()
+	return ch
+}
+
+// Wait blocks until the next Notify or ctx is done.
+// If ctx is done, it returns ctx.Err().
+func (b *Broadcast) Wait(ctx context.Context) error {
+	select {
+	case <-b.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Notify wakes up all the goroutines that are waiting.
+//
+// It's edge-triggered, so the goroutines waiting after Notify
+// will wait for the next one.
+func (b *Broadcast) Notify() {
+	b.lock.Lock()
+	if b.ch != nil {
+		close(b.ch)
+		b.ch = nil
+	}
+	b.lock.Unlock()
+}
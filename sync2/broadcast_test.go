@@ -0,0 +1,57 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBroadcast(t *testing.T) {
+	var b Broadcast
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		ch := b.C()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case <-ch:
+			case <-time.After(time.Second):
+				t.Error("expect to be woken up")
+			}
+		}()
+	}
+
+	b.Notify()
+	wg.Wait()
+
+	// Edge-triggered: the previous Notify does not wake up the new waiter.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := b.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expect context.DeadlineExceeded, but got %v", err)
+	}
+
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		b.Notify()
+	}()
+	if err := b.Wait(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
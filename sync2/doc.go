@@ -15,5 +15,5 @@
 // Package sync2 is the supplement of the standard library `sync`.
 //
 // This package supplies some types about the synchronization,
-// such as Semaphore, and some atomic types.
+// such as Semaphore, Mutex, Group, Future, and some atomic types.
 package sync2
@@ -0,0 +1,189 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"context"
+	"sync"
+
+	"github.com/xgfone/go-tools/errors2"
+)
+
+// Future is a placeholder of the result which will be completed later,
+// such as the response of the request.
+type Future struct {
+	once  sync.Once
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// NewFuture returns a new uncompleted Future.
+func NewFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+// Go returns a new Future which is completed by the result of f
+// running in a new goroutine.
+func Go(f func() (interface{}, error)) *Future {
+	future := NewFuture()
+	go func() { future.resolve(f()) }()
+	return future
+}
+
+func (f *Future) resolve(value interface{}, err error) (ok bool) {
+	f.once.Do(func() {
+		f.value, f.err = value, err
+		close(f.done)
+		ok = true
+	})
+	return
+}
+
+// Complete completes the future with the value, and reports whether
+// it's the first time to complete it.
+func (f *Future) Complete(value interface{}) bool {
+	return f.resolve(value, nil)
+}
+
+// Fail completes the future with the error, and reports whether
+// it's the first time to complete it.
+func (f *Future) Fail(err error) bool {
+	return f.resolve(nil, err)
+}
+
+// Done returns a channel which is closed when the future is completed.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// IsDone reports whether the future has been completed.
+func (f *Future) IsDone() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Get waits until the future is completed or ctx is done, and returns
+// the result. If ctx is done at first, it returns ctx.Err().
+//
+// If ctx is nil, wait until the future is completed.
+func (f *Future) Get(ctx context.Context) (interface{}, error) {
+	if ctx == nil {
+		<-f.done
+		return f.value, f.err
+	}
+
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Then returns a new Future which is completed by the result of next
+// with the value of the current future if it succeeds. Or it fails
+// with the same error.
+func (f *Future) Then(next func(value interface{}) (interface{}, error)) *Future {
+	future := NewFuture()
+	go func() {
+		<-f.done
+		if f.err != nil {
+			future.Fail(f.err)
+		} else {
+			future.resolve(next(f.value))
+		}
+	}()
+	return future
+}
+
+// All returns a new Future which is completed with the values of all
+// the futures as []interface{} in order when all of them succeed,
+// or fails with the first error.
+func All(futures ...*Future) *Future {
+	future := NewFuture()
+	go func() {
+		values := make([]interface{}, len(futures))
+		for i, f := range futures {
+			select {
+			case <-f.done:
+				if f.err != nil {
+					future.Fail(f.err)
+					return
+				}
+				values[i] = f.value
+			case <-future.done:
+				return
+			}
+		}
+		future.Complete(values)
+	}()
+
+	for _, f := range futures {
+		go func(f *Future) {
+			select {
+			case <-f.done:
+				if f.err != nil {
+					future.Fail(f.err)
+				}
+			case <-future.done:
+			}
+		}(f)
+	}
+	return future
+}
+
+// Any returns a new Future which is completed with the value of the first
+// succeeded future, or fails with all the errors as *errors2.Multi if all fail.
+func Any(futures ...*Future) *Future {
+	future := NewFuture()
+	if len(futures) == 0 {
+		future.Fail(&errors2.Multi{})
+		return future
+	}
+
+	var lock sync.Mutex
+	errs := make([]error, len(futures))
+	remaining := len(futures)
+	for i, f := range futures {
+		go func(i int, f *Future) {
+			select {
+			case <-f.done:
+			case <-future.done:
+				return
+			}
+
+			if f.err == nil {
+				future.Complete(f.value)
+				return
+			}
+
+			lock.Lock()
+			errs[i] = f.err
+			remaining--
+			last := remaining == 0
+			lock.Unlock()
+
+			if last {
+				future.Fail(&errors2.Multi{Errors: errs})
+			}
+		}(i, f)
+	}
+	return future
+}
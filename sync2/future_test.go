@@ -0,0 +1,92 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-tools/errors2"
+)
+
+func TestFuture(t *testing.T) {
+	f := NewFuture()
+	if f.IsDone() {
+		t.Fail()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if _, err := f.Get(ctx); err != context.DeadlineExceeded {
+		t.Error(err)
+	}
+
+	go f.Complete(1)
+	if v, err := f.Get(nil); err != nil || v.(int) != 1 {
+		t.Error(v, err)
+	}
+	if f.Complete(2) || f.Fail(errors.New("failed")) || !f.IsDone() {
+		t.Error("expect to complete only once")
+	}
+
+	double := func(v interface{}) (interface{}, error) { return v.(int) * 2, nil }
+	if v, err := f.Then(double).Then(double).Get(nil); err != nil || v.(int) != 4 {
+		t.Error(v, err)
+	}
+
+	errFailed := errors.New("failed")
+	failed := Go(func() (interface{}, error) { return nil, errFailed })
+	if _, err := failed.Then(double).Get(nil); err != errFailed {
+		t.Error(err)
+	}
+}
+
+func TestFutureAll(t *testing.T) {
+	f1 := Go(func() (interface{}, error) { time.Sleep(time.Millisecond * 10); return 1, nil })
+	f2 := Go(func() (interface{}, error) { return 2, nil })
+	if v, err := All(f1, f2).Get(nil); err != nil {
+		t.Error(err)
+	} else if vs := v.([]interface{}); len(vs) != 2 || vs[0].(int) != 1 || vs[1].(int) != 2 {
+		t.Error(vs)
+	}
+
+	errFailed := errors.New("failed")
+	slow := NewFuture()
+	failed := Go(func() (interface{}, error) { return nil, errFailed })
+	if _, err := All(slow, failed).Get(nil); err != errFailed {
+		t.Error(err)
+	}
+
+	if v, err := All().Get(nil); err != nil || len(v.([]interface{})) != 0 {
+		t.Error(v, err)
+	}
+}
+
+func TestFutureAny(t *testing.T) {
+	errFailed := errors.New("failed")
+	failed := Go(func() (interface{}, error) { return nil, errFailed })
+	ok := Go(func() (interface{}, error) { time.Sleep(time.Millisecond * 10); return 1, nil })
+	if v, err := Any(failed, ok, NewFuture()).Get(nil); err != nil || v.(int) != 1 {
+		t.Error(v, err)
+	}
+
+	if _, err := Any(failed, failed).Get(nil); err == nil {
+		t.Error("expect an error")
+	} else if errs := err.(*errors2.Multi).Errors; len(errs) != 2 || errs[0] != errFailed {
+		t.Error(errs)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/xgfone/go-tools/errors2"
+)
+
+// PanicError is the error converted from the panic in the goroutine.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n%s", e.Value, e.Stack)
+}
+
+// Group is a collection of the goroutines working on the subtasks of the same
+// task, which is similar to errgroup, but converts the panic to PanicError,
+// limits the concurrency, and collects all the errors.
+//
+// A zero Group is valid, which has no limit and does not cancel the context.
+type Group struct {
+	cancel func()
+	sem    chan struct{}
+
+	wg   sync.WaitGroup
+	lock sync.Mutex
+	errs []error
+	once sync.Once
+}
+
+// NewGroup returns a new Group with the concurrency limit, and the derived
+// context, which is canceled when a goroutine fails at first or Wait returns.
+//
+// If limit is equal to or less than 0, there is no limit.
+func NewGroup(ctx context.Context, limit int) (*Group, context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	g := &Group{cancel: cancel}
+	if limit > 0 {
+		g.sem = make(chan struct{}, limit)
+	}
+	return g, ctx
+}
+
+// Go calls f in a new goroutine, which blocks until there is a free slot
+// if the concurrency of the group is limited.
+//
+// If f returns an error or panics, the context of the group is canceled.
+func (g *Group) Go(f func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				buf := make([]byte, 4096)
+				g.fail(PanicError{Value: v, Stack: buf[:runtime.Stack(buf, false)]})
+			}
+
+			if g.sem != nil {
+				<-g.sem
+			}
+			g.wg.Done()
+		}()
+
+		if err := f(); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+func (g *Group) fail(err error) {
+	g.lock.Lock()
+	g.errs = append(g.errs, err)
+	g.lock.Unlock()
+
+	g.once.Do(func() {
+		if g.cancel != nil {
+			g.cancel()
+		}
+	})
+}
+
+// Wait waits until all the goroutines have finished, and returns the first
+// error. Or return nil if no error.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel()
+	}
+
+	if len(g.errs) > 0 {
+		return g.errs[0]
+	}
+	return nil
+}
+
+// Errors returns all the errors as *errors2.Multi, which should be called
+// after Wait.
+//
+// Return nil if no error.
+func (g *Group) Errors() error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if len(g.errs) == 0 {
+		return nil
+	}
+	return &errors2.Multi{Errors: append([]error(nil), g.errs...)}
+}
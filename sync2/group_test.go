@@ -0,0 +1,90 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-tools/errors2"
+)
+
+func TestGroup(t *testing.T) {
+	var g Group
+	var count int64
+	for i := 0; i < 10; i++ {
+		g.Go(func() error { atomic.AddInt64(&count, 1); return nil })
+	}
+	if err := g.Wait(); err != nil || count != 10 {
+		t.Error(count, err)
+	}
+}
+
+func TestGroupCancel(t *testing.T) {
+	errFailed := errors.New("failed")
+	g, ctx := NewGroup(context.Background(), 0)
+	g.Go(func() error { return errFailed })
+	g.Go(func() error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return errors.New("not canceled")
+		}
+	})
+
+	if err := g.Wait(); err != errFailed {
+		t.Error(err)
+	}
+	if errs := g.Errors().(*errors2.Multi).Errors; len(errs) != 2 || errs[1] != context.Canceled {
+		t.Error(errs)
+	}
+}
+
+func TestGroupPanic(t *testing.T) {
+	g, _ := NewGroup(nil, 0)
+	g.Go(func() error { panic("boom") })
+	if err, ok := g.Wait().(PanicError); !ok || err.Value != "boom" || len(err.Stack) == 0 {
+		t.Error(err)
+	}
+}
+
+func TestGroupLimit(t *testing.T) {
+	g, _ := NewGroup(context.Background(), 2)
+	var running, max int64
+	for i := 0; i < 10; i++ {
+		g.Go(func() error {
+			n := atomic.AddInt64(&running, 1)
+			for {
+				m := atomic.LoadInt64(&max)
+				if n <= m || atomic.CompareAndSwapInt64(&max, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond * 5)
+			atomic.AddInt64(&running, -1)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		t.Error(err)
+	} else if max > 2 {
+		t.Errorf("expect the concurrency 2 at most, got %d", max)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"context"
+	"sync"
+)
+
+type keyedLock struct {
+	mutex Mutex
+	refs  int
+}
+
+// KeyedMutex is a set of the mutexes by the key, so the operations
+// on the different keys don't block each other.
+//
+// The mutex of the key is created on demand, and removed automatically
+// when no one holds or waits for it.
+//
+// The key must be comparable. The zero value is ready to use.
+type KeyedMutex struct {
+	lock  sync.Mutex
+	locks map[interface{}]*keyedLock
+}
+
+func (m *KeyedMutex) get(key interface{}) *keyedLock {
+	m.lock.Lock()
+	if m.locks == nil {
+		m.locks = make(map[interface{}]*keyedLock)
+	}
+
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		m.locks[key] = l
+	}
+	l.refs++
+	m.lock.Unlock()
+	return l
+}
+
+func (m *KeyedMutex) put(key interface{}, l *keyedLock) {
+	m.lock.Lock()
+	if l.refs--; l.refs == 0 {
+		delete(m.locks, key)
+	}
+	m.lock.Unlock()
+}
+
+// Lock locks the mutex of the key.
+func (m *KeyedMutex) Lock(key interface{}) {
+	m.get(key).mutex.Lock()
+}
+
+// TryLock tries to lock the mutex of the key without blocking,
+// and reports whether it succeeds.
+func (m *KeyedMutex) TryLock(key interface{}) bool {
+	l := m.get(key)
+	if l.mutex.TryLock() {
+		return true
+	}
+	m.put(key, l)
+	return false
+}
+
+// LockContext locks the mutex of the key, which blocks until the mutex
+// is available or ctx is done. If ctx is done, it returns ctx.Err()
+// without locking.
+func (m *KeyedMutex) LockContext(ctx context.Context, key interface{}) error {
+	l := m.get(key)
+	if err := l.mutex.LockContext(ctx); err != nil {
+		m.put(key, l)
+		return err
+	}
+	return nil
+}
+
+// Unlock unlocks the mutex of the key, which panics if it is not locked.
+func (m *KeyedMutex) Unlock(key interface{}) {
+	m.lock.Lock()
+	l, ok := m.locks[key]
+	m.lock.Unlock()
+	if !ok {
+		panic("sync2: unlock of unlocked key")
+	}
+
+	l.mutex.Unlock()
+	m.put(key, l)
+}
+
+// Len returns the number of the keys being locked or waited for.
+func (m *KeyedMutex) Len() int {
+	m.lock.Lock()
+	n := len(m.locks)
+	m.lock.Unlock()
+	return n
+}
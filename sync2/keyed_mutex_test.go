@@ -0,0 +1,94 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	var m KeyedMutex
+	m.Lock("a")
+	if !m.TryLock("b") {
+		t.Error("failed to lock the key 'b'")
+	}
+	if m.TryLock("a") {
+		t.Error("unexpected to lock the locked key 'a'")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := m.LockContext(ctx, "a"); err != context.DeadlineExceeded {
+		t.Errorf("expect context.DeadlineExceeded, but got %v", err)
+	}
+
+	if n := m.Len(); n != 2 {
+		t.Errorf("expect 2 keys, but got %d", n)
+	}
+	m.Unlock("a")
+	m.Unlock("b")
+	if n := m.Len(); n != 0 {
+		t.Errorf("expect to clean up the unused keys, but got %d", n)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expect a panic when unlocking the unlocked key")
+		}
+	}()
+	m.Unlock("a")
+}
+
+func TestKeyedMutexConcurrency(t *testing.T) {
+	var m KeyedMutex
+	var wg sync.WaitGroup
+	counters := make(map[int]int, 4)
+	for i := 0; i < 4; i++ {
+		counters[i] = 0
+	}
+
+	var lock sync.Mutex
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(key int) {
+			defer wg.Done()
+			m.Lock(key)
+			defer m.Unlock(key)
+
+			lock.Lock()
+			v := counters[key]
+			lock.Unlock()
+
+			time.Sleep(time.Microsecond)
+
+			lock.Lock()
+			counters[key] = v + 1
+			lock.Unlock()
+		}(i % 4)
+	}
+	wg.Wait()
+
+	for key, n := range counters {
+		if n != 25 {
+			t.Errorf("key %d: expect 25, but got %d", key, n)
+		}
+	}
+	if n := m.Len(); n != 0 {
+		t.Errorf("expect to clean up the unused keys, but got %d", n)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// CountDownLatch is used to wait until the count reaches zero,
+// such as waiting for N signals.
+type CountDownLatch struct {
+	lock  sync.Mutex
+	count int
+	done  chan struct{}
+}
+
+// NewCountDownLatch returns a new CountDownLatch with the count.
+func NewCountDownLatch(count int) *CountDownLatch {
+	if count < 0 {
+		panic(fmt.Errorf("the count of the latch must not be negative: %d", count))
+	}
+
+	l := &CountDownLatch{count: count, done: make(chan struct{})}
+	if count == 0 {
+		close(l.done)
+	}
+	return l
+}
+
+// CountDown decrements the count, and releases all the waiters
+// when the count reaches zero. It does nothing if the count is zero.
+func (l *CountDownLatch) CountDown() {
+	l.lock.Lock()
+	if l.count > 0 {
+		if l.count--; l.count == 0 {
+			close(l.done)
+		}
+	}
+	l.lock.Unlock()
+}
+
+// Count returns the current count.
+func (l *CountDownLatch) Count() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.count
+}
+
+// Done returns a channel that's closed when the count reaches zero.
+func (l *CountDownLatch) Done() <-chan struct{} {
+	return l.done
+}
+
+// Wait blocks until the count reaches zero or ctx is done.
+// If ctx is done, it returns ctx.Err().
+func (l *CountDownLatch) Wait(ctx context.Context) error {
+	select {
+	case <-l.done:
+		return nil
+	default:
+	}
+
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Barrier is a cyclic barrier that the parties wait for each other,
+// and proceed together when all of them arrive. Then it is reset
+// for the next round automatically.
+type Barrier struct {
+	lock    sync.Mutex
+	parties int
+	count   int
+	round   chan struct{}
+}
+
+// NewBarrier returns a new Barrier for the parties.
+func NewBarrier(parties int) *Barrier {
+	if parties <= 0 {
+		panic(fmt.Errorf("the parties of the barrier must be positive: %d", parties))
+	}
+	return &Barrier{parties: parties, round: make(chan struct{})}
+}
+
+// Parties returns the number of the parties required to trip the barrier.
+func (b *Barrier) Parties() int {
+	return b.parties
+}
+
+// Waiting returns the number of the parties waiting at the barrier.
+func (b *Barrier) Waiting() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.count
+}
+
+// Wait blocks until all the parties arrive at the barrier or ctx is done.
+//
+// If ctx is done before the barrier is tripped, it returns ctx.Err()
+// and the arrival of the caller is withdrawn, so the others keep waiting.
+func (b *Barrier) Wait(ctx context.Context) error {
+	b.lock.Lock()
+	round := b.round
+	if b.count++; b.count == b.parties {
+		b.count = 0
+		b.round = make(chan struct{})
+		close(round)
+		b.lock.Unlock()
+		return nil
+	}
+	b.lock.Unlock()
+
+	select {
+	case <-round:
+		return nil
+	case <-ctx.Done():
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		select {
+		case <-round:
+			return nil // Tripped while being cancelled.
+		default:
+			b.count--
+			return ctx.Err()
+		}
+	}
+}
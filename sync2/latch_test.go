@@ -0,0 +1,82 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCountDownLatch(t *testing.T) {
+	l := NewCountDownLatch(3)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expect context.DeadlineExceeded, but got %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		go l.CountDown()
+	}
+	if err := l.Wait(context.Background()); err != nil {
+		t.Error(err)
+	}
+
+	l.CountDown()
+	if n := l.Count(); n != 0 {
+		t.Errorf("expect the count 0, but got %d", n)
+	}
+
+	select {
+	case <-NewCountDownLatch(0).Done():
+	default:
+		t.Error("expect the latch with the count 0 to be done")
+	}
+}
+
+func TestBarrier(t *testing.T) {
+	const parties = 3
+	b := NewBarrier(parties)
+
+	var arrived int32
+	var wg sync.WaitGroup
+	for round := 0; round < 2; round++ {
+		for i := 0; i < parties; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				atomic.AddInt32(&arrived, 1)
+				if err := b.Wait(context.Background()); err != nil {
+					t.Error(err)
+				} else if n := atomic.LoadInt32(&arrived); n%parties != 0 {
+					t.Errorf("proceed before all the parties arrive: %d", n)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := b.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expect context.DeadlineExceeded, but got %v", err)
+	}
+	if n := b.Waiting(); n != 0 {
+		t.Errorf("expect to withdraw the cancelled party, but got %d", n)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DebugLock enables the instrumentation of the hold duration of the locks
+// of Mutex and RWMutex, which reports the duration by OnLockHeld
+// after unlocking.
+//
+// It should be set before using the locks.
+var DebugLock bool
+
+// OnLockHeld is called with the duration that the lock was held
+// after unlocking in the debug mode, such as logging the slow holders.
+var OnLockHeld func(held time.Duration)
+
+// Mutex is a mutual exclusion lock, which supports TryLock and LockContext
+// besides Lock and Unlock.
+//
+// The zero value is an unlocked mutex.
+type Mutex struct {
+	once   sync.Once
+	ch     chan struct{}
+	locked time.Time
+}
+
+func (m *Mutex) init() {
+	m.once.Do(func() { m.ch = make(chan struct{}, 1) })
+}
+
+func (m *Mutex) acquired() {
+	if DebugLock {
+		m.locked = time.Now()
+	}
+}
+
+// Lock locks the mutex, which blocks until the mutex is available.
+func (m *Mutex) Lock() {
+	m.init()
+	m.ch <- struct{}{}
+	m.acquired()
+}
+
+// TryLock tries to lock the mutex without blocking, and reports
+// whether it succeeds.
+func (m *Mutex) TryLock() bool {
+	m.init()
+	select {
+	case m.ch <- struct{}{}:
+		m.acquired()
+		return true
+	default:
+		return false
+	}
+}
+
+// LockContext locks the mutex, which blocks until the mutex is available
+// or ctx is done. If ctx is done, it returns ctx.Err() without locking.
+func (m *Mutex) LockContext(ctx context.Context) error {
+	m.init()
+	select {
+	case m.ch <- struct{}{}:
+		m.acquired()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Unlock unlocks the mutex, which panics if it is not locked.
+func (m *Mutex) Unlock() {
+	m.init()
+	if DebugLock && OnLockHeld != nil {
+		held := time.Since(m.locked)
+		defer OnLockHeld(held)
+	}
+
+	select {
+	case <-m.ch:
+	default:
+		panic("sync2: unlock of unlocked mutex")
+	}
+}
+
+// maxReaders is the maximum number of the concurrent readers of RWMutex.
+const maxReaders = 1 << 30
+
+// RWMutex is a reader/writer mutual exclusion lock, which supports
+// TryLock and LockContext besides Lock and Unlock, and the same for
+// the reader lock.
+//
+// The lock is acquired in the FIFO order, so the writer waiting for
+// the lock blocks the new readers and will not be starved.
+//
+// The zero value is an unlocked mutex.
+type RWMutex struct {
+	once   sync.Once
+	sem    *WeightedSemaphore
+	locked time.Time
+}
+
+func (m *RWMutex) init() {
+	m.once.Do(func() { m.sem = NewWeightedSemaphore(maxReaders) })
+}
+
+func (m *RWMutex) acquired() {
+	if DebugLock {
+		m.locked = time.Now()
+	}
+}
+
+// Lock locks the mutex for writing, which blocks until the mutex is available.
+func (m *RWMutex) Lock() {
+	m.init()
+	m.sem.Acquire(context.Background(), maxReaders)
+	m.acquired()
+}
+
+// TryLock tries to lock the mutex for writing without blocking,
+// and reports whether it succeeds.
+func (m *RWMutex) TryLock() bool {
+	m.init()
+	if m.sem.TryAcquire(maxReaders) {
+		m.acquired()
+		return true
+	}
+	return false
+}
+
+// LockContext locks the mutex for writing, which blocks until the mutex
+// is available or ctx is done. If ctx is done, it returns ctx.Err()
+// without locking.
+func (m *RWMutex) LockContext(ctx context.Context) error {
+	m.init()
+	if err := m.sem.Acquire(ctx, maxReaders); err != nil {
+		return err
+	}
+	m.acquired()
+	return nil
+}
+
+// Unlock unlocks the mutex for writing, which panics if it is not locked.
+func (m *RWMutex) Unlock() {
+	m.init()
+	if DebugLock && OnLockHeld != nil {
+		held := time.Since(m.locked)
+		defer OnLockHeld(held)
+	}
+	m.sem.Release(maxReaders)
+}
+
+// RLock locks the mutex for reading.
+func (m *RWMutex) RLock() {
+	m.init()
+	m.sem.Acquire(context.Background(), 1)
+}
+
+// TryRLock tries to lock the mutex for reading without blocking,
+// and reports whether it succeeds.
+func (m *RWMutex) TryRLock() bool {
+	m.init()
+	return m.sem.TryAcquire(1)
+}
+
+// RLockContext locks the mutex for reading, which blocks until the mutex
+// is available or ctx is done. If ctx is done, it returns ctx.Err()
+// without locking.
+func (m *RWMutex) RLockContext(ctx context.Context) error {
+	m.init()
+	return m.sem.Acquire(ctx, 1)
+}
+
+// RUnlock unlocks the mutex for reading, which panics if it is not locked.
+func (m *RWMutex) RUnlock() {
+	m.init()
+	m.sem.Release(1)
+}
+
+// RLocker returns a sync.Locker interface that implements the Lock and
+// Unlock methods by calling m.RLock and m.RUnlock.
+func (m *RWMutex) RLocker() sync.Locker {
+	return rlocker{m}
+}
+
+type rlocker struct{ m *RWMutex }
+
+func (r rlocker) Lock()   { r.m.RLock() }
+func (r rlocker) Unlock() { r.m.RUnlock() }
@@ -0,0 +1,116 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMutex(t *testing.T) {
+	var m Mutex
+	if !m.TryLock() {
+		t.Fatal("failed to lock the unlocked mutex")
+	} else if m.TryLock() {
+		t.Error("unexpected to lock the locked mutex")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := m.LockContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expect context.DeadlineExceeded, but got %v", err)
+	}
+
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		m.Unlock()
+	}()
+	if err := m.LockContext(context.Background()); err != nil {
+		t.Error(err)
+	}
+	m.Unlock()
+
+	m.Lock()
+	m.Unlock()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expect a panic when unlocking the unlocked mutex")
+		}
+	}()
+	m.Unlock()
+}
+
+func TestRWMutex(t *testing.T) {
+	var m RWMutex
+	if !m.TryRLock() || !m.TryRLock() {
+		t.Fatal("failed to lock the mutex for reading")
+	}
+	if m.TryLock() {
+		t.Error("unexpected to lock the mutex for writing with the readers")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := m.LockContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expect context.DeadlineExceeded, but got %v", err)
+	}
+
+	m.RUnlock()
+	m.RUnlock()
+	if err := m.LockContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if m.TryRLock() {
+		t.Error("unexpected to lock the mutex for reading with the writer")
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := m.RLockContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expect context.DeadlineExceeded, but got %v", err)
+	}
+	m.Unlock()
+
+	l := m.RLocker()
+	l.Lock()
+	m.RLock()
+	m.RUnlock()
+	l.Unlock()
+
+	m.Lock()
+	m.Unlock()
+}
+
+func TestMutexDebug(t *testing.T) {
+	held := make(chan time.Duration, 2)
+	DebugLock, OnLockHeld = true, func(d time.Duration) { held <- d }
+	defer func() { DebugLock, OnLockHeld = false, nil }()
+
+	var m Mutex
+	var rw RWMutex
+	m.Lock()
+	rw.Lock()
+	time.Sleep(time.Millisecond * 10)
+	m.Unlock()
+	rw.Unlock()
+
+	for i := 0; i < 2; i++ {
+		if d := <-held; d < time.Millisecond*10 {
+			t.Errorf("unexpected hold duration: %s", d)
+		}
+	}
+}
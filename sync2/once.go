@@ -0,0 +1,70 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// OnceError is the same as sync.Once, but memoizes the error returned
+// by the function, and returns it for each call of Do.
+type OnceError struct {
+	once sync.Once
+	err  error
+}
+
+// Do calls f only once, and returns the error returned by f.
+func (o *OnceError) Do(f func() error) error {
+	o.once.Do(func() { o.err = f() })
+	return o.err
+}
+
+// ResettableOnce is a Once that can be re-armed, and is not done
+// if the function fails, so that it will retry in the next call of Do,
+// which is used to initialize the resources lazily, such as the connections.
+type ResettableOnce struct {
+	lock sync.Mutex
+	done uint32
+}
+
+// Do calls f if the once is not done, and the once is done only if f returns
+// nil. If the once has been done, it returns nil and does not call f.
+func (o *ResettableOnce) Do(f func() error) (err error) {
+	if atomic.LoadUint32(&o.done) == 1 {
+		return nil
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.done == 0 {
+		if err = f(); err == nil {
+			atomic.StoreUint32(&o.done, 1)
+		}
+	}
+	return
+}
+
+// Done reports whether the once has been done.
+func (o *ResettableOnce) Done() bool {
+	return atomic.LoadUint32(&o.done) == 1
+}
+
+// Reset re-arms the once so that the next call of Do will call f again.
+func (o *ResettableOnce) Reset() {
+	o.lock.Lock()
+	atomic.StoreUint32(&o.done, 0)
+	o.lock.Unlock()
+}
@@ -0,0 +1,55 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOnceError(t *testing.T) {
+	var once OnceError
+	var count int
+	errFailed := errors.New("failed")
+	for i := 0; i < 3; i++ {
+		if err := once.Do(func() error { count++; return errFailed }); err != errFailed {
+			t.Error(err)
+		}
+	}
+	if count != 1 {
+		t.Errorf("expect to call once, got %d", count)
+	}
+}
+
+func TestResettableOnce(t *testing.T) {
+	var once ResettableOnce
+	var count int
+	errFailed := errors.New("failed")
+
+	if err := once.Do(func() error { count++; return errFailed }); err != errFailed || once.Done() {
+		t.Error(err)
+	}
+	if err := once.Do(func() error { count++; return nil }); err != nil || !once.Done() {
+		t.Error(err)
+	}
+	if err := once.Do(func() error { count++; return nil }); err != nil || count != 2 {
+		t.Error(count, err)
+	}
+
+	once.Reset()
+	if err := once.Do(func() error { count++; return nil }); err != nil || count != 3 {
+		t.Error(count, err)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-tools/errors2"
+)
+
+// WaitGroupTimeout is a WaitGroup which supports waiting with the timeout.
+type WaitGroupTimeout struct {
+	wg sync.WaitGroup
+}
+
+// Add is the same as sync.WaitGroup.Add.
+func (g *WaitGroupTimeout) Add(delta int) { g.wg.Add(delta) }
+
+// Done is the same as sync.WaitGroup.Done.
+func (g *WaitGroupTimeout) Done() { g.wg.Done() }
+
+// Go calls f in a new goroutine tracked by the group.
+func (g *WaitGroupTimeout) Go(f func()) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		f()
+	}()
+}
+
+// Wait waits until the group finishes or the timeout d, and reports
+// whether the group has finished. If d is equal to or less than 0,
+// wait until the group finishes.
+//
+// Notice: when timing out, an internal goroutine keeps waiting
+// until the group finishes.
+func (g *WaitGroupTimeout) Wait(d time.Duration) bool {
+	if d <= 0 {
+		g.wg.Wait()
+		return true
+	}
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// ErrorWaitGroup is a WaitGroupTimeout collecting the errors of the tasks.
+type ErrorWaitGroup struct {
+	group WaitGroupTimeout
+	lock  sync.Mutex
+	errs  []error
+}
+
+// Go calls f in a new goroutine tracked by the group, and collects
+// the error returned by f.
+func (g *ErrorWaitGroup) Go(f func() error) {
+	g.group.Go(func() {
+		if err := f(); err != nil {
+			g.lock.Lock()
+			g.errs = append(g.errs, err)
+			g.lock.Unlock()
+		}
+	})
+}
+
+// Wait is the same as WaitGroupTimeout.Wait, but also returns the errors
+// collected until now. errs is nil if no error.
+func (g *ErrorWaitGroup) Wait(d time.Duration) (errs *errors2.Multi, ok bool) {
+	ok = g.group.Wait(d)
+	g.lock.Lock()
+	if len(g.errs) > 0 {
+		errs = &errors2.Multi{Errors: append([]error(nil), g.errs...)}
+	}
+	g.lock.Unlock()
+	return
+}
@@ -0,0 +1,50 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitGroupTimeout(t *testing.T) {
+	var g WaitGroupTimeout
+	if !g.Wait(time.Millisecond) {
+		t.Error("expect the empty group to finish")
+	}
+
+	block := make(chan struct{})
+	g.Go(func() { <-block })
+	if g.Wait(time.Millisecond * 10) {
+		t.Error("expect the timeout")
+	}
+
+	close(block)
+	if !g.Wait(time.Second) {
+		t.Error("expect the group to finish")
+	}
+}
+
+func TestErrorWaitGroup(t *testing.T) {
+	var g ErrorWaitGroup
+	g.Go(func() error { return nil })
+	g.Go(func() error { return errors.New("failed") })
+	g.Go(func() error { return errors.New("failed") })
+
+	if errs, ok := g.Wait(0); !ok || errs.Len() != 2 || errs.Errors[0].Error() != "failed" {
+		t.Error(errs, ok)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+type semaphoreWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// WeightedSemaphore is a semaphore that the weight of each acquisition
+// may be different, such as the number of the bytes or connections.
+//
+// The waiters are served in the FIFO order, so a large acquisition
+// will not be starved by the small ones.
+type WeightedSemaphore struct {
+	size    int64
+	cur     int64
+	lock    sync.Mutex
+	waiters list.List
+}
+
+// NewWeightedSemaphore returns a new WeightedSemaphore with the maximum
+// combined weight size.
+func NewWeightedSemaphore(size int64) *WeightedSemaphore {
+	if size <= 0 {
+		panic(fmt.Errorf("the size of the semaphore must be positive: %d", size))
+	}
+	return &WeightedSemaphore{size: size}
+}
+
+// Acquire acquires the semaphore with the weight n, which blocks until
+// the weight is available or ctx is done. If ctx is done, it returns
+// ctx.Err() and leaves the semaphore unchanged.
+//
+// If n is larger than the size of the semaphore, it waits until ctx is done.
+func (s *WeightedSemaphore) Acquire(ctx context.Context, n int64) error {
+	s.lock.Lock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.lock.Unlock()
+		return nil
+	}
+
+	if n > s.size {
+		s.lock.Unlock()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ready := make(chan struct{})
+	elem := s.waiters.PushBack(semaphoreWaiter{n: n, ready: ready})
+	s.lock.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		err := ctx.Err()
+		s.lock.Lock()
+		select {
+		case <-ready:
+			// Acquired after being canceled, so ignore the cancellation.
+			err = nil
+		default:
+			isFront := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			if isFront && s.size > s.cur {
+				s.notifyWaiters()
+			}
+		}
+		s.lock.Unlock()
+		return err
+	}
+}
+
+// TryAcquire acquires the semaphore with the weight n without blocking,
+// and reports whether it succeeds.
+func (s *WeightedSemaphore) TryAcquire(n int64) bool {
+	s.lock.Lock()
+	ok := s.size-s.cur >= n && s.waiters.Len() == 0
+	if ok {
+		s.cur += n
+	}
+	s.lock.Unlock()
+	return ok
+}
+
+// Release releases the semaphore with the weight n.
+//
+// It panics if releasing more than held.
+func (s *WeightedSemaphore) Release(n int64) {
+	s.lock.Lock()
+	s.cur -= n
+	if s.cur < 0 {
+		s.lock.Unlock()
+		panic("sync2: released more than held")
+	}
+	s.notifyWaiters()
+	s.lock.Unlock()
+}
+
+// Available returns the available weight of the semaphore.
+func (s *WeightedSemaphore) Available() int64 {
+	s.lock.Lock()
+	n := s.size - s.cur
+	s.lock.Unlock()
+	return n
+}
+
+func (s *WeightedSemaphore) notifyWaiters() {
+	for {
+		next := s.waiters.Front()
+		if next == nil {
+			break
+		}
+
+		w := next.Value.(semaphoreWaiter)
+		if s.size-s.cur < w.n {
+			// Keep the FIFO order, so don't wake up the next waiters.
+			break
+		}
+
+		s.cur += w.n
+		s.waiters.Remove(next)
+		close(w.ready)
+	}
+}
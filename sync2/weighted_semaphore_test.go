@@ -0,0 +1,68 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync2
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWeightedSemaphore(t *testing.T) {
+	s := NewWeightedSemaphore(10)
+	ctx := context.Background()
+
+	if err := s.Acquire(ctx, 6); err != nil {
+		t.Fatal(err)
+	}
+	if s.TryAcquire(5) || s.Available() != 4 {
+		t.Fail()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		if err := s.Acquire(ctx, 8); err != nil {
+			t.Error(err)
+		}
+		close(done)
+	}()
+
+	// The later small acquisition must not jump over the waiter.
+	time.Sleep(time.Millisecond * 10)
+	if s.TryAcquire(1) {
+		t.Error("expect to keep the FIFO order")
+	}
+
+	s.Release(6)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the waiter is not woken up")
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, time.Millisecond*10)
+	defer cancel()
+	if err := s.Acquire(timeout, 5); err != context.DeadlineExceeded {
+		t.Error(err)
+	}
+	if err := s.Acquire(timeout, 11); err != context.DeadlineExceeded {
+		t.Error(err)
+	}
+
+	s.Release(8)
+	if s.Available() != 10 {
+		t.Error(s.Available())
+	}
+}
@@ -363,6 +363,34 @@ func ToLocalTime(v interface{}, layout ...string) (time.Time, error) {
 	return toTime(toLocalTimeParser, v, layout...)
 }
 
+// ToDuration does the best to convert any certain value to time.Duration.
+//
+// For the string, it is parsed by time.ParseDuration, such as "1m30s",
+// but the integer string is considered as the number of the nanoseconds
+// like other integers.
+func ToDuration(v interface{}) (time.Duration, error) {
+	switch _v := v.(type) {
+	case time.Duration:
+		return _v, nil
+	case []byte:
+		return parseDuration(string(_v))
+	case string:
+		return parseDuration(_v)
+	}
+
+	i, err := ToInt64(v)
+	return time.Duration(i), err
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	} else if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(i), nil
+	}
+	return time.ParseDuration(s)
+}
+
 // ToBool does the best to convert any certain value to bool.
 //
 // For the string, the true value is
@@ -712,6 +740,15 @@ func MustToLocalTime(v interface{}, layout ...string) time.Time {
 	return _v
 }
 
+// MustToDuration is equal to ToDuration, but panic if there is an error.
+func MustToDuration(v interface{}) time.Duration {
+	_v, err := ToDuration(v)
+	if err != nil {
+		panic(err)
+	}
+	return _v
+}
+
 // MustToBool is equal to ToBool, but panic if there is an error.
 func MustToBool(v interface{}) bool {
 	_v, err := ToBool(v)
@@ -33,3 +33,28 @@ func TestConverter(t *testing.T) {
 		t.Error(v)
 	}
 }
+
+func TestToDuration(t *testing.T) {
+	for _, c := range []struct {
+		in  interface{}
+		out time.Duration
+	}{
+		{nil, 0},
+		{"", 0},
+		{"1m30s", 90 * time.Second},
+		{[]byte("2h"), 2 * time.Hour},
+		{"1000", 1000},
+		{int64(10), 10},
+		{time.Second, time.Second},
+	} {
+		if v, err := ToDuration(c.in); err != nil {
+			t.Errorf("%v: %s", c.in, err)
+		} else if v != c.out {
+			t.Errorf("%v: expect '%s', got '%s'", c.in, c.out, v)
+		}
+	}
+
+	if _, err := ToDuration("abc"); err == nil {
+		t.Error("expect an error")
+	}
+}
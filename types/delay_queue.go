@@ -0,0 +1,140 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type delayItem struct {
+	value interface{}
+	ready time.Time
+	seq   uint64
+}
+
+// DelayQueue is a thread-safe queue that the items are pushed with
+// the ready time, and the item can be popped only when it's due.
+//
+// The items with the same ready time are popped in the pushed order.
+type DelayQueue struct {
+	lock  sync.Mutex
+	queue *PriorityQueue
+	seq   uint64
+	wake  chan struct{} // Closed and renewed when pushing an item.
+}
+
+// NewDelayQueue returns a new DelayQueue.
+func NewDelayQueue() *DelayQueue {
+	return &DelayQueue{
+		wake: make(chan struct{}),
+		queue: NewPriorityQueue(func(first, second interface{}) bool {
+			a, b := first.(delayItem), second.(delayItem)
+			if a.ready.Equal(b.ready) {
+				return a.seq < b.seq
+			}
+			return a.ready.Before(b.ready)
+		}),
+	}
+}
+
+// Len returns the number of the items in the queue, including undue ones.
+func (q *DelayQueue) Len() int {
+	q.lock.Lock()
+	n := q.queue.Len()
+	q.lock.Unlock()
+	return n
+}
+
+// Push pushes the item which is due at the ready time.
+func (q *DelayQueue) Push(item interface{}, ready time.Time) {
+	q.lock.Lock()
+	q.seq++
+	q.queue.Push(delayItem{value: item, ready: ready, seq: q.seq})
+
+	// Wake up all the waiters to recheck the earliest item.
+	close(q.wake)
+	q.wake = make(chan struct{})
+	q.lock.Unlock()
+}
+
+// PushAfter is equal to q.Push(item, time.Now().Add(delay)).
+func (q *DelayQueue) PushAfter(item interface{}, delay time.Duration) {
+	q.Push(item, time.Now().Add(delay))
+}
+
+// TryPop pops the earliest item and returns true if it's due.
+// Or return false.
+func (q *DelayQueue) TryPop() (interface{}, bool) {
+	item, _, _, ok := q.tryPop(time.Now())
+	return item, ok
+}
+
+func (q *DelayQueue) tryPop(now time.Time) (item interface{},
+	wait time.Duration, wake <-chan struct{}, ok bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	v, ok := q.queue.Peek()
+	if !ok {
+		return nil, -1, q.wake, false
+	}
+
+	if di := v.(delayItem); di.ready.After(now) {
+		return nil, di.ready.Sub(now), q.wake, false
+	}
+
+	q.queue.Pop()
+	return v.(delayItem).value, 0, nil, true
+}
+
+// Pop blocks until the earliest item is due or ctx is done, and pops it.
+// If ctx is done, it returns ctx.Err().
+func (q *DelayQueue) Pop(ctx context.Context) (interface{}, error) {
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		item, wait, wake, ok := q.tryPop(time.Now())
+		if ok {
+			return item, nil
+		}
+
+		var timeout <-chan time.Time
+		if wait >= 0 {
+			if timer == nil {
+				timer = time.NewTimer(wait)
+			} else {
+				timer.Reset(wait)
+			}
+			timeout = timer.C
+		}
+
+		select {
+		case <-timeout:
+		case <-wake:
+			if timeout != nil && !timer.Stop() {
+				<-timer.C
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import "container/heap"
+
+// PriorityQueue is a priority queue based on the heap, which pops
+// the smallest item by the less function at first.
+//
+// It is not thread-safe.
+type PriorityQueue struct {
+	items []interface{}
+	less  func(first, second interface{}) bool
+}
+
+// NewPriorityQueue returns a new PriorityQueue.
+//
+// For the max-priority queue, you can reverse the less function.
+func NewPriorityQueue(less func(first, second interface{}) bool) *PriorityQueue {
+	return &PriorityQueue{less: less}
+}
+
+// Len returns the number of the items in the queue.
+func (q *PriorityQueue) Len() int {
+	return len(q.items)
+}
+
+// Push pushes the item into the queue.
+func (q *PriorityQueue) Push(item interface{}) {
+	heap.Push((*priorityHeap)(q), item)
+}
+
+// Pop pops the smallest item and returns true. Or return false if empty.
+func (q *PriorityQueue) Pop() (interface{}, bool) {
+	if len(q.items) == 0 {
+		return nil, false
+	}
+	return heap.Pop((*priorityHeap)(q)), true
+}
+
+// Peek returns the smallest item without popping it.
+// Or return false if empty.
+func (q *PriorityQueue) Peek() (interface{}, bool) {
+	if len(q.items) == 0 {
+		return nil, false
+	}
+	return q.items[0], true
+}
+
+type priorityHeap PriorityQueue
+
+func (h *priorityHeap) Len() int           { return len(h.items) }
+func (h *priorityHeap) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+func (h *priorityHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *priorityHeap) Push(x interface{}) { h.items = append(h.items, x) }
+func (h *priorityHeap) Pop() interface{} {
+	n := len(h.items) - 1
+	x := h.items[n]
+	h.items[n] = nil
+	h.items = h.items[:n]
+	return x
+}
@@ -0,0 +1,81 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	q := NewPriorityQueue(func(v1, v2 interface{}) bool { return v1.(int) < v2.(int) })
+	for _, v := range []int{5, 1, 4, 2, 3} {
+		q.Push(v)
+	}
+
+	if v, ok := q.Peek(); !ok || v.(int) != 1 || q.Len() != 5 {
+		t.Error(v, ok)
+	}
+	for i := 1; i <= 5; i++ {
+		if v, ok := q.Pop(); !ok || v.(int) != i {
+			t.Errorf("expect %d, got %v", i, v)
+		}
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("expect the empty queue")
+	}
+	if _, ok := q.Peek(); ok {
+		t.Error("expect the empty queue")
+	}
+}
+
+func TestDelayQueue(t *testing.T) {
+	q := NewDelayQueue()
+	now := time.Now()
+	q.Push("b", now.Add(time.Millisecond*40))
+	q.Push("a", now.Add(time.Millisecond*20))
+	q.Push("c", now.Add(time.Millisecond*40))
+
+	if _, ok := q.TryPop(); ok || q.Len() != 3 {
+		t.Error("expect no due item")
+	}
+
+	ctx := context.Background()
+	for _, expect := range []string{"a", "b", "c"} {
+		if v, err := q.Pop(ctx); err != nil || v.(string) != expect {
+			t.Errorf("expect '%s', got '%v': %v", expect, v, err)
+		}
+	}
+	if cost := time.Since(now); cost < time.Millisecond*40 {
+		t.Errorf("popped too early: %s", cost)
+	}
+
+	// Wake up the waiter when pushing an earlier item.
+	q.PushAfter("late", time.Hour)
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		q.PushAfter("early", 0)
+	}()
+	if v, err := q.Pop(ctx); err != nil || v.(string) != "early" {
+		t.Error(v, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond*10)
+	defer cancel()
+	if _, err := q.Pop(ctx); err != context.DeadlineExceeded {
+		t.Error(err)
+	}
+}
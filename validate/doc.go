@@ -0,0 +1,47 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package validate validates the fields of the struct by the rules
+// in the tag "validate", and returns all the violations with the field paths.
+//
+// The rules are separated by the comma, and the parameter of the rule
+// is separated by the equal sign, such as
+//
+//	type ServerConfig struct {
+//		Network string `validate:"required,oneof=tcp udp"`
+//		Port    int    `validate:"min=1,max=65535"`
+//		Hosts   []string `validate:"omitempty,min=1"`
+//	}
+//
+//	if err := validate.Struct(conf); err != nil {
+//		for _, e := range err.(*errors2.Multi).Errors {
+//			fe := e.(*validate.FieldError)
+//			fmt.Println(fe.Field, fe.Rule, fe.Err)
+//		}
+//	}
+//
+// The builtin rules are
+//
+//	required   The value must not be the zero value.
+//	omitempty  Skip the other rules if the value is the zero value.
+//	min=N      The number must be >= N, or the length must be >= N.
+//	max=N      The number must be <= N, or the length must be <= N.
+//	len=N      The length must be equal to N.
+//	oneof=A B  The value must be one of the space-separated values.
+//	ip         The string must be an IP address.
+//	hostport   The string must be in the form of "host:port".
+//
+// For time.Duration, the parameters of min and max may be the duration
+// string, such as "1s". And you can register the customized rule by Register.
+package validate
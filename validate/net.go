@@ -0,0 +1,57 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+)
+
+func toString(v reflect.Value) (string, error) {
+	if v = indirect(v); v.Kind() != reflect.String {
+		if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			return "", nil // nil
+		}
+		return "", fmt.Errorf("unsupported type '%s'", v.Type())
+	}
+	return v.String(), nil
+}
+
+func ip(v reflect.Value, param string) error {
+	s, err := toString(v)
+	if err != nil || s == "" {
+		return err
+	} else if net.ParseIP(s) == nil {
+		return fmt.Errorf("'%s' is not a valid ip", s)
+	}
+	return nil
+}
+
+func hostport(v reflect.Value, param string) error {
+	s, err := toString(v)
+	if err != nil || s == "" {
+		return err
+	}
+
+	_, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a valid host:port", s)
+	} else if p, err := strconv.ParseUint(port, 10, 16); err != nil || p == 0 {
+		return fmt.Errorf("'%s' has an invalid port", s)
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-tools/errors2"
+)
+
+// ErrSkip is returned by the rule to skip the rest rules of the field.
+var ErrSkip = errors.New("skip the rest rules")
+
+// Rule is the validation rule of the field, which returns an error
+// if the field value violates the rule.
+//
+// param is the parameter of the rule, such as "1" for "min=1".
+type Rule func(v reflect.Value, param string) error
+
+// FieldError represents a violation of a field.
+type FieldError struct {
+	Field string // The path of the field, such as "Server.Hosts[0]".
+	Rule  string // The name of the rule, such as "min".
+	Param string // The parameter of the rule, such as "1".
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	if e.Param == "" {
+		return fmt.Sprintf("field '%s' violates '%s': %s", e.Field, e.Rule, e.Err)
+	}
+	return fmt.Sprintf("field '%s' violates '%s=%s': %s", e.Field, e.Rule, e.Param, e.Err)
+}
+
+// Validator is used to validate the struct by the tag.
+type Validator struct {
+	tag   string
+	lock  sync.RWMutex
+	rules map[string]Rule
+}
+
+// NewValidator returns a new Validator with the builtin rules.
+//
+// If tag is empty, it is "validate" by default.
+func NewValidator(tag string) *Validator {
+	if tag == "" {
+		tag = "validate"
+	}
+
+	v := &Validator{tag: tag, rules: make(map[string]Rule, len(builtinRules))}
+	for name, rule := range builtinRules {
+		v.rules[name] = rule
+	}
+	return v
+}
+
+// DefaultValidator is the default global Validator.
+var DefaultValidator = NewValidator("")
+
+// Register is equal to DefaultValidator.Register(name, rule).
+func Register(name string, rule Rule) { DefaultValidator.Register(name, rule) }
+
+// Struct is equal to DefaultValidator.Struct(s).
+func Struct(s interface{}) error { return DefaultValidator.Struct(s) }
+
+// Register registers the rule named name, which will override it if exists.
+func (v *Validator) Register(name string, rule Rule) {
+	if name == "" || rule == nil {
+		panic("validate: the rule name or function must not be empty")
+	}
+
+	v.lock.Lock()
+	v.rules[name] = rule
+	v.lock.Unlock()
+}
+
+func (v *Validator) getRule(name string) Rule {
+	v.lock.RLock()
+	rule := v.rules[name]
+	v.lock.RUnlock()
+	return rule
+}
+
+// Struct validates the struct or the pointer to struct, the nested structs,
+// and the structs in the slices and the maps recursively.
+//
+// It returns nil if no violations, or *errors2.Multi containing all
+// the violations as *FieldError.
+// It panics if s is not a struct or a pointer to struct, or the rule is
+// not registered.
+func (v *Validator) Struct(s interface{}) error {
+	value := reflect.ValueOf(s)
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		panic(fmt.Errorf("validate: the type '%T' is not a struct", s))
+	}
+
+	var errs []error
+	v.validateStruct(&errs, value, "")
+	if len(errs) == 0 {
+		return nil
+	}
+	return &errors2.Multi{Errors: errs}
+}
+
+func (v *Validator) validateStruct(errs *[]error, value reflect.Value, prefix string) {
+	t := value.Type()
+	for i, n := 0, t.NumField(); i < n; i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" { // Unexported
+			continue
+		}
+
+		tag := field.Tag.Get(v.tag)
+		if tag == "-" {
+			continue
+		}
+
+		path := prefix + field.Name
+		fv := value.Field(i)
+		if v.validateField(errs, fv, tag, path) {
+			v.validateNested(errs, fv, path)
+		}
+	}
+}
+
+func (v *Validator) validateNested(errs *[]error, value reflect.Value, path string) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if value.Type() != timeType {
+			v.validateStruct(errs, value, path+".")
+		}
+	case reflect.Slice, reflect.Array:
+		for i, n := 0, value.Len(); i < n; i++ {
+			v.validateNested(errs, value.Index(i), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			v.validateNested(errs, value.MapIndex(key), fmt.Sprintf("%s[%v]", path, key))
+		}
+	}
+}
+
+// validateField returns false if the nested fields should not be validated.
+func (v *Validator) validateField(errs *[]error, value reflect.Value, tag, path string) bool {
+	if tag == "" {
+		return true
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		var param string
+		if rule = strings.TrimSpace(rule); rule == "" {
+			continue
+		} else if index := strings.IndexByte(rule, '='); index > -1 {
+			rule, param = rule[:index], rule[index+1:]
+		}
+
+		f := v.getRule(rule)
+		if f == nil {
+			panic(fmt.Errorf("validate: no rule named '%s' for the field '%s'", rule, path))
+		}
+
+		if err := f(value, param); err == ErrSkip {
+			return false
+		} else if err != nil {
+			*errs = append(*errs, &FieldError{Field: path, Rule: rule, Param: param, Err: err})
+			if rule == "required" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var builtinRules = map[string]Rule{
+	"required":  required,
+	"omitempty": omitempty,
+	"min":       min,
+	"max":       max,
+	"len":       length,
+	"oneof":     oneof,
+	"ip":        ip,
+	"hostport":  hostport,
+}
+
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	case reflect.Invalid:
+		return true
+	default:
+		return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+	}
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func required(v reflect.Value, param string) error {
+	if isZero(v) {
+		return errors.New("the value is required")
+	}
+	return nil
+}
+
+func omitempty(v reflect.Value, param string) error {
+	if isZero(v) {
+		return ErrSkip
+	}
+	return nil
+}
+
+// compare returns -1, 0 or 1 if the number or length of v is less than,
+// equal to or greater than param.
+func compare(v reflect.Value, param string) (int, error) {
+	v = indirect(v)
+	switch v.Kind() {
+	case reflect.String:
+		return compareInt(int64(len([]rune(v.String()))), param)
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return compareInt(int64(v.Len()), param)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			if d, err := time.ParseDuration(param); err == nil {
+				return compareInt(v.Int(), strconv.FormatInt(int64(d), 10))
+			}
+		}
+		return compareInt(v.Int(), param)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		p, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid parameter '%s'", param)
+		}
+		switch u := v.Uint(); {
+		case u < p:
+			return -1, nil
+		case u > p:
+			return 1, nil
+		}
+		return 0, nil
+	case reflect.Float32, reflect.Float64:
+		p, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid parameter '%s'", param)
+		}
+		switch f := v.Float(); {
+		case f < p:
+			return -1, nil
+		case f > p:
+			return 1, nil
+		}
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("unsupported type '%s'", v.Type())
+	}
+}
+
+func compareInt(i int64, param string) (int, error) {
+	p, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid parameter '%s'", param)
+	}
+
+	switch {
+	case i < p:
+		return -1, nil
+	case i > p:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func isNumber(v reflect.Value) bool {
+	switch indirect(v).Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return false
+	}
+	return true
+}
+
+func min(v reflect.Value, param string) error {
+	if r, err := compare(v, param); err != nil {
+		return err
+	} else if r < 0 {
+		if isNumber(v) {
+			return fmt.Errorf("the value must be at least %s", param)
+		}
+		return fmt.Errorf("the length must be at least %s", param)
+	}
+	return nil
+}
+
+func max(v reflect.Value, param string) error {
+	if r, err := compare(v, param); err != nil {
+		return err
+	} else if r > 0 {
+		if isNumber(v) {
+			return fmt.Errorf("the value must be at most %s", param)
+		}
+		return fmt.Errorf("the length must be at most %s", param)
+	}
+	return nil
+}
+
+func length(v reflect.Value, param string) error {
+	if isNumber(v) {
+		return fmt.Errorf("unsupported type '%s'", v.Type())
+	} else if r, err := compare(v, param); err != nil {
+		return err
+	} else if r != 0 {
+		return fmt.Errorf("the length must be %s", param)
+	}
+	return nil
+}
+
+func oneof(v reflect.Value, param string) error {
+	v = indirect(v)
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface || v.Kind() == reflect.Invalid {
+		return nil // nil
+	}
+
+	s := fmt.Sprint(v.Interface())
+	for _, p := range strings.Fields(param) {
+		if s == p {
+			return nil
+		}
+	}
+	return fmt.Errorf("the value '%s' is not one of [%s]", s, param)
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-tools/errors2"
+)
+
+type listener struct {
+	Network string `validate:"required,oneof=tcp udp"`
+	Addr    string `validate:"hostport"`
+}
+
+type server struct {
+	Name      string        `validate:"required,len=3"`
+	Port      int           `validate:"min=1,max=65535"`
+	Timeout   time.Duration `validate:"min=1s,max=1m"`
+	Hosts     []string      `validate:"omitempty,min=2"`
+	IP        *string       `validate:"ip"`
+	Main      listener
+	Listeners []*listener `validate:"required"`
+	Even      uint        `validate:"even"`
+}
+
+func TestStruct(t *testing.T) {
+	Register("even", func(v reflect.Value, param string) error {
+		if v.Uint()%2 != 0 {
+			return errors.New("not even")
+		}
+		return nil
+	})
+
+	ip := "1.2.3.4"
+	s := server{
+		Name:      "abc",
+		Port:      80,
+		Timeout:   time.Second,
+		IP:        &ip,
+		Main:      listener{Network: "tcp", Addr: "127.0.0.1:80"},
+		Listeners: []*listener{{Network: "udp", Addr: ":53"}},
+	}
+	if err := Struct(&s); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	ip = "abc"
+	s = server{
+		Name:      "abcd",
+		Hosts:     []string{"a"},
+		IP:        &ip,
+		Main:      listener{Network: "http", Addr: "127.0.0.1"},
+		Listeners: []*listener{{Addr: ":0"}},
+		Even:      1,
+	}
+	err := Struct(s)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	expects := []string{
+		"Name:len", "Port:min", "Timeout:min", "Hosts:min", "IP:ip",
+		"Main.Network:oneof", "Main.Addr:hostport",
+		"Listeners[0].Network:required", "Listeners[0].Addr:hostport", "Even:even",
+	}
+	errs := err.(*errors2.Multi).Errors
+	results := make([]string, len(errs))
+	for i, e := range errs {
+		fe := e.(*FieldError)
+		results[i] = fe.Field + ":" + fe.Rule
+	}
+	if strings.Join(results, " ") != strings.Join(expects, " ") {
+		t.Errorf("expected '%v', but got '%v'", expects, results)
+	}
+
+	if e := errs[1].Error(); e != "field 'Port' violates 'min=1': the value must be at least 1" {
+		t.Error(e)
+	}
+}
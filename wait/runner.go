@@ -0,0 +1,125 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wait
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RunnerStats is the statistics of the runner.
+type RunnerStats struct {
+	// Runs is the number of the runs, and Skips is the number of the ticks
+	// skipped because the previous run has not finished.
+	Runs  uint64
+	Skips uint64
+
+	// LastStart, LastDuration and LastError are the start time,
+	// the duration and the returned error of the last run.
+	//
+	// If the function panics, LastError is the error converted from it.
+	LastStart    time.Time
+	LastDuration time.Duration
+	LastError    error
+}
+
+// Runner runs the function periodically, which runs it immediately,
+// then on each interval since the first run.
+//
+// If the previous run has not finished when ticking, the tick is skipped
+// instead of running again immediately after the previous run finishes,
+// so the runs are always aligned to the interval.
+type Runner struct {
+	interval time.Duration
+	fn       func(ctx context.Context) error
+
+	lock  sync.Mutex
+	stats RunnerStats
+}
+
+// NewRunner returns a new Runner to run fn on each interval,
+// which panics if interval is not positive.
+func NewRunner(interval time.Duration, fn func(ctx context.Context) error) *Runner {
+	if interval <= 0 {
+		panic("wait: the interval of the runner must be positive")
+	}
+	return &Runner{interval: interval, fn: fn}
+}
+
+// Stats returns the statistics of the runner.
+func (r *Runner) Stats() RunnerStats {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.stats
+}
+
+// Run runs the function periodically, which blocks until ctx is done.
+func (r *Runner) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	next := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		// Re-check ctx since select chooses the ready case randomly.
+		if ctx.Err() != nil {
+			return
+		}
+
+		start := time.Now()
+		err := r.call(ctx)
+		end := time.Now()
+
+		// Skip the missed ticks and align the next one to the interval.
+		var skips uint64
+		for next = next.Add(r.interval); !next.After(end); next = next.Add(r.interval) {
+			skips++
+		}
+
+		r.lock.Lock()
+		r.stats.Runs++
+		r.stats.Skips += skips
+		r.stats.LastStart = start
+		r.stats.LastDuration = end.Sub(start)
+		r.stats.LastError = err
+		r.lock.Unlock()
+
+		timer.Reset(next.Sub(end))
+	}
+}
+
+func (r *Runner) call(ctx context.Context) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("panic: %v", v)
+		}
+	}()
+	return r.fn(ctx)
+}
+
+// RunPeriodically is equal to NewRunner(interval, fn) and runs it
+// in a new goroutine until ctx is done, then returns the runner.
+func RunPeriodically(ctx context.Context, interval time.Duration, fn func(ctx context.Context) error) *Runner {
+	r := NewRunner(interval, fn)
+	go r.Run(ctx)
+	return r
+}
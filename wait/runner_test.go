@@ -0,0 +1,81 @@
+// Copyright 2019 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wait
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunner(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := make(chan time.Time, 10)
+	start := time.Now()
+	r := RunPeriodically(ctx, time.Millisecond*30, func(context.Context) error {
+		runs <- time.Now()
+		return errors.New("error")
+	})
+
+	// Run immediately.
+	if first := <-runs; first.Sub(start) > time.Millisecond*10 {
+		t.Errorf("expect to run immediately, but cost %s", first.Sub(start))
+	}
+
+	time.Sleep(time.Millisecond * 75)
+	cancel()
+	time.Sleep(time.Millisecond * 10)
+
+	stats := r.Stats()
+	if stats.Runs != 3 || stats.Skips != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats.LastError == nil || stats.LastError.Error() != "error" {
+		t.Errorf("unexpected error: %v", stats.LastError)
+	}
+}
+
+func TestRunnerSkip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var n int
+	starts := make(chan time.Time, 10)
+	r := NewRunner(time.Millisecond*20, func(context.Context) error {
+		starts <- time.Now()
+		if n++; n == 1 {
+			time.Sleep(time.Millisecond * 50) // Miss two ticks.
+		} else if n == 2 {
+			panic("test")
+		}
+		return nil
+	})
+	go r.Run(ctx)
+
+	first, second := <-starts, <-starts
+	if d := second.Sub(first); d < time.Millisecond*55 || d > time.Millisecond*75 {
+		t.Errorf("expect the next run to align to the interval, but cost %s", d)
+	}
+
+	time.Sleep(time.Millisecond * 5)
+	stats := r.Stats()
+	if stats.Runs != 2 || stats.Skips != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats.LastError == nil || stats.LastError.Error() != "panic: test" {
+		t.Errorf("unexpected error: %v", stats.LastError)
+	}
+}